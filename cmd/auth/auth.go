@@ -0,0 +1,333 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/chazzychouse/g2o/internal/config"
+	"github.com/chazzychouse/g2o/internal/glclient"
+	"github.com/chazzychouse/g2o/internal/keystore"
+	"github.com/chazzychouse/g2o/internal/render"
+	"github.com/chazzychouse/g2o/internal/styles"
+	"github.com/spf13/cobra"
+	"golang.org/x/oauth2"
+)
+
+var Command = &cobra.Command{
+	Use:   "auth",
+	Short: "Log in to GitLab and manage stored credentials",
+	Long: `Log in to GitLab and manage stored credentials.
+
+Credentials from "auth login" are kept per profile in an encrypted keystore
+under ~/.g2o. A token source set in the profile's config takes precedence
+over the keystore, and $GITLAB_TOKEN is used when neither is present.`,
+}
+
+var (
+	loginDevice    bool
+	loginWithToken bool
+	loginClientID  string
+	statusOutput   string
+)
+
+var loginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Store a personal access token or log in with the OAuth device flow",
+	Args:  cobra.NoArgs,
+	// Errors here are about credentials, not about how the command was used.
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return login(cmd)
+	},
+}
+
+var statusCmd = &cobra.Command{
+	Use:          "status",
+	Short:        "Show which account each profile is logged in as",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return status(cmd)
+	},
+}
+
+var logoutCmd = &cobra.Command{
+	Use:          "logout",
+	Short:        "Remove the stored credential of a profile",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return logout(cmd)
+	},
+}
+
+func init() {
+	loginCmd.Flags().BoolVar(&loginDevice, "device", false, "log in through the browser with the OAuth device flow")
+	loginCmd.Flags().BoolVar(&loginWithToken, "with-token", false, "read the token from stdin")
+	loginCmd.Flags().StringVar(&loginClientID, "client-id", "", "OAuth application ID (default: the profile's oauth_client_id or $G2O_OAUTH_CLIENT_ID)")
+	loginCmd.MarkFlagsMutuallyExclusive("device", "with-token")
+	statusCmd.Flags().StringVarP(&statusOutput, "output", "o", string(render.Plain),
+		"output format: plain, table, json, ndjson or csv")
+	Command.AddCommand(loginCmd, statusCmd, logoutCmd)
+}
+
+// profile returns the profile selected by --profile or the config default.
+func profile(cmd *cobra.Command) (config.Profile, error) {
+	cfg, err := config.Load(config.DefaultPath())
+	if err != nil {
+		return config.Profile{}, err
+	}
+	name, _ := cmd.Flags().GetString("profile")
+	return cfg.Resolve(name)
+}
+
+// client builds a GitLab client for p that is not tied to a local store.
+func client(p config.Profile, token string, opts ...glclient.Option) (glclient.GitLab, error) {
+	hc, err := glclient.NewHTTPClient(p.CAFile)
+	if err != nil {
+		return glclient.GitLab{}, err
+	}
+	opts = append(opts, glclient.WithHTTPClient(hc))
+	if p.BaseURL != "" {
+		opts = append(opts, glclient.WithBaseURL(p.BaseURL))
+	}
+	return glclient.NewGitlab(token, opts...)
+}
+
+func login(cmd *cobra.Command) error {
+	p, err := profile(cmd)
+	if err != nil {
+		return err
+	}
+
+	var cred keystore.Credential
+	var g glclient.GitLab
+	if loginDevice {
+		cred, g, err = deviceLogin(cmd.Context(), p)
+	} else {
+		cred, g, err = tokenLogin(p)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Only keep credentials that work.
+	user, err := g.Whoami()
+	if err != nil {
+		return err
+	}
+	cred.Username = user.Username
+	ks := keystore.New(config.Dir())
+	if err := ks.Put(p.Name, cred); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s %s %s\n",
+		styles.Success.Render("✓ logged in to "+p.Host()+" as @"+user.Username),
+		styles.Label.Render("(profile "+p.Name+")"),
+		styles.Label.Render("— stored in "+ks.Path()))
+	if src := p.TokenSourceName(); src != "" {
+		fmt.Println(styles.Error.Render("  note: the profile's config reads its token from " + src + ", which takes precedence"))
+	}
+	return nil
+}
+
+// tokenLogin asks for a personal access token, hiding it when typed at a
+// terminal.
+func tokenLogin(p config.Profile) (keystore.Credential, glclient.GitLab, error) {
+	var token string
+	if !loginWithToken && term.IsTerminal(os.Stdin.Fd()) {
+		base := p.BaseURL
+		if base == "" {
+			base = "https://gitlab.com"
+		}
+		fmt.Println(styles.Label.Render("Create a token with the api scope at " + base + "/-/user_settings/personal_access_tokens"))
+		fmt.Print(styles.Value.Render("Paste your token: "))
+		b, err := term.ReadPassword(os.Stdin.Fd())
+		fmt.Println()
+		if err != nil {
+			return keystore.Credential{}, glclient.GitLab{}, err
+		}
+		token = string(b)
+	} else {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return keystore.Credential{}, glclient.GitLab{}, fmt.Errorf("read token from stdin: %w", err)
+		}
+		token = line
+	}
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return keystore.Credential{}, glclient.GitLab{}, fmt.Errorf("no token given")
+	}
+
+	g, err := client(p, token)
+	if err != nil {
+		return keystore.Credential{}, glclient.GitLab{}, err
+	}
+	cred := keystore.Credential{Kind: keystore.KindPAT, Host: p.Host(), Token: token, CreatedAt: time.Now().UTC()}
+	// Project and group tokens cannot describe themselves; that is fine.
+	if pat, err := g.TokenInfo(); err == nil {
+		cred.Scopes = pat.Scopes
+		if pat.ExpiresAt != nil {
+			cred.Expiry = time.Time(*pat.ExpiresAt)
+		}
+	}
+	return cred, g, nil
+}
+
+// deviceLogin runs the OAuth device authorization flow, which needs an
+// OAuth application registered on the instance. Cancelling ctx stops
+// waiting for approval.
+func deviceLogin(ctx context.Context, p config.Profile) (keystore.Credential, glclient.GitLab, error) {
+	clientID := loginClientID
+	if clientID == "" {
+		clientID = p.OAuthClientID
+	}
+	if clientID == "" {
+		clientID = os.Getenv("G2O_OAUTH_CLIENT_ID")
+	}
+	if clientID == "" {
+		return keystore.Credential{}, glclient.GitLab{}, fmt.Errorf(
+			"the device flow needs an OAuth application: register a non-confidential application with the api scope on %s "+
+				"and pass its ID with --client-id or set oauth_client_id in the profile", p.Host())
+	}
+
+	hc, err := glclient.NewHTTPClient(p.CAFile)
+	if err != nil {
+		return keystore.Credential{}, glclient.GitLab{}, err
+	}
+	cfg := keystore.OAuthConfig(p.BaseURL, clientID, keystore.DefaultScopes)
+	tok, err := keystore.DeviceLogin(ctx, hc, cfg, func(da *oauth2.DeviceAuthResponse) {
+		fmt.Println(styles.Label.Render("First copy your one-time code: ") + styles.Title.Render(da.UserCode))
+		uri := da.VerificationURI
+		if da.VerificationURIComplete != "" {
+			uri = da.VerificationURIComplete
+		}
+		fmt.Println(styles.Label.Render("Then open ") + styles.Link.Render(uri) + styles.Label.Render(" and approve g2o."))
+		fmt.Println(styles.Label.Render("Waiting for authorization..."))
+	})
+	if err != nil {
+		return keystore.Credential{}, glclient.GitLab{}, fmt.Errorf("device login: %w", err)
+	}
+
+	g, err := client(p, "", glclient.WithTokenSource(oauth2.StaticTokenSource(tok)))
+	if err != nil {
+		return keystore.Credential{}, glclient.GitLab{}, err
+	}
+	return keystore.FromToken(p.Host(), clientID, cfg.Scopes, tok), g, nil
+}
+
+func status(cmd *cobra.Command) error {
+	format, err := render.ParseFormat(statusOutput)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(config.DefaultPath())
+	if err != nil {
+		return err
+	}
+	names := cfg.Names()
+	if name, _ := cmd.Flags().GetString("profile"); name != "" {
+		names = []string{name}
+	} else if os.Getenv("G2O_PROFILE") != "" {
+		names = []string{""}
+	}
+
+	ks := keystore.New(config.Dir())
+	var infos []render.AuthInfo
+	failed := 0
+	for _, name := range names {
+		p, err := cfg.Resolve(name)
+		if err != nil {
+			return err
+		}
+		info := authInfo(ks, p)
+		if info.Error != "" {
+			failed++
+		}
+		infos = append(infos, info)
+	}
+	if err := render.AuthStatus(os.Stdout, format, infos); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d profiles are not logged in", failed, len(infos))
+	}
+	return nil
+}
+
+// authInfo checks the credential of p against its instance.
+func authInfo(ks *keystore.Keystore, p config.Profile) render.AuthInfo {
+	info := render.AuthInfo{Profile: p.Name, Host: p.Host()}
+	hc, err := glclient.NewHTTPClient(p.CAFile)
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	auth, err := ks.Resolve(p, hc)
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	info.Source, info.Kind = auth.Source, keystore.KindPAT
+	if c := auth.Credential; c != nil {
+		info.Kind, info.Scopes, info.Expiry = c.Kind, c.Scopes, c.Expiry
+	}
+
+	var opts []glclient.Option
+	if auth.TokenSource != nil {
+		opts = append(opts, glclient.WithTokenSource(auth.TokenSource))
+	}
+	g, err := client(p, auth.Token, opts...)
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	user, err := g.Whoami()
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	info.Username = user.Username
+	return info
+}
+
+func logout(cmd *cobra.Command) error {
+	p, err := profile(cmd)
+	if err != nil {
+		return err
+	}
+	ks := keystore.New(config.Dir())
+	cred, err := ks.Get(p.Name)
+	if errors.Is(err, keystore.ErrNotFound) {
+		return fmt.Errorf("profile %s has no stored credential", p.Name)
+	}
+	if err != nil {
+		return err
+	}
+
+	if cred.Kind == keystore.KindOAuth {
+		if hc, err := glclient.NewHTTPClient(p.CAFile); err == nil {
+			ctx, cancel := context.WithTimeout(cmd.Context(), 10*time.Second)
+			defer cancel()
+			if err := keystore.Revoke(ctx, hc, p.BaseURL, cred); err != nil {
+				fmt.Fprintln(os.Stderr, styles.Error.Render("could not revoke the token on "+p.Host()+": "+err.Error()))
+			}
+		}
+	}
+	if err := ks.Delete(p.Name); err != nil {
+		return err
+	}
+	fmt.Println(styles.Success.Render("✓ logged out of " + p.Host() + " (profile " + p.Name + ")"))
+	if cred.Kind == keystore.KindPAT {
+		fmt.Println(styles.Label.Render("  the token itself is still valid; revoke it in GitLab if it is no longer needed"))
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package lab
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// addCommands registers the REPL command tree as cobra subcommands of parent,
+// so every REPL command can also run non-interactively with a proper exit
+// code. Both paths share the same handlers via dispatch.
+func addCommands(parent *cobra.Command, tree []*replCmd) {
+	var add func(parent *cobra.Command, nodes []*replCmd, path []string)
+	add = func(parent *cobra.Command, nodes []*replCmd, path []string) {
+		for _, c := range nodes {
+			if c.REPLOnly {
+				continue
+			}
+			tokens := append(slices.Clone(path), c.Name)
+			cc := &cobra.Command{
+				Use:          c.usage(),
+				Short:        c.Desc,
+				SilenceUsage: true,
+			}
+
+			if c.Flags != nil {
+				// Cobra parses the flags into the variables Run reads, so the
+				// handler is called directly rather than through dispatch.
+				c.Flags(cc.Flags())
+				cc.Args = cobra.ExactArgs(c.argCount())
+				if c.Rest != "" {
+					cc.Args = cobra.MinimumNArgs(c.argCount())
+				}
+				cc.RunE = func(cmd *cobra.Command, args []string) error {
+					return c.Run(cmd.Context(), args)
+				}
+				parent.AddCommand(cc)
+				continue
+			}
+
+			if c.Arg != "" {
+				// Cobra cannot match literal tokens that follow a positional
+				// value ("group 42 issues"), so the remaining arguments are
+				// handed to dispatch, which walks the tree itself.
+				cc.Args = cobra.MinimumNArgs(c.argCount())
+				cc.RunE = func(cmd *cobra.Command, args []string) error {
+					return dispatch(cmd.Context(), tree, append(slices.Clone(tokens), args...))
+				}
+				if len(c.Sub) > 0 {
+					cc.Long = c.Desc + "\n\nCommands:\n" + subTreeHelp(c.Sub, strings.Join(tokens, " ")+" "+c.Arg+" ")
+				}
+				parent.AddCommand(cc)
+				continue
+			}
+
+			if c.Run != nil {
+				cc.Args = cobra.NoArgs
+				if c.Rest != "" {
+					cc.Args = cobra.ArbitraryArgs
+				}
+				cc.RunE = func(cmd *cobra.Command, args []string) error {
+					return dispatch(cmd.Context(), tree, append(slices.Clone(tokens), args...))
+				}
+			}
+			add(cc, c.Sub, tokens)
+			parent.AddCommand(cc)
+		}
+	}
+	add(parent, tree, nil)
+}
+
+// subTreeHelp renders the runnable commands below a positional node as plain
+// text for cobra's help output.
+func subTreeHelp(cmds []*replCmd, prefix string) string {
+	var b strings.Builder
+	for _, c := range cmds {
+		label := prefix + c.usage()
+		if c.Run != nil {
+			fmt.Fprintf(&b, "  %-24s  %s\n", label, c.Desc)
+		}
+		if len(c.Sub) > 0 {
+			b.WriteString(subTreeHelp(c.Sub, label+" "))
+		}
+	}
+	return b.String()
+}
@@ -1,31 +1,61 @@
 package lab
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
 	prompt "github.com/c-bata/go-prompt"
 	"github.com/chazzychouse/g2o/internal/styles"
+	"github.com/spf13/pflag"
 )
 
 // replCmd is a node in the command tree. Each node matches a literal token
-// (Name) or, when Arg is set, captures the next token as a positional argument.
+// (Name) and, when Arg is set, captures the following tokens as positional
+// arguments, one per placeholder in Arg.
 type replCmd struct {
-	Name string              // literal token to match (e.g. "group")
-	Desc string              // shown in help and completion
-	Arg  string              // if non-empty, next token is captured (e.g. "<gid>")
-	Run  func(args []string) error // executor; args contains captured positional values
-	Sub  []*replCmd          // subcommands
+	Name string                                         // literal token to match (e.g. "group")
+	Desc string                                         // shown in help and completion
+	Arg  string                                         // if non-empty, one token is captured per placeholder (e.g. "<group>")
+	Run  func(ctx context.Context, args []string) error // executor; args contains captured positional values
+	Sub  []*replCmd                                     // subcommands
+	Rest string                                         // if non-empty, all remaining tokens are captured (e.g. "[filter...]")
+
+	// Flags, if set, defines the node's flags on fs, binding them to
+	// variables that Run reads. It is called afresh for every invocation so
+	// values do not leak from one REPL command into the next.
+	Flags func(fs *pflag.FlagSet)
+
+	REPLOnly bool // not exposed as a cobra subcommand (e.g. "exit")
+}
+
+// usage returns the node's name followed by its argument placeholders.
+func (c *replCmd) usage() string {
+	parts := []string{c.Name}
+	for _, p := range []string{c.Arg, c.Rest} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	if c.Flags != nil {
+		parts = append(parts, "[flags]")
+	}
+	return strings.Join(parts, " ")
+}
+
+// argCount returns the number of positional tokens the node captures.
+func (c *replCmd) argCount() int {
+	return len(strings.Fields(c.Arg))
 }
 
 // dispatch walks the command tree and invokes the deepest matching Run.
 // Positional args captured via Arg fields are collected in order.
-func dispatch(cmds []*replCmd, tokens []string) error {
+func dispatch(ctx context.Context, cmds []*replCmd, tokens []string) error {
 	var args []string
 	nodes := cmds
 
 	var matched *replCmd
-	i := 0
+	i, start, prior := 0, 0, 0
 	for i < len(tokens) {
 		tok := tokens[i]
 		found := false
@@ -33,8 +63,9 @@ func dispatch(cmds []*replCmd, tokens []string) error {
 			if c.Name == tok {
 				matched = c
 				i++
-				// If this node expects a positional arg, consume the next token.
-				if c.Arg != "" && i < len(tokens) {
+				start, prior = i, len(args)
+				// If this node expects positional args, consume the next tokens.
+				for n := c.argCount(); n > 0 && i < len(tokens); n-- {
 					args = append(args, tokens[i])
 					i++
 				}
@@ -51,12 +82,76 @@ func dispatch(cmds []*replCmd, tokens []string) error {
 	if matched == nil || matched.Run == nil {
 		return fmt.Errorf("unknown command: %q", strings.Join(tokens, " "))
 	}
-	return matched.Run(args)
+	if matched.Flags != nil {
+		// Flags may appear anywhere after the command name, so the
+		// positional values are taken from what the flag parser leaves.
+		fs := pflag.NewFlagSet(matched.Name, pflag.ContinueOnError)
+		matched.Flags(fs)
+		if err := fs.Parse(tokens[start:]); err != nil {
+			return err
+		}
+		args = append(args[:prior], fs.Args()...)
+	} else {
+		args = append(args, tokens[i:]...)
+	}
+	// Handlers index their positional args directly, so a node is only run
+	// with exactly as many as it captures, or more when it takes the rest.
+	if n := prior + matched.argCount(); len(args) < n || (matched.Rest == "" && len(args) > n) {
+		return fmt.Errorf("usage: %s", strings.Join(append(tokens[:start-1:start-1], matched.usage()), " "))
+	}
+	return matched.Run(ctx, args)
+}
+
+// tokenize splits a REPL input line into tokens like a shell would: single
+// and double quotes group words, and a backslash escapes the next character.
+func tokenize(in string) ([]string, error) {
+	var tokens []string
+	var cur strings.Builder
+	inToken := false
+	var quote rune
+	escaped := false
+	for _, r := range in {
+		switch {
+		case escaped:
+			cur.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inToken = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inToken = true
+		case r == ' ' || r == '\t':
+			if inToken {
+				tokens = append(tokens, cur.String())
+				cur.Reset()
+				inToken = false
+			}
+		default:
+			cur.WriteRune(r)
+			inToken = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inToken {
+		tokens = append(tokens, cur.String())
+	}
+	return tokens, nil
 }
 
 // complete walks the command tree following already-typed tokens and returns
-// suggestions for the next position.
-func complete(cmds []*replCmd, tokens []string) []prompt.Suggest {
+// suggestions for the next position. When that is a positional argument the
+// suggestions come from args, given the argument's placeholder, and isArg
+// is true.
+func complete(cmds []*replCmd, tokens []string, args func(placeholder string) []prompt.Suggest) (suggestions []prompt.Suggest, isArg bool) {
 	nodes := cmds
 	i := 0
 	for i < len(tokens) {
@@ -65,13 +160,13 @@ func complete(cmds []*replCmd, tokens []string) []prompt.Suggest {
 		for _, c := range nodes {
 			if c.Name == tok {
 				i++
-				// Skip over a positional arg value if present.
-				if c.Arg != "" {
-					if i < len(tokens) {
-						i++
+				// Skip over positional arg values if present.
+				if n := c.argCount(); n > 0 {
+					if i+n <= len(tokens) {
+						i += n
 					} else {
-						// User hasn't typed the arg yet — no suggestions.
-						return nil
+						// The user is typing one of the args.
+						return args(strings.Fields(c.Arg)[len(tokens)-i]), true
 					}
 				}
 				nodes = c.Sub
@@ -88,7 +183,7 @@ func complete(cmds []*replCmd, tokens []string) []prompt.Suggest {
 	for _, c := range nodes {
 		out = append(out, prompt.Suggest{Text: c.Name, Description: c.Desc})
 	}
-	return out
+	return out, false
 }
 
 // buildHelp prints the command tree as a flat help listing.
@@ -98,10 +193,7 @@ func buildHelp(cmds []*replCmd) {
 
 func printTree(cmds []*replCmd, prefix string) {
 	for _, c := range cmds {
-		label := prefix + c.Name
-		if c.Arg != "" {
-			label += " " + c.Arg
-		}
+		label := prefix + c.usage()
 		if c.Run != nil {
 			// Pad to a fixed width for alignment.
 			padded := label
@@ -0,0 +1,80 @@
+package lab
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDispatchMissingArgs(t *testing.T) {
+	// Every input stops at the usage check, before a handler could index
+	// an argument that is not there.
+	cmds := (&session{}).commands()
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"project", "usage: project <project>"},
+		{"mr foo", "usage: mr <project> <iid>"},
+		{"pipelines", "usage: pipelines <project>"},
+		{"milestone", "usage: milestone <id|title>"},
+		{"issue close x", "usage: issue close <project> <iid>"},
+		{"issue reopen", "usage: issue reopen <project> <iid>"},
+		{"issue comment x", "usage: issue comment <project> <iid> [text...]"},
+		{"issue assign x", "usage: issue assign <project> <iid> <user...>"},
+		{"issue label x", "usage: issue label <project> <iid> <+label|-label...>"},
+		{"issue new", "usage: issue new <project> [flags]"},
+		{"issue edit x --title t", "usage: issue edit <project> <iid> [flags]"},
+		{"pipeline retry p", "usage: pipeline retry <project> <id>"},
+		{"pipeline cancel", "usage: pipeline cancel <project> <id>"},
+		{"queue retry", "usage: queue retry <id>"},
+		{"queue drop", "usage: queue drop <id>"},
+		{"sync project", "usage: sync project <project>"},
+		{"set workers", "usage: set workers <n>"},
+		{"profile use", "usage: profile use <name>"},
+		// Extra tokens are refused too, rather than dropped.
+		{"queue drop 1 2", "usage: queue drop <id>"},
+		{"sync bogus", "usage: sync"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			err := dispatch(context.Background(), cmds, strings.Fields(tt.line))
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("got error %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDispatchArgs(t *testing.T) {
+	var got []string
+	record := func(ctx context.Context, args []string) error {
+		got = args
+		return nil
+	}
+	cmds := []*replCmd{
+		{Name: "group", Arg: "<group>", Sub: []*replCmd{
+			{Name: "issues", Rest: "[filter...]", Run: record},
+		}},
+		{Name: "mr", Arg: "<project> <iid>", Run: record},
+	}
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"mr acme/api 4", []string{"acme/api", "4"}},
+		{"group acme issues", []string{"acme"}},
+		{"group acme issues label:bug state:opened", []string{"acme", "label:bug", "state:opened"}},
+	}
+	for _, tt := range tests {
+		got = nil
+		if err := dispatch(context.Background(), cmds, strings.Fields(tt.line)); err != nil {
+			t.Errorf("dispatch(%q): %v", tt.line, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("dispatch(%q) ran with %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package lab
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Help lines appended to the text handed to the editor and removed again
+// afterwards. They are HTML comments so they cannot be mistaken for Markdown.
+const (
+	issueEditorHelp   = "<!-- The first line is the title and the rest the description. An empty title aborts. -->"
+	commentEditorHelp = "<!-- Write the comment in Markdown. An empty comment aborts. -->"
+)
+
+// editIssueText lets the user edit a title and description in their editor.
+func editIssueText(title, description string) (string, string, error) {
+	text, err := editText(title+"\n\n"+description, issueEditorHelp)
+	if err != nil {
+		return "", "", err
+	}
+	title, description, _ = strings.Cut(text, "\n")
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return "", "", fmt.Errorf("empty title, aborting")
+	}
+	return title, strings.TrimSpace(description), nil
+}
+
+// editComment lets the user write a comment in their editor.
+func editComment() (string, error) {
+	text, err := editText("", commentEditorHelp)
+	if err != nil {
+		return "", err
+	}
+	if text == "" {
+		return "", fmt.Errorf("empty comment, aborting")
+	}
+	return text, nil
+}
+
+// editText opens $VISUAL or $EDITOR (vi if neither is set) on initial
+// followed by help, and returns the trimmed result with help removed.
+func editText(initial, help string) (string, error) {
+	f, err := os.CreateTemp("", "g2o-*.md")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+
+	if !strings.HasSuffix(initial, "\n") {
+		initial += "\n"
+	}
+	if _, err := f.WriteString(initial + "\n" + help + "\n"); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	// The editor setting may carry arguments ("code --wait"), so let the
+	// shell split it.
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "sh", f.Name())
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor: %w", err)
+	}
+
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(strings.ReplaceAll(string(data), help, "")), nil
+}
+
+// interactive reports whether stdin is a terminal, i.e. whether an editor
+// can be opened.
+func interactive() bool {
+	fi, err := os.Stdin.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
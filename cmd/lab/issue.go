@@ -0,0 +1,141 @@
+package lab
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/chazzychouse/g2o/internal/glclient"
+	"github.com/spf13/pflag"
+)
+
+// issueFlags binds the flags of "issue new" and "issue edit".
+type issueFlags struct {
+	fs          *pflag.FlagSet
+	title       string
+	description string
+	milestone   string
+	due         string
+	labels      []string
+	assignees   []string
+}
+
+func (f *issueFlags) define(fs *pflag.FlagSet, create bool) {
+	f.fs = fs
+	fs.StringVarP(&f.title, "title", "t", "", "issue title")
+	fs.StringVarP(&f.description, "description", "d", "", "description in Markdown (opens $EDITOR when omitted)")
+	fs.StringVarP(&f.milestone, "milestone", "m", "", `milestone title ("" clears it)`)
+	fs.StringVar(&f.due, "due", "", `due date as YYYY-MM-DD ("" clears it)`)
+	if create {
+		fs.StringSliceVarP(&f.labels, "label", "l", nil, "labels, comma-separated or repeated")
+	}
+	fs.StringSliceVarP(&f.assignees, "assignee", "a", nil, "assignee usernames (@me for yourself)")
+}
+
+// fields returns the flags that were given on the command line.
+func (f *issueFlags) fields() glclient.IssueFields {
+	var out glclient.IssueFields
+	str := func(name string, v string) *string {
+		if f.fs.Changed(name) {
+			return &v
+		}
+		return nil
+	}
+	out.Title = str("title", f.title)
+	out.Description = str("description", f.description)
+	out.Milestone = str("milestone", f.milestone)
+	out.DueDate = str("due", f.due)
+	out.Labels = f.labels
+	if f.fs.Changed("assignee") {
+		out.Assignees = f.assignees
+	}
+	return out
+}
+
+// issueCommand builds the "issue" command: "issue <project> <iid>" shows an
+// issue and the subcommands change one.
+func (s *session) issueCommand() *replCmd {
+	var newFlags, editFlags issueFlags
+	return &replCmd{
+		Name: "issue", Desc: "Show an issue with its notes and linked issues", Rest: "<project> <iid>",
+		Run: func(ctx context.Context, args []string) error {
+			if len(args) != 2 {
+				return fmt.Errorf("usage: issue <project> <iid>")
+			}
+			project, err := s.resolve("project", args[0])
+			if err != nil {
+				return err
+			}
+			return s.g.RunIssue(ctx, project, args[1])
+		},
+		Sub: []*replCmd{
+			{
+				Name: "new", Desc: "Create an issue", Arg: "<project>",
+				Flags: func(fs *pflag.FlagSet) { newFlags.define(fs, true) },
+				Run: func(ctx context.Context, args []string) error {
+					f := newFlags.fields()
+					if f.Description == nil && interactive() {
+						var title, description string
+						if f.Title != nil {
+							title = *f.Title
+						}
+						title, description, err := editIssueText(title, description)
+						if err != nil {
+							return err
+						}
+						f.Title, f.Description = &title, &description
+					}
+					return s.g.RunCreateIssue(args[0], f)
+				},
+			},
+			{
+				Name: "edit", Desc: "Edit an issue (opens $EDITOR without flags)", Arg: "<project> <iid>",
+				Flags: func(fs *pflag.FlagSet) { editFlags.define(fs, false) },
+				Run: func(ctx context.Context, args []string) error {
+					f := editFlags.fields()
+					if editFlags.fs.NFlag() == 0 {
+						if !interactive() {
+							return fmt.Errorf("nothing to change; pass flags or run in a terminal to use $EDITOR")
+						}
+						n, err := strconv.ParseInt(args[1], 10, 64)
+						if err != nil {
+							return fmt.Errorf("invalid issue IID %q", args[1])
+						}
+						issue, err := s.g.GetIssue(args[0], n)
+						if err != nil {
+							return err
+						}
+						title, description, err := editIssueText(issue.Title, issue.Description)
+						if err != nil {
+							return err
+						}
+						f.Title, f.Description = &title, &description
+					}
+					return s.g.RunEditIssue(args[0], args[1], f)
+				},
+			},
+			{Name: "close", Desc: "Close an issue", Arg: "<project> <iid>", Run: func(ctx context.Context, args []string) error { return s.g.RunCloseIssue(args[0], args[1]) }},
+			{Name: "reopen", Desc: "Reopen an issue", Arg: "<project> <iid>", Run: func(ctx context.Context, args []string) error { return s.g.RunReopenIssue(args[0], args[1]) }},
+			{Name: "comment", Desc: "Comment on an issue (opens $EDITOR without text)", Arg: "<project> <iid>", Rest: "[text...]", Run: func(ctx context.Context, args []string) error {
+				body := strings.Join(args[2:], " ")
+				if body == "" && interactive() {
+					var err error
+					if body, err = editComment(); err != nil {
+						return err
+					}
+				}
+				return s.g.RunCommentIssue(args[0], args[1], body)
+			}},
+			{Name: "assign", Desc: "Set assignees (@me, usernames or none)", Arg: "<project> <iid>", Rest: "<user...>", Run: func(ctx context.Context, args []string) error {
+				if len(args) < 3 {
+					return fmt.Errorf("usage: issue assign <project> <iid> <user...>")
+				}
+				return s.g.RunAssignIssue(args[0], args[1], args[2:])
+			}},
+			{Name: "label", Desc: "Add or remove labels (+bug -triage)", Arg: "<project> <iid>", Rest: "<+label|-label...>", Run: func(ctx context.Context, args []string) error {
+				return s.g.RunLabelIssue(args[0], args[1], args[2:])
+			}},
+		},
+	}
+}
@@ -3,121 +3,365 @@ package lab
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"os/signal"
+	"strconv"
 	"strings"
 
 	prompt "github.com/c-bata/go-prompt"
+	"github.com/charmbracelet/x/term"
+	"github.com/chazzychouse/g2o/internal/config"
 	"github.com/chazzychouse/g2o/internal/glclient"
+	"github.com/chazzychouse/g2o/internal/keystore"
+	"github.com/chazzychouse/g2o/internal/render"
 	"github.com/chazzychouse/g2o/internal/store"
 	"github.com/chazzychouse/g2o/internal/styles"
 	gosync "github.com/chazzychouse/g2o/internal/sync"
 	"github.com/spf13/cobra"
 )
 
+// session holds the state shared by the REPL and the non-interactive
+// subcommands. It is populated by open before any handler runs.
+type session struct {
+	db      *store.Store
+	g       glclient.GitLab
+	syncer  *gosync.Syncer
+	closers []io.Closer
+	profile config.Profile
+
+	output      string // --output flag value
+	progress    string // --progress flag value
+	profileName string // --profile flag value, or set by "profile use"
+}
+
+var sess = &session{}
+
 var Command = &cobra.Command{
 	Use:   "lab",
 	Short: "Interact with GitLab",
+	Long: `Interact with GitLab.
+
+Without arguments an interactive REPL is started. Every REPL command is also
+available as a subcommand, e.g. "g2o lab issues" or "g2o lab sync full".`,
+	Args: cobra.NoArgs,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		sess.profileName, _ = cmd.Flags().GetString("profile")
+		return sess.open()
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		token, ok := os.LookupEnv("GITLAB_TOKEN")
-		if !ok {
-			return fmt.Errorf("GITLAB_TOKEN is not set")
+		// Auto-sync on first run if the database is empty.
+		if sess.syncer.NeedsFullSync() {
+			fmt.Println(styles.Title.Render("First run detected — syncing data from GitLab..."))
+			if err := sess.syncer.SyncAll(cmd.Context()); err != nil {
+				fmt.Fprintln(os.Stderr, styles.Error.Render("sync failed: "+err.Error()))
+				fmt.Println("Continuing with API fallback...")
+			}
 		}
 
-		// Open local store.
-		db, err := store.Open(store.DefaultPath())
-		if err != nil {
-			return fmt.Errorf("open store: %w", err)
-		}
-		defer db.Close()
+		return runREPL(sess)
+	},
+}
 
-		var opts []glclient.Option
-		opts = append(opts, glclient.WithStore(db))
+func init() {
+	Command.PersistentFlags().StringVarP(&sess.output, "output", "o", string(render.Plain),
+		"output format: plain, table, json, ndjson or csv")
+	Command.PersistentFlags().StringVar(&sess.progress, "progress", progressAuto,
+		"sync progress: auto, live, plain, json or none (auto is live on a terminal and json with JSON output)")
+	addCommands(Command, sess.commands())
+}
 
-		if _, ok := os.LookupEnv("G2O_DEBUG"); ok {
-			logFile, err := os.Create("g2o.debug.log")
-			if err != nil {
-				return fmt.Errorf("failed to create debug log: %w", err)
-			}
-			defer logFile.Close()
-			logger := slog.New(slog.NewJSONHandler(logFile, &slog.HandlerOptions{Level: slog.LevelDebug}))
-			opts = append(opts, glclient.WithLogger(logger))
+// open resolves the profile, reads its credentials, opens its local store
+// and builds the GitLab client and syncer.
+func (s *session) open() error {
+	format, err := render.ParseFormat(s.output)
+	if err != nil {
+		return err
+	}
+	s.output = string(format)
 
-			dumpFile, err := os.Create("g2o.issues.jsonl")
-			if err != nil {
-				return fmt.Errorf("failed to create dump file: %w", err)
-			}
-			defer dumpFile.Close()
-			opts = append(opts, glclient.WithDump(dumpFile))
+	cfg, err := config.Load(config.DefaultPath())
+	if err != nil {
+		return err
+	}
+	profile, err := cfg.Resolve(s.profileName)
+	if err != nil {
+		return err
+	}
+	// Each profile has its own store so instances never mix.
+	db, err := store.Open(profile.DBPath())
+	if err != nil {
+		return fmt.Errorf("open store: %w", err)
+	}
+	s.db = db
+	s.closers = append(s.closers, db)
+	s.profile = profile
+
+	var opts []glclient.Option
+	opts = append(opts, glclient.WithStore(db), glclient.WithOutput(os.Stdout, format))
+	if profile.BaseURL != "" {
+		opts = append(opts, glclient.WithBaseURL(profile.BaseURL))
+	}
+	hc, err := glclient.NewHTTPClient(profile.CAFile)
+	if err != nil {
+		return err
+	}
+	if profile.CAFile != "" {
+		opts = append(opts, glclient.WithHTTPClient(hc))
+	}
+	if profile.SyncWorkers > 0 {
+		opts = append(opts, glclient.WithConcurrency(profile.SyncWorkers))
+	}
+	if profile.RateLimit > 0 {
+		opts = append(opts, glclient.WithRateLimit(float64(profile.RateLimit)))
+	}
+
+	auth, err := keystore.New(config.Dir()).Resolve(profile, hc)
+	if err != nil {
+		return fmt.Errorf("profile %s: %w", profile.Name, err)
+	}
+	if auth.TokenSource != nil {
+		opts = append(opts, glclient.WithTokenSource(auth.TokenSource))
+	}
+
+	if _, ok := os.LookupEnv("G2O_DEBUG"); ok {
+		logFile, err := os.Create("g2o.debug.log")
+		if err != nil {
+			return fmt.Errorf("failed to create debug log: %w", err)
 		}
+		s.closers = append(s.closers, logFile)
+		logger := slog.New(slog.NewJSONHandler(logFile, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		opts = append(opts, glclient.WithLogger(logger))
 
-		g, err := glclient.NewGitlab(token, opts...)
+		dumpFile, err := os.Create("g2o.issues.jsonl")
 		if err != nil {
-			return err
+			return fmt.Errorf("failed to create dump file: %w", err)
 		}
+		s.closers = append(s.closers, dumpFile)
+		opts = append(opts, glclient.WithDump(dumpFile))
+	}
 
-		syncer := gosync.NewSyncer(&g, db)
+	g, err := glclient.NewGitlab(auth.Token, opts...)
+	if err != nil {
+		return err
+	}
+	s.g = g
+	return s.newSyncer()
+}
 
-		// Auto-sync on first run if the database is empty.
-		if syncer.NeedsFullSync() {
-			fmt.Println(styles.Title.Render("First run detected — syncing data from GitLab..."))
-			if err := syncer.SyncAll(context.Background()); err != nil {
-				fmt.Fprintln(os.Stderr, styles.Error.Render("sync failed: "+err.Error()))
-				fmt.Println("Continuing with API fallback...")
-			}
+// newSyncer builds the syncer for the session's client, store and profile.
+func (s *session) newSyncer() error {
+	scopes, err := glclient.ParseIssueScopes(s.profile.IssueScopes)
+	if err != nil {
+		return fmt.Errorf("profile %s: %w", s.profile.Name, err)
+	}
+	s.syncer = gosync.NewSyncer(&s.g, s.db)
+	s.syncer.SetOverlap(s.profile.SyncOverlap)
+	s.syncer.SetIssueScopes(scopes)
+	return s.applyProgress()
+}
+
+// progressAuto picks the sync progress reporter from where output goes.
+const progressAuto = "auto"
+
+// applyProgress gives the syncer the reporter for the progress mode. In
+// auto mode a terminal gets the live view, JSON output gets JSON events and
+// anything else plain lines.
+func (s *session) applyProgress() error {
+	mode := s.progress
+	if mode == "" || mode == progressAuto {
+		switch {
+		case s.output == string(render.JSON) || s.output == string(render.NDJSON):
+			mode = gosync.ProgressJSON
+		case term.IsTerminal(os.Stdout.Fd()):
+			mode = gosync.ProgressLive
+		default:
+			mode = gosync.ProgressPlain
 		}
+	}
+	r, err := gosync.NewReporter(mode, os.Stdout)
+	if err != nil {
+		return err
+	}
+	s.syncer.SetReporter(r)
+	return nil
+}
 
-		return runREPL(g, syncer)
-	},
+// setProgress switches how subsequent syncs report their progress.
+func (s *session) setProgress(mode string) error {
+	prev := s.progress
+	s.progress = mode
+	if err := s.applyProgress(); err != nil {
+		s.progress = prev
+		return err
+	}
+	fmt.Println(styles.Success.Render("sync progress: " + mode))
+	return nil
+}
+
+// setOutput switches the output format of subsequent commands.
+func (s *session) setOutput(name string) error {
+	format, err := render.ParseFormat(name)
+	if err != nil {
+		return err
+	}
+	s.output = string(format)
+	s.g.Apply(glclient.WithOutput(os.Stdout, format))
+	if err := s.applyProgress(); err != nil {
+		return err
+	}
+	fmt.Println(styles.Success.Render("output format: " + s.output))
+	return nil
+}
+
+// setWorkers changes how many requests subsequent syncs run at once.
+func (s *session) setWorkers(arg string) error {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 {
+		return fmt.Errorf("invalid worker count %q", arg)
+	}
+	s.g.Apply(glclient.WithConcurrency(n))
+	fmt.Println(styles.Success.Render("sync workers: " + arg))
+	return nil
+}
+
+// Close releases what the lab commands opened. It is called once the
+// command line has run, rather than from a post-run hook, which cobra skips
+// when a command fails.
+func Close() error {
+	return sess.close()
+}
+
+// close releases everything opened by open, most recent first.
+func (s *session) close() error {
+	var first error
+	for i := len(s.closers) - 1; i >= 0; i-- {
+		if err := s.closers[i].Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	s.closers = nil
+	return first
 }
 
-func runREPL(g glclient.GitLab, syncer *gosync.Syncer) error {
+// commands builds the command tree shared by the REPL and the cobra
+// subcommands. Handlers read the session at call time, so the tree can be
+// built before open has run.
+func (s *session) commands() []*replCmd {
 	var cmds []*replCmd
 	cmds = []*replCmd{
-		{Name: "groups", Desc: "List your groups", Run: func(args []string) error { return g.RunGroups() }},
+		{Name: "groups", Desc: "List your groups", Run: func(ctx context.Context, args []string) error { return s.g.RunGroups() }},
 		{
-			Name: "group", Desc: "Group commands", Arg: "<gid>",
+			Name: "group", Desc: "Group commands (by ID, path or name)", Arg: "<group>",
 			Sub: []*replCmd{
-				{Name: "issues", Desc: "List issues for group", Run: func(args []string) error { return g.RunGroupsIssues(context.Background(), args[0]) }},
+				{Name: "issues", Desc: "List issues for group", Run: func(ctx context.Context, args []string) error { return s.g.RunGroupsIssues(ctx, args[0]) }},
+			},
+		},
+		{
+			Name: "project", Desc: "Show a project (by ID, path or name)", Arg: "<project>",
+			Run: func(ctx context.Context, args []string) error { return s.g.RunProject(ctx, args[0]) },
+			Sub: []*replCmd{
+				{Name: "issues", Desc: "List issues for project", Rest: "[filter...]", Run: func(ctx context.Context, args []string) error { return s.g.RunProjectIssues(ctx, args[0], args[1:]) }},
+				{Name: "mrs", Desc: "List open merge requests for project", Run: func(ctx context.Context, args []string) error { return s.g.RunProjectMergeRequests(ctx, args[0]) }},
+				{Name: "milestones", Desc: "List milestones for project", Run: func(ctx context.Context, args []string) error { return s.g.RunProjectMilestones(ctx, args[0]) }},
+				{Name: "labels", Desc: "List labels for project with how many of its issues carry them", Run: func(ctx context.Context, args []string) error { return s.g.RunProjectLabels(ctx, args[0]) }},
+				{Name: "members", Desc: "List members of project", Run: func(ctx context.Context, args []string) error { return s.g.RunProjectMembers(ctx, args[0]) }},
+				{Name: "pipelines", Desc: "List recent pipelines for project", Run: func(ctx context.Context, args []string) error { return s.g.RunPipelines(ctx, args[0]) }},
+			},
+		},
+		{Name: "projects", Desc: "List your projects", Run: func(ctx context.Context, args []string) error { return s.g.RunProjects() }},
+		{Name: "me", Desc: "Show current user", Run: func(ctx context.Context, args []string) error { return s.g.RunCurrentUser() }},
+		{Name: "todos", Desc: "List your pending to-do items", Run: func(ctx context.Context, args []string) error { return s.g.RunTodos(ctx) }},
+		s.todoCommand(),
+		{Name: "dashboard", Desc: "Summarise your issues, merge requests and what changed since your last look", Run: func(ctx context.Context, args []string) error { return s.g.RunDashboard() }},
+		{Name: "issues", Desc: "List your issues (e.g. issues state:opened label:bug sort:-weight)", Rest: "[filter...]", Run: func(ctx context.Context, args []string) error { return s.g.RunIssues(args) }},
+		s.issueCommand(),
+		{Name: "mrs", Desc: "List your open merge requests", Run: func(ctx context.Context, args []string) error { return s.g.RunMergeRequests() }},
+		{Name: "mr", Desc: "Show a merge request", Arg: "<project> <iid>", Run: func(ctx context.Context, args []string) error { return s.g.RunMergeRequest(args[0], args[1]) }},
+		{Name: "pipelines", Desc: "List recent pipelines of a project", Arg: "<project>", Run: func(ctx context.Context, args []string) error { return s.g.RunPipelines(ctx, args[0]) }},
+		s.pipelineCommand(),
+		s.jobCommand(),
+		{Name: "milestone", Desc: "Show milestone or iteration progress with a burndown", Arg: "<id|title>", Run: func(ctx context.Context, args []string) error { return s.g.RunMilestone(args[0]) }},
+		{Name: "labels", Desc: "List labels with how many issues carry them", Run: func(ctx context.Context, args []string) error { return s.g.RunLabels() }},
+		{Name: "search", Desc: "Full-text search issues (phrases in quotes, prefix*)", Rest: "<terms...>", Run: func(ctx context.Context, args []string) error { return s.g.RunSearch(args) }},
+		{
+			Name: "queue", Desc: "List writes queued while offline",
+			Run: func(ctx context.Context, args []string) error { return s.g.RunQueue() },
+			Sub: []*replCmd{
+				{Name: "retry", Desc: "Replay a conflicted or failed write, overriding server changes", Arg: "<id>", Run: func(ctx context.Context, args []string) error { return s.g.RunRetryOp(ctx, args[0]) }},
+				{Name: "drop", Desc: "Discard a queued write", Arg: "<id>", Run: func(ctx context.Context, args []string) error { return s.g.RunDropOp(args[0]) }},
 			},
 		},
-		{Name: "projects", Desc: "List your projects", Run: func(args []string) error { return g.RunProjects() }},
-		{Name: "me", Desc: "Show current user", Run: func(args []string) error { return g.RunCurrentUser() }},
-		{Name: "issues", Desc: "List your issues", Run: func(args []string) error { return g.RunIssues() }},
 		{
 			Name: "sync", Desc: "Sync data from GitLab",
-			Run: func(args []string) error { return syncer.SyncIncremental(context.Background()) },
+			Run: func(ctx context.Context, args []string) error { return s.syncer.SyncIncremental(ctx) },
 			Sub: []*replCmd{
-				{Name: "full", Desc: "Full re-download of all data", Run: func(args []string) error { return syncer.SyncAll(context.Background()) }},
+				{Name: "full", Desc: "Full re-download of all data", Run: func(ctx context.Context, args []string) error { return s.syncer.SyncAll(ctx) }},
 				{
 					Name: "groups", Desc: "Sync groups only",
-					Run: func(args []string) error { return syncer.SyncGroups(context.Background()) },
+					Run: func(ctx context.Context, args []string) error { return s.syncer.SyncGroups(ctx) },
 					Sub: []*replCmd{
-						{Name: "issues", Desc: "Sync issues for all groups", Run: func(args []string) error { return syncer.SyncGroupIssues(context.Background()) }},
+						{Name: "issues", Desc: "Sync issues for all groups", Run: func(ctx context.Context, args []string) error { return s.syncer.SyncGroupIssues(ctx) }},
 					},
 				},
-				{Name: "projects", Desc: "Sync projects only", Run: func(args []string) error { return syncer.SyncProjects(context.Background()) }},
-				{Name: "issues", Desc: "Sync issues only", Run: func(args []string) error { return syncer.SyncIssues(context.Background()) }},
-				{Name: "status", Desc: "Show sync timestamps", Run: func(args []string) error { return syncer.ShowStatus() }},
+				{Name: "projects", Desc: "Sync projects only", Run: func(ctx context.Context, args []string) error { return s.syncer.SyncProjects(ctx) }},
+				{Name: "issues", Desc: "Sync issues only", Run: func(ctx context.Context, args []string) error { return s.syncer.SyncIssues(ctx) }},
+				{Name: "project", Desc: "Fetch every issue of one project", Arg: "<project>", Run: func(ctx context.Context, args []string) error { return s.syncer.SyncProject(ctx, args[0]) }},
+				{Name: "notes", Desc: "Sync notes and links of changed issues", Run: func(ctx context.Context, args []string) error { return s.syncer.SyncIssueNotes(ctx) }},
+				{Name: "mrs", Desc: "Sync merge requests only", Run: func(ctx context.Context, args []string) error { return s.syncer.SyncMergeRequests(ctx) }},
+				{Name: "milestones", Desc: "Sync milestones and iterations only", Run: func(ctx context.Context, args []string) error { return s.syncer.SyncMilestones(ctx) }},
+				{Name: "labels", Desc: "Sync group and project labels only", Run: func(ctx context.Context, args []string) error { return s.syncer.SyncLabels(ctx) }},
+				{Name: "todos", Desc: "Sync pending to-do items only", Run: func(ctx context.Context, args []string) error { return s.syncer.SyncTodos(ctx) }},
+				{Name: "reconcile", Desc: "Soft-delete records GitLab no longer lists", Run: func(ctx context.Context, args []string) error { return s.syncer.Reconcile(ctx) }},
+				{Name: "verify", Desc: "Check the store against GitLab's counts and checksums", Run: func(ctx context.Context, args []string) error { return s.syncer.Verify(ctx) }},
+				{Name: "status", Desc: "Show sync timestamps and drift", Run: func(ctx context.Context, args []string) error {
+					return s.syncer.ShowStatus(os.Stdout, render.Format(s.output))
+				}},
+			},
+		},
+		{
+			Name: "set", Desc: "Change REPL settings", REPLOnly: true,
+			Sub: []*replCmd{
+				{Name: "output", Desc: "Set output format (plain, table, json, ndjson, csv)", Arg: "<format>", Run: func(ctx context.Context, args []string) error { return s.setOutput(args[0]) }},
+				{Name: "workers", Desc: "Set how many requests a sync runs at once", Arg: "<n>", Run: func(ctx context.Context, args []string) error { return s.setWorkers(args[0]) }},
+				{Name: "progress", Desc: "Set sync progress (auto, live, plain, json, none)", Arg: "<mode>", Run: func(ctx context.Context, args []string) error { return s.setProgress(args[0]) }},
 			},
 		},
-		{Name: "help", Desc: "Show help", Run: func(args []string) error { buildHelp(cmds); return nil }},
-		{Name: "exit", Desc: "Quit"},
-		{Name: "quit", Desc: "Quit"},
+		s.profileCommand(),
+		{Name: "help", Desc: "Show help", REPLOnly: true, Run: func(ctx context.Context, args []string) error { buildHelp(cmds); return nil }},
+		{Name: "exit", Desc: "Quit", REPLOnly: true},
+		{Name: "quit", Desc: "Quit", REPLOnly: true},
 	}
+	s.resolveArgs(cmds, nil)
+	return cmds
+}
+
+func runREPL(s *session) error {
+	cmds := s.commands()
 
 	fmt.Println(styles.Banner.Render("GitLab REPL") + " — type 'exit' to quit, 'help' for commands.")
 	buildHelp(cmds)
 
 	executor := func(in string) {
-		parts := strings.Fields(in)
+		parts, err := tokenize(in)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, styles.Error.Render(err.Error()))
+			return
+		}
 		if len(parts) == 0 {
 			return
 		}
 		if parts[0] == "exit" || parts[0] == "quit" {
 			return // handled by OptionSetExitCheckerOnInput
 		}
-		if err := dispatch(cmds, parts); err != nil {
+		// Ctrl-C stops the running command, not the REPL.
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		if err := dispatch(ctx, cmds, parts); err != nil {
+			if ctx.Err() != nil {
+				err = fmt.Errorf("interrupted")
+			}
 			fmt.Fprintln(os.Stderr, styles.Error.Render(err.Error()))
 		}
 	}
@@ -134,7 +378,8 @@ func runREPL(g glclient.GitLab, syncer *gosync.Syncer) error {
 		if word == "" && text == "" {
 			if showAll {
 				showAll = false
-				return complete(cmds, nil)
+				suggestions, _ := complete(cmds, nil, s.argSuggestions)
+				return suggestions
 			}
 			return nil
 		}
@@ -145,10 +390,14 @@ func runREPL(g glclient.GitLab, syncer *gosync.Syncer) error {
 			completed = completed[:len(completed)-1]
 		}
 
-		suggestions := complete(cmds, completed)
+		suggestions, isArg := complete(cmds, completed, s.argSuggestions)
 		if word == "" {
 			return suggestions
 		}
+		if isArg {
+			// Paths are matched anywhere, as the resolver matches names.
+			return prompt.FilterFuzzy(suggestions, word, true)
+		}
 		return prompt.FilterHasPrefix(suggestions, word, true)
 	}
 
@@ -156,6 +405,13 @@ func runREPL(g glclient.GitLab, syncer *gosync.Syncer) error {
 
 	prompt.New(executor, completer,
 		prompt.OptionPrefix("❯ "),
+		prompt.OptionLivePrefix(func() (string, bool) {
+			// Show which instance commands go to, unless it is the default.
+			if s.profile.Name == "" || s.profile.Name == config.DefaultProfile {
+				return "", false
+			}
+			return s.profile.Name + " ❯ ", true
+		}),
 		prompt.OptionTitle("g2o GitLab REPL"),
 		prompt.OptionSetExitCheckerOnInput(func(in string, breakline bool) bool {
 			parts := strings.Fields(in)
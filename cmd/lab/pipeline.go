@@ -0,0 +1,53 @@
+package lab
+
+import (
+	"context"
+	"fmt"
+)
+
+// pipelineCommand builds the "pipeline" command: "pipeline <project> <id>"
+// shows a pipeline with its jobs and the subcommands act on one.
+func (s *session) pipelineCommand() *replCmd {
+	return &replCmd{
+		Name: "pipeline", Desc: "Show a pipeline with its jobs", Rest: "<project> <id>",
+		Run: func(ctx context.Context, args []string) error {
+			if len(args) != 2 {
+				return fmt.Errorf("usage: pipeline <project> <id>")
+			}
+			project, err := s.resolve("project", args[0])
+			if err != nil {
+				return err
+			}
+			return s.g.RunPipeline(ctx, project, args[1])
+		},
+		Sub: []*replCmd{
+			{Name: "retry", Desc: "Retry the failed jobs of a pipeline", Arg: "<project> <id>", Run: func(ctx context.Context, args []string) error { return s.g.RunRetryPipeline(ctx, args[0], args[1]) }},
+			{Name: "cancel", Desc: "Cancel a running pipeline", Arg: "<project> <id>", Run: func(ctx context.Context, args []string) error { return s.g.RunCancelPipeline(ctx, args[0], args[1]) }},
+		},
+	}
+}
+
+// jobCommand builds the "job" command. A job's project is remembered from
+// the pipeline it was listed with, so it only has to be given for jobs
+// never seen before.
+func (s *session) jobCommand() *replCmd {
+	return &replCmd{
+		Name: "job", Desc: "Job commands", Arg: "<id>",
+		Sub: []*replCmd{
+			{Name: "log", Desc: "Show a job's log, following it while the job runs", Rest: "[project]", Run: func(ctx context.Context, args []string) error {
+				var project string
+				switch len(args) {
+				case 1:
+				case 2:
+					var err error
+					if project, err = s.resolve("project", args[1]); err != nil {
+						return err
+					}
+				default:
+					return fmt.Errorf("usage: job <id> log [project]")
+				}
+				return s.g.RunJobLog(ctx, args[0], project)
+			}},
+		},
+	}
+}
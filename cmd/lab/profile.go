@@ -0,0 +1,70 @@
+package lab
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/chazzychouse/g2o/internal/config"
+	"github.com/chazzychouse/g2o/internal/render"
+	"github.com/chazzychouse/g2o/internal/styles"
+)
+
+// profileCommand builds the "profile" command, which lists the GitLab
+// instances from the config file and, in the REPL, switches between them.
+func (s *session) profileCommand() *replCmd {
+	return &replCmd{
+		Name: "profile", Desc: "List configured GitLab instances",
+		Run: func(ctx context.Context, args []string) error { return s.listProfiles() },
+		Sub: []*replCmd{
+			{Name: "use", Desc: "Switch to another profile", Arg: "<name>", REPLOnly: true, Run: func(ctx context.Context, args []string) error { return s.useProfile(args[0]) }},
+		},
+	}
+}
+
+func (s *session) listProfiles() error {
+	cfg, err := config.Load(config.DefaultPath())
+	if err != nil {
+		return err
+	}
+	var out []render.ProfileInfo
+	for _, name := range cfg.Names() {
+		p, err := cfg.Resolve(name)
+		if err != nil {
+			return err
+		}
+		baseURL := p.BaseURL
+		if baseURL == "" {
+			baseURL = "https://gitlab.com"
+		}
+		out = append(out, render.ProfileInfo{
+			Name:    p.Name,
+			BaseURL: baseURL,
+			Store:   p.DBPath(),
+			Active:  p.Name == s.profile.Name,
+		})
+	}
+	format, _ := render.ParseFormat(s.output)
+	return render.Profiles(os.Stdout, format, out)
+}
+
+// useProfile reopens the session against another profile. The current
+// session is kept if the new one cannot be opened.
+func (s *session) useProfile(name string) error {
+	next := &session{output: s.output, progress: s.progress, profileName: name}
+	if err := next.open(); err != nil {
+		next.close()
+		return err
+	}
+	if err := s.close(); err != nil {
+		fmt.Fprintln(os.Stderr, styles.Error.Render(err.Error()))
+	}
+	s.db, s.g, s.closers, s.profile, s.profileName =
+		next.db, next.g, next.closers, next.profile, name
+	// The syncer holds a pointer to the client it was built with.
+	if err := s.newSyncer(); err != nil {
+		return err
+	}
+	fmt.Println(styles.Success.Render("profile: " + s.profile.Name + " (" + s.profile.Host() + ")"))
+	return nil
+}
@@ -0,0 +1,105 @@
+package lab
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	prompt "github.com/c-bata/go-prompt"
+	"github.com/charmbracelet/x/term"
+	"github.com/chazzychouse/g2o/internal/glclient"
+	"github.com/chazzychouse/g2o/internal/styles"
+)
+
+// resolveArgs wraps the Run of every node that takes a <project> or <group>
+// argument, its own or an ancestor's, so the value may be an ID, a full
+// path or a short name; see glclient.ResolveProject.
+func (s *session) resolveArgs(cmds []*replCmd, placeholders []string) {
+	for _, c := range cmds {
+		own := append(placeholders[:len(placeholders):len(placeholders)], strings.Fields(c.Arg)...)
+		if c.Run != nil {
+			c.Run = s.resolving(own, c.Run)
+		}
+		s.resolveArgs(c.Sub, own)
+	}
+}
+
+func (s *session) resolving(placeholders []string, run func(context.Context, []string) error) func(context.Context, []string) error {
+	var kinds []string
+	for _, p := range placeholders {
+		kinds = append(kinds, strings.Trim(p, "<>"))
+	}
+	if !slices.Contains(kinds, "project") && !slices.Contains(kinds, "group") {
+		return run
+	}
+	return func(ctx context.Context, args []string) error {
+		args = slices.Clone(args)
+		for i, kind := range kinds {
+			if i >= len(args) || (kind != "project" && kind != "group") {
+				continue
+			}
+			v, err := s.resolve(kind, args[i])
+			if err != nil {
+				return err
+			}
+			args[i] = v
+		}
+		return run(ctx, args)
+	}
+}
+
+// resolve looks up a group or project name, asking which one was meant
+// when it is ambiguous or only a partial match and there is a terminal to
+// ask on.
+func (s *session) resolve(kind, name string) (string, error) {
+	resolve := s.g.ResolveProject
+	if kind == "group" {
+		resolve = s.g.ResolveGroup
+	}
+	v, err := resolve(name)
+	var amb *glclient.AmbiguousError
+	if !errors.As(err, &amb) || !term.IsTerminal(os.Stdin.Fd()) {
+		return v, err
+	}
+
+	if len(amb.Matches) == 1 {
+		fmt.Fprintf(os.Stderr, "%q only matches part of a %s path:\n", name, kind)
+	} else {
+		fmt.Fprintf(os.Stderr, "%q matches several %ss:\n", name, kind)
+	}
+	for i, m := range amb.Matches {
+		fmt.Fprintf(os.Stderr, "  %s %s\n", styles.Label.Render(fmt.Sprintf("%2d)", i+1)), m.Path)
+	}
+	fmt.Fprintf(os.Stderr, "Which %s? [1-%d] ", kind, len(amb.Matches))
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return "", amb
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil || n < 1 || n > len(amb.Matches) {
+		return "", amb
+	}
+	return resolve(amb.Matches[n-1].Path)
+}
+
+// argSuggestions offers the stored paths for a <project> or <group>
+// argument in the REPL.
+func (s *session) argSuggestions(placeholder string) []prompt.Suggest {
+	var paths []string
+	switch placeholder {
+	case "<project>":
+		paths = s.g.ProjectPaths()
+	case "<group>":
+		paths = s.g.GroupPaths()
+	}
+	out := make([]prompt.Suggest, len(paths))
+	for i, p := range paths {
+		out[i] = prompt.Suggest{Text: p}
+	}
+	return out
+}
@@ -0,0 +1,31 @@
+package lab
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+// todoCommand builds the "todo" command, which acts on the To-Do list.
+func (s *session) todoCommand() *replCmd {
+	var all bool
+	return &replCmd{
+		Name: "todo", Desc: "To-do item commands",
+		Sub: []*replCmd{
+			{
+				Name: "done", Desc: "Mark a to-do item done, or every one with --all", Rest: "[id]",
+				Flags: func(fs *pflag.FlagSet) { fs.BoolVar(&all, "all", false, "mark every pending to-do item done") },
+				Run: func(ctx context.Context, args []string) error {
+					switch {
+					case all && len(args) == 0:
+						return s.g.RunAllTodosDone(ctx)
+					case !all && len(args) == 1:
+						return s.g.RunTodoDone(ctx, args[0])
+					}
+					return fmt.Errorf("usage: todo done <id> | todo done --all")
+				},
+			},
+		},
+	}
+}
@@ -1,9 +1,12 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
 
+	"github.com/chazzychouse/g2o/cmd/auth"
 	"github.com/chazzychouse/g2o/cmd/lab"
 	"github.com/chazzychouse/g2o/internal/root"
 	"github.com/spf13/cobra"
@@ -13,18 +16,43 @@ var rootCmd = &cobra.Command{
 	Use:   "g2o",
 	Short: "g2o is a CLI application",
 	Long:  `g2o is a CLI application built with Cobra.`,
+	// Execute prints the error itself before exiting non-zero.
+	SilenceErrors: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return root.Run()
 	},
 }
 
 func init() {
-	rootCmd.AddCommand(lab.Command)
+	rootCmd.PersistentFlags().StringP("profile", "p", "",
+		"GitLab instance to use, as named in ~/.g2o/config.toml (default $G2O_PROFILE or the configured default)")
+	rootCmd.AddCommand(lab.Command, auth.Command)
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	if err := execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
+
+func execute() (err error) {
+	// Ctrl-C cancels the running command so it can stop cleanly, e.g. with
+	// a sync checkpoint saved.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	// The store is closed here, before os.Exit, whether the command
+	// succeeded or not.
+	defer func() {
+		if cerr := lab.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("interrupted")
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,346 @@
+// Package config reads ~/.g2o/config.toml, which describes the GitLab
+// instances g2o can talk to as named profiles:
+//
+//	default = "work"
+//
+//	[profiles.work]
+//	base_url  = "https://gitlab.example.com"
+//	token_env = "WORK_GITLAB_TOKEN"
+//	ca_file   = "~/certs/example-ca.pem"
+//	sync_workers = 8
+//	sync_overlap = "10m"
+//	issue_scopes = ["assigned", "created", "group:platform"]
+//
+//	[profiles.gitlab]
+//	base_url      = "https://gitlab.com"
+//	token_command = "pass show gitlab.com/token"
+//
+// Without a config file there is a single profile, "default", that talks
+// to gitlab.com with $GITLAB_TOKEN, as g2o always has.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultProfile is the name of the profile used when neither the config
+// file nor the command line picks one.
+const DefaultProfile = "default"
+
+var (
+	ErrUnknownProfile = fmt.Errorf("unknown profile")
+	ErrNoToken        = fmt.Errorf("no token")
+)
+
+// Profile is one GitLab instance and how to authenticate against it.
+type Profile struct {
+	Name    string
+	BaseURL string // empty means gitlab.com
+
+	// Where the token comes from; the first one set wins. With none set the
+	// token is read from $GITLAB_TOKEN.
+	Token        string // literal token; prefer one of the others
+	TokenEnv     string // environment variable holding the token
+	TokenFile    string // file whose first line is the token
+	TokenCommand string // shell command printing the token
+
+	// OAuthClientID is the application used by "auth login --device".
+	OAuthClientID string
+
+	CAFile    string // PEM bundle trusted in addition to the system roots
+	StorePath string // SQLite database; defaults to ~/.g2o/<name>.db
+
+	SyncWorkers int // concurrent requests during sync; 0 for the default
+	RateLimit   int // requests per second; 0 to follow GitLab's headers
+
+	// SyncOverlap is how far before the newest change already stored an
+	// incremental sync starts looking; 0 for the default.
+	SyncOverlap time.Duration
+
+	// IssueScopes are the sets of issues a sync fetches: "assigned",
+	// "created", "project:<path>" or "group:<path>". Empty means assigned.
+	IssueScopes []string
+}
+
+// Config is the parsed config file.
+type Config struct {
+	Path     string
+	Default  string
+	Profiles map[string]Profile
+}
+
+// Dir returns ~/.g2o, where the config file and databases live.
+func Dir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = "."
+	}
+	return filepath.Join(home, ".g2o")
+}
+
+// DefaultPath returns $G2O_CONFIG if set and ~/.g2o/config.toml otherwise.
+func DefaultPath() string {
+	if p := os.Getenv("G2O_CONFIG"); p != "" {
+		return p
+	}
+	return filepath.Join(Dir(), "config.toml")
+}
+
+// Load reads the config file at path. A missing file is not an error and
+// yields a config with no profiles.
+func Load(path string) (*Config, error) {
+	c := &Config{Path: path, Profiles: map[string]Profile{}}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	doc, err := parseTOML(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for key, v := range doc[""] {
+		switch key {
+		case "default":
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s: default must be a string", path)
+			}
+			c.Default = s
+		default:
+			return nil, fmt.Errorf("%s: unknown setting %q", path, key)
+		}
+	}
+	for name, t := range doc {
+		profile, ok := strings.CutPrefix(name, "profiles.")
+		if !ok {
+			if name != "" && name != "profiles" {
+				return nil, fmt.Errorf("%s: unknown table [%s]", path, name)
+			}
+			continue
+		}
+		if strings.Contains(profile, ".") {
+			return nil, fmt.Errorf("%s: unknown table [%s]", path, name)
+		}
+		p, err := decodeProfile(profile, t)
+		if err != nil {
+			return nil, fmt.Errorf("%s: profile %s: %w", path, profile, err)
+		}
+		c.Profiles[profile] = p
+	}
+	if c.Default != "" {
+		if _, ok := c.Profiles[c.Default]; !ok {
+			return nil, fmt.Errorf("%s: default profile %q is not defined", path, c.Default)
+		}
+	}
+	return c, nil
+}
+
+func decodeProfile(name string, t table) (Profile, error) {
+	p := Profile{Name: name}
+	fields := map[string]*string{
+		"base_url":        &p.BaseURL,
+		"token":           &p.Token,
+		"token_env":       &p.TokenEnv,
+		"token_file":      &p.TokenFile,
+		"token_command":   &p.TokenCommand,
+		"oauth_client_id": &p.OAuthClientID,
+		"ca_file":         &p.CAFile,
+		"store_path":      &p.StorePath,
+	}
+	ints := map[string]*int{
+		"sync_workers": &p.SyncWorkers,
+		"rate_limit":   &p.RateLimit,
+	}
+	durations := map[string]*time.Duration{
+		"sync_overlap": &p.SyncOverlap,
+	}
+	lists := map[string]*[]string{
+		"issue_scopes": &p.IssueScopes,
+	}
+	for key, v := range t {
+		if dst, ok := lists[key]; ok {
+			l, ok := v.([]string)
+			if !ok {
+				return p, fmt.Errorf("%s must be an array of strings", key)
+			}
+			*dst = l
+			continue
+		}
+		if dst, ok := durations[key]; ok {
+			s, ok := v.(string)
+			if !ok {
+				return p, fmt.Errorf("%s must be a duration such as \"5m\"", key)
+			}
+			d, err := time.ParseDuration(s)
+			if err != nil || d < 0 {
+				return p, fmt.Errorf("%s must be a duration such as \"5m\"", key)
+			}
+			*dst = d
+			continue
+		}
+		if dst, ok := ints[key]; ok {
+			n, ok := v.(int64)
+			if !ok || n < 0 {
+				return p, fmt.Errorf("%s must be a non-negative integer", key)
+			}
+			*dst = int(n)
+			continue
+		}
+		dst, ok := fields[key]
+		if !ok {
+			return p, fmt.Errorf("unknown setting %q", key)
+		}
+		s, ok := v.(string)
+		if !ok {
+			return p, fmt.Errorf("%s must be a string", key)
+		}
+		*dst = s
+	}
+	p.BaseURL = strings.TrimSuffix(p.BaseURL, "/")
+	p.TokenFile = expandHome(p.TokenFile)
+	p.CAFile = expandHome(p.CAFile)
+	p.StorePath = expandHome(p.StorePath)
+	return p, nil
+}
+
+// Names returns the profile names in alphabetical order, including the
+// implicit default profile when the file defines none.
+func (c *Config) Names() []string {
+	if len(c.Profiles) == 0 {
+		return []string{DefaultProfile}
+	}
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Resolve picks the profile to use: name if given, else $G2O_PROFILE, else
+// the configured default, else the only profile defined, else the implicit
+// default profile.
+func (c *Config) Resolve(name string) (Profile, error) {
+	if name == "" {
+		name = os.Getenv("G2O_PROFILE")
+	}
+	if name == "" {
+		name = c.Default
+	}
+	if name == "" && len(c.Profiles) == 1 {
+		for n := range c.Profiles {
+			name = n
+		}
+	}
+	if name == "" {
+		name = DefaultProfile
+	}
+	if p, ok := c.Profiles[name]; ok {
+		return p, nil
+	}
+	if name == DefaultProfile && len(c.Profiles) == 0 {
+		return Profile{Name: DefaultProfile}, nil
+	}
+	return Profile{}, fmt.Errorf("%w %q (have %s)", ErrUnknownProfile, name, strings.Join(c.Names(), ", "))
+}
+
+// Host returns the host name of the profile's GitLab instance.
+func (p Profile) Host() string {
+	u := p.BaseURL
+	if u == "" {
+		return "gitlab.com"
+	}
+	if _, rest, ok := strings.Cut(u, "://"); ok {
+		u = rest
+	}
+	host, _, _ := strings.Cut(u, "/")
+	return host
+}
+
+// DBPath returns the profile's SQLite database. The default profile keeps
+// the original ~/.g2o/g2o.db so existing caches carry over.
+func (p Profile) DBPath() string {
+	if p.StorePath != "" {
+		return p.StorePath
+	}
+	name := p.Name
+	if name == DefaultProfile {
+		name = "g2o"
+	}
+	return filepath.Join(Dir(), name+".db")
+}
+
+// TokenSourceName describes where the config file says the profile's token
+// comes from, or returns "" when it does not say.
+func (p Profile) TokenSourceName() string {
+	switch {
+	case p.Token != "":
+		return "token in " + DefaultPath()
+	case p.TokenEnv != "":
+		return "$" + p.TokenEnv
+	case p.TokenFile != "":
+		return p.TokenFile
+	case p.TokenCommand != "":
+		return "token_command"
+	}
+	return ""
+}
+
+// ReadToken returns the profile's token from its configured source.
+func (p Profile) ReadToken() (string, error) {
+	switch {
+	case p.Token != "":
+		return p.Token, nil
+	case p.TokenEnv != "":
+		if t := os.Getenv(p.TokenEnv); t != "" {
+			return t, nil
+		}
+		return "", fmt.Errorf("%w: %s is not set", ErrNoToken, p.TokenEnv)
+	case p.TokenFile != "":
+		data, err := os.ReadFile(p.TokenFile)
+		if err != nil {
+			return "", fmt.Errorf("read token file: %w", err)
+		}
+		return firstLine(data), nil
+	case p.TokenCommand != "":
+		var stderr bytes.Buffer
+		cmd := exec.Command("sh", "-c", p.TokenCommand)
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("token command: %w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return firstLine(out), nil
+	}
+	if t := os.Getenv("GITLAB_TOKEN"); t != "" {
+		return t, nil
+	}
+	return "", fmt.Errorf("%w: GITLAB_TOKEN is not set", ErrNoToken)
+}
+
+func firstLine(b []byte) string {
+	line, _, _ := strings.Cut(string(b), "\n")
+	return strings.TrimSpace(line)
+}
+
+func expandHome(p string) string {
+	if rest, ok := strings.CutPrefix(p, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return p
+}
@@ -0,0 +1,274 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// table is one [section] of a TOML document, keyed by its dotted name.
+type table map[string]any
+
+// parseTOML reads the subset of TOML that the config file needs: comments,
+// [tables] with dotted and quoted names, and key = value pairs whose value
+// is a basic or literal string, an integer, a boolean or an array of
+// strings, which may span lines. Tables are returned by their dotted path,
+// with the top level under "".
+func parseTOML(r io.Reader) (map[string]table, error) {
+	doc := map[string]table{"": {}}
+	// Tables given a [header] and tables made by dotted keys. A header may
+	// only appear once and dotted keys may not reopen a headed table, but a
+	// table only named as the parent of another, such as [profiles] before
+	// [profiles.work], may still get its header later.
+	headers, dotted := map[string]bool{}, map[string]bool{}
+	current := ""
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			if strings.HasPrefix(line, "[[") {
+				return nil, fmt.Errorf("line %d: arrays of tables are not supported", n)
+			}
+			end := indexUnquoted(line, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: invalid table header", n)
+			}
+			if rest := strings.TrimSpace(line[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+				return nil, fmt.Errorf("line %d: unexpected %q after table header", n, rest)
+			}
+			parts, err := splitKey(line[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n, err)
+			}
+			current = strings.Join(parts, ".")
+			if headers[current] || dotted[current] {
+				return nil, fmt.Errorf("line %d: table [%s] defined twice", n, current)
+			}
+			headers[current] = true
+			if doc[current] == nil {
+				doc[current] = table{}
+			}
+			continue
+		}
+
+		eq := indexUnquoted(line, '=')
+		if eq < 0 {
+			return nil, fmt.Errorf("line %d: expected key = value", n)
+		}
+		parts, err := splitKey(line[:eq])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		start := n
+		raw := strings.TrimSpace(line[eq+1:])
+		if strings.HasPrefix(raw, "[") {
+			// An array runs on to the line holding its closing bracket;
+			// comments end at each line break, so they are dropped first.
+			raw = stripComment(raw)
+			for indexUnquoted(raw, ']') < 0 && sc.Scan() {
+				n++
+				raw += " " + stripComment(strings.TrimSpace(sc.Text()))
+			}
+		}
+		value, err := parseValue(raw)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", start, err)
+		}
+		// Dotted keys (a.b = 1) belong to a sub-table.
+		name := current
+		for _, p := range parts[:len(parts)-1] {
+			if name != "" {
+				name += "."
+			}
+			name += p
+			if headers[name] {
+				return nil, fmt.Errorf("line %d: table [%s] defined twice", n, name)
+			}
+			dotted[name] = true
+		}
+		if doc[name] == nil {
+			doc[name] = table{}
+		}
+		key := parts[len(parts)-1]
+		if _, ok := doc[name][key]; ok {
+			return nil, fmt.Errorf("line %d: duplicate key %q", n, key)
+		}
+		doc[name][key] = value
+	}
+	return doc, sc.Err()
+}
+
+// splitKey splits a dotted key such as profiles."my work" into its parts.
+func splitKey(s string) ([]string, error) {
+	var parts []string
+	s = strings.TrimSpace(s)
+	for s != "" {
+		var part string
+		switch s[0] {
+		case '"', '\'':
+			end := strings.IndexByte(s[1:], s[0])
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quoted key")
+			}
+			part, s = s[1:end+1], s[end+2:]
+		default:
+			end := strings.IndexByte(s, '.')
+			if end < 0 {
+				end = len(s)
+			}
+			part, s = strings.TrimSpace(s[:end]), s[end:]
+			if part == "" || strings.ContainsAny(part, " \t\"'") {
+				return nil, fmt.Errorf("invalid key %q", part)
+			}
+		}
+		parts = append(parts, part)
+		s = strings.TrimSpace(s)
+		if s != "" {
+			if s[0] != '.' {
+				return nil, fmt.Errorf("invalid key")
+			}
+			s = strings.TrimSpace(s[1:])
+		}
+	}
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("empty key")
+	}
+	return parts, nil
+}
+
+func parseValue(s string) (any, error) {
+	switch {
+	case strings.HasPrefix(s, "["):
+		return parseArray(s)
+	case strings.HasPrefix(s, `"`), strings.HasPrefix(s, "'"):
+		v, rest, err := parseString(s)
+		if err != nil {
+			return nil, err
+		}
+		return v, trailing(rest)
+	}
+
+	if i := strings.Index(s, "#"); i >= 0 {
+		s = strings.TrimSpace(s[:i])
+	}
+	switch s {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	if n, err := strconv.ParseInt(strings.ReplaceAll(s, "_", ""), 10, 64); err == nil {
+		return n, nil
+	}
+	return nil, fmt.Errorf("unsupported value %q", s)
+}
+
+// parseArray reads an array of strings, such as ["a", 'b'], written on one
+// line.
+func parseArray(s string) ([]string, error) {
+	out := []string{}
+	s = strings.TrimSpace(s[1:])
+	for {
+		if strings.HasPrefix(s, "]") {
+			return out, trailing(s[1:])
+		}
+		if s == "" || (s[0] != '"' && s[0] != '\'') {
+			return nil, fmt.Errorf("arrays may only hold strings")
+		}
+		v, rest, err := parseString(s)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+		s = strings.TrimSpace(rest)
+		switch {
+		case strings.HasPrefix(s, ","):
+			s = strings.TrimSpace(s[1:])
+		case !strings.HasPrefix(s, "]"):
+			return nil, fmt.Errorf("expected , or ] in array")
+		}
+	}
+}
+
+// parseString reads the basic ("...") or literal ('...') string at the
+// start of s and returns it with what follows.
+func parseString(s string) (string, string, error) {
+	if s[0] == '\'' {
+		end := strings.IndexByte(s[1:], '\'')
+		if end < 0 {
+			return "", "", fmt.Errorf("unterminated string")
+		}
+		return s[1 : end+1], s[end+2:], nil
+	}
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '"':
+			return b.String(), s[i+1:], nil
+		case '\\':
+			if i+1 >= len(s) {
+				return "", "", fmt.Errorf("unterminated string")
+			}
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case '"', '\\':
+				b.WriteByte(s[i])
+			default:
+				return "", "", fmt.Errorf("unsupported escape \\%c", s[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", "", fmt.Errorf("unterminated string")
+}
+
+// indexUnquoted returns the index of the first c in s outside a quoted
+// string or comment, or -1.
+func indexUnquoted(s string, c byte) int {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quote == '"' && s[i] == '\\':
+			i++
+		case quote != 0:
+			if s[i] == quote {
+				quote = 0
+			}
+		case s[i] == c:
+			return i
+		case s[i] == '"' || s[i] == '\'':
+			quote = s[i]
+		case s[i] == '#':
+			return -1
+		}
+	}
+	return -1
+}
+
+// stripComment removes a comment from the end of s.
+func stripComment(s string) string {
+	if i := indexUnquoted(s, '#'); i >= 0 {
+		return strings.TrimSpace(s[:i])
+	}
+	return s
+}
+
+// trailing checks that only a comment follows a value.
+func trailing(s string) error {
+	s = strings.TrimSpace(s)
+	if s != "" && !strings.HasPrefix(s, "#") {
+		return fmt.Errorf("unexpected %q after value", s)
+	}
+	return nil
+}
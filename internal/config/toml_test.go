@@ -0,0 +1,152 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want map[string]table
+	}{
+		{
+			name: "top level",
+			doc:  "default = \"work\"\nworkers = 8\ncolor = false\n",
+			want: map[string]table{"": {"default": "work", "workers": int64(8), "color": false}},
+		},
+		{
+			name: "comments and blank lines",
+			doc:  "# settings\n\n  # indented\nworkers = 1_000 # big\ncolor = true#tight\n",
+			want: map[string]table{"": {"workers": int64(1000), "color": true}},
+		},
+		{
+			name: "basic strings",
+			doc:  `a = "tab\there" # note` + "\n" + `b = "say \"hi\" \\ # not a comment"` + "\n" + `c = ""`,
+			want: map[string]table{"": {"a": "tab\there", "b": `say "hi" \ # not a comment`, "c": ""}},
+		},
+		{
+			name: "literal strings",
+			doc:  `path = 'C:\Users\me' # windows` + "\n" + `hash = '#1'`,
+			want: map[string]table{"": {"path": `C:\Users\me`, "hash": "#1"}},
+		},
+		{
+			name: "tables",
+			doc:  "[profiles.work]\nhost = \"gitlab.example.com\"\n[profiles.home]\nhost = \"gitlab.com\"\n",
+			want: map[string]table{
+				"":              {},
+				"profiles.work": {"host": "gitlab.example.com"},
+				"profiles.home": {"host": "gitlab.com"},
+			},
+		},
+		{
+			name: "quoted table names",
+			doc:  "[ profiles . \"my work\" ]\nhost = 'a'\n['x.y']\nz = 1\n",
+			want: map[string]table{"": {}, "profiles.my work": {"host": "a"}, "x.y": {"z": int64(1)}},
+		},
+		{
+			name: "table header with a comment holding ]",
+			doc:  "[profiles.work] # see [docs]\nhost = \"h\"\n",
+			want: map[string]table{"": {}, "profiles.work": {"host": "h"}},
+		},
+		{
+			name: "table name holding ] and =",
+			doc:  "[profiles.\"a]=b\"]\nhost = \"h\"\n",
+			want: map[string]table{"": {}, "profiles.a]=b": {"host": "h"}},
+		},
+		{
+			name: "dotted keys",
+			doc:  "sync.workers = 4\n[profiles]\nwork.host = \"h\"\n\"my home\".host = \"g\"\n",
+			want: map[string]table{
+				"":                 {},
+				"sync":             {"workers": int64(4)},
+				"profiles":         {},
+				"profiles.work":    {"host": "h"},
+				"profiles.my home": {"host": "g"},
+			},
+		},
+		{
+			name: "quoted key holding =",
+			doc:  `"a=b" = "c=d"`,
+			want: map[string]table{"": {"a=b": "c=d"}},
+		},
+		{
+			name: "arrays",
+			doc:  `a = []` + "\n" + `b = ["x", 'y' , "z, \"w\""] # three` + "\n" + `c = ["]"]`,
+			want: map[string]table{"": {"a": []string{}, "b": []string{"x", "y", `z, "w"`}, "c": []string{"]"}}},
+		},
+		{
+			name: "multi-line arrays",
+			doc: "issue_scopes = [\n  \"group:a\", # first\n  'project:b#1',\n\n  \"x]\",\n] # done\n" +
+				"empty = [\n]\nafter = 1\n",
+			want: map[string]table{"": {
+				"issue_scopes": []string{"group:a", "project:b#1", "x]"},
+				"empty":        []string{},
+				"after":        int64(1),
+			}},
+		},
+		{
+			name: "parent table after its child",
+			doc:  "[profiles.work]\nhost = \"h\"\n[profiles]\n",
+			want: map[string]table{"": {}, "profiles.work": {"host": "h"}, "profiles": {}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTOML(strings.NewReader(tt.doc))
+			if err != nil {
+				t.Fatalf("parseTOML: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v\nwant %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTOMLErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{"unclosed header", "[profiles", "line 1: invalid table header"},
+		{"comment in header", "[profiles # x]", "line 1: invalid table header"},
+		{"array of tables", "[[profiles]]", "line 1: arrays of tables are not supported"},
+		{"text after header", "[a] b", `line 1: unexpected "b" after table header`},
+		{"table twice", "[a]\nx = 1\n[a]\ny = 2", "line 3: table [a] defined twice"},
+		{"empty table twice", "[a]\n[b]\n[a]", "line 3: table [a] defined twice"},
+		{"dotted key into a table with a header", "[a.b]\nc = 1\n[a]\nb.d = 2", "line 4: table [a.b] defined twice"},
+		{"duplicate key", "x = 1\nx = 2", `line 2: duplicate key "x"`},
+		{"duplicate dotted key", "[a]\nb.c = 1\n[a.b]\nc = 2", "line 3: table [a.b] defined twice"},
+		{"no equals", "x", "line 1: expected key = value"},
+		{"empty key", "= 1", "line 1: empty key"},
+		{"space in bare key", "a b = 1", `line 1: invalid key "a b"`},
+		{"unterminated quoted key", `"a = 1`, "line 1: expected key = value"},
+		{"unterminated string", `x = "abc`, "line 1: unterminated string"},
+		{"unterminated literal", `x = 'abc`, "line 1: unterminated string"},
+		{"bad escape", `x = "\u0041"`, `line 1: unsupported escape \u`},
+		{"text after string", `x = "a" b`, `line 1: unexpected "b" after value`},
+		{"float", "x = 1.5", `line 1: unsupported value "1.5"`},
+		{"bare word", "x = yes", `line 1: unsupported value "yes"`},
+		{"array of numbers", "x = [1, 2]", "line 1: arrays may only hold strings"},
+		{"array without comma", `x = ["a" "b"]`, "line 1: expected , or ] in array"},
+		{"unclosed array", `x = ["a",`, "line 1: arrays may only hold strings"},
+		{"unclosed multi-line array", "x = [\n  \"a\",\n\n", "line 1: arrays may only hold strings"},
+		{"bad multi-line array", "a = 1\nx = [\n  \"a\"\n  \"b\",\n]", "line 2: expected , or ] in array"},
+		{"text after array", `x = ["a"] b`, `line 1: unexpected "b" after value`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseTOML(strings.NewReader(tt.doc))
+			if err == nil {
+				t.Fatalf("parseTOML succeeded, want %q", tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %q, want %q", err, tt.want)
+			}
+		})
+	}
+}
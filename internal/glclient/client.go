@@ -1,19 +1,37 @@
 package glclient
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"fmt"
 	"io"
 	"log/slog"
+	"net/http"
+	"os"
 
+	"github.com/chazzychouse/g2o/internal/render"
 	"github.com/chazzychouse/g2o/internal/store"
 	gitlab "gitlab.com/gitlab-org/api/client-go"
+	"golang.org/x/oauth2"
+	"golang.org/x/time/rate"
 )
 
 type GitLab struct {
-	client *gitlab.Client
-	log    *slog.Logger
-	dump   *json.Encoder
-	store  *store.Store
+	client      *gitlab.Client
+	baseURL     string
+	httpClient  *http.Client
+	tokenSource oauth2.TokenSource
+	workers     int
+	rateLimit   float64
+	retries     int
+	slots       chan struct{} // request slots shared by copies of the client
+	log         *slog.Logger
+	dump        *json.Encoder
+	store       *store.Store
+	out         io.Writer
+	format      render.Format
 }
 
 type Option func(*GitLab)
@@ -30,30 +48,177 @@ func WithStore(s *store.Store) Option {
 	return func(g *GitLab) { g.store = s }
 }
 
-func NewGitlab(token string, opts ...Option) (GitLab, error) {
-	if token == "" {
-		return GitLab{}, ErrTokenRequired
+// WithBaseURL points the client at a self-managed instance instead of
+// gitlab.com.
+func WithBaseURL(u string) Option {
+	return func(g *GitLab) { g.baseURL = u }
+}
+
+// WithHTTPClient sends requests through hc, e.g. one from NewHTTPClient
+// that trusts a private CA.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(g *GitLab) { g.httpClient = hc }
+}
+
+// WithTokenSource authenticates with OAuth tokens from ts instead of the
+// token passed to NewGitlab.
+func WithTokenSource(ts oauth2.TokenSource) Option {
+	return func(g *GitLab) { g.tokenSource = ts }
+}
+
+// WithConcurrency sets how many requests run at once during syncs and
+// paginated fetches.
+func WithConcurrency(n int) Option {
+	return func(g *GitLab) {
+		g.workers = n
+		g.slots = make(chan struct{}, g.Workers())
 	}
+}
 
-	client, err := gitlab.NewClient(token)
-	if err != nil {
-		return GitLab{}, ErrClientCreationFailed
+// WithRateLimit caps requests per second. Without it the limit is taken
+// from the RateLimit headers of GitLab's first response.
+func WithRateLimit(perSecond float64) Option {
+	return func(g *GitLab) { g.rateLimit = perSecond }
+}
+
+// WithRetries sets how often a request that failed transiently is retried;
+// see transport.
+func WithRetries(n int) Option {
+	return func(g *GitLab) { g.retries = n }
+}
+
+// WithOutput sets where the Run* commands write and in which format.
+func WithOutput(w io.Writer, f render.Format) Option {
+	return func(g *GitLab) { g.out = w; g.format = f }
+}
+
+// Apply applies opts to an existing client, e.g. to change the output format
+// from the REPL.
+func (g *GitLab) Apply(opts ...Option) {
+	for _, opt := range opts {
+		opt(g)
 	}
+}
 
+func NewGitlab(token string, opts ...Option) (GitLab, error) {
 	g := GitLab{
-		client: client,
-		log:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+		log:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+		out:     os.Stdout,
+		format:  render.Plain,
+		retries: DefaultRetries,
 	}
 	for _, opt := range opts {
 		opt(&g)
 	}
+
+	if token == "" && g.tokenSource == nil {
+		return GitLab{}, ErrTokenRequired
+	}
+
+	var clientOpts []gitlab.ClientOptionFunc
+	if g.baseURL != "" {
+		clientOpts = append(clientOpts, gitlab.WithBaseURL(g.baseURL))
+	}
+	// Retries happen in our transport, which knows about GitLab's rate
+	// limit headers, rather than in the client library.
+	hc := &http.Client{}
+	if g.httpClient != nil {
+		*hc = *g.httpClient
+	}
+	hc.Transport = newTransport(hc.Transport, g.retries, g.log)
+	clientOpts = append(clientOpts, gitlab.WithHTTPClient(hc), gitlab.WithCustomRetryMax(0))
+	if g.rateLimit > 0 {
+		burst := max(1, int(g.rateLimit))
+		clientOpts = append(clientOpts, gitlab.WithCustomLimiter(rate.NewLimiter(rate.Limit(g.rateLimit), burst)))
+	}
+	if g.slots == nil {
+		g.slots = make(chan struct{}, g.Workers())
+	}
+	var client *gitlab.Client
+	var err error
+	if g.tokenSource != nil {
+		client, err = gitlab.NewAuthSourceClient(tokenAuth{g.tokenSource}, clientOpts...)
+	} else {
+		client, err = gitlab.NewClient(token, clientOpts...)
+	}
+	if err != nil {
+		return GitLab{}, fmt.Errorf("%w: %w", ErrClientCreationFailed, err)
+	}
+	g.client = client
 	return g, nil
 }
 
+// tokenAuth authenticates requests with OAuth tokens from ts. Unlike
+// gitlab.OAuthTokenSource it hands the request's context to sources that
+// take one, so cancelling a command also cancels a token refresh.
+type tokenAuth struct {
+	ts oauth2.TokenSource
+}
+
+func (tokenAuth) Init(context.Context, *gitlab.Client) error {
+	return nil
+}
+
+func (a tokenAuth) Header(ctx context.Context) (string, string, error) {
+	var t *oauth2.Token
+	var err error
+	if cts, ok := a.ts.(interface {
+		TokenContext(context.Context) (*oauth2.Token, error)
+	}); ok {
+		t, err = cts.TokenContext(ctx)
+	} else {
+		t, err = a.ts.Token()
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return "Authorization", "Bearer " + t.AccessToken, nil
+}
+
+// NewHTTPClient returns an HTTP client that trusts the certificates in the
+// PEM file at caFile as well as the system roots. Without a file it returns
+// a plain client.
+func NewHTTPClient(caFile string) (*http.Client, error) {
+	if caFile == "" {
+		return &http.Client{Transport: http.DefaultTransport.(*http.Transport).Clone()}, nil
+	}
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCABundle, err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("%w: no certificates in %s", ErrInvalidCABundle, caFile)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	return &http.Client{Transport: transport}, nil
+}
+
 func (g GitLab) CurrentUser() (*gitlab.User, error) {
-	user, _, err := g.client.Users.CurrentUser()
+	user, resp, err := g.client.Users.CurrentUser()
 	if err != nil {
-		return nil, ErrCurrentUserFailed
+		return nil, apiError(ErrCurrentUserFailed, resp, err)
 	}
 	return user, nil
 }
+
+// Whoami checks the client's credentials and returns the user they belong
+// to. A rejected token matches ErrUnauthorized and an unreachable server
+// ErrOffline.
+func (g GitLab) Whoami() (*gitlab.User, error) {
+	return g.CurrentUser()
+}
+
+// TokenInfo returns the personal access token the client authenticates
+// with, for its scopes and expiry date.
+func (g GitLab) TokenInfo() (*gitlab.PersonalAccessToken, error) {
+	pat, resp, err := g.client.PersonalAccessTokens.GetSinglePersonalAccessToken()
+	if err != nil {
+		return nil, apiError(ErrTokenInfoFailed, resp, err)
+	}
+	return pat, nil
+}
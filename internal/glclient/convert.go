@@ -0,0 +1,476 @@
+package glclient
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/chazzychouse/g2o/internal/render"
+	"github.com/chazzychouse/g2o/internal/store"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// ConvertGroups maps API groups to their store representation.
+func ConvertGroups(groups []*gitlab.Group) []store.StoreGroup {
+	out := make([]store.StoreGroup, len(groups))
+	for i, g := range groups {
+		out[i] = store.StoreGroup{
+			ID:          g.ID,
+			Name:        g.Name,
+			Path:        g.Path,
+			FullName:    g.FullName,
+			FullPath:    g.FullPath,
+			Description: g.Description,
+			Visibility:  string(g.Visibility),
+			WebURL:      g.WebURL,
+			ParentID:    g.ParentID,
+			CreatedAt:   ptrTime(g.CreatedAt),
+		}
+	}
+	return out
+}
+
+// ConvertProjects maps API projects to their store representation.
+func ConvertProjects(projects []*gitlab.Project) []store.StoreProject {
+	out := make([]store.StoreProject, len(projects))
+	for i, p := range projects {
+		var nsID int64
+		if p.Namespace != nil {
+			nsID = p.Namespace.ID
+		}
+		out[i] = store.StoreProject{
+			ID:                p.ID,
+			Name:              p.Name,
+			Path:              p.Path,
+			PathWithNamespace: p.PathWithNamespace,
+			NameWithNamespace: p.NameWithNamespace,
+			Description:       p.Description,
+			DefaultBranch:     p.DefaultBranch,
+			Visibility:        string(p.Visibility),
+			WebURL:            p.WebURL,
+			NamespaceID:       nsID,
+			CreatedAt:         ptrTime(p.CreatedAt),
+			UpdatedAt:         ptrTime(p.UpdatedAt),
+			LastActivityAt:    ptrTime(p.LastActivityAt),
+			Archived:          p.Archived,
+			OpenIssuesCount:   p.OpenIssuesCount,
+		}
+	}
+	return out
+}
+
+// ConvertIssues maps API issues to their store representation.
+func ConvertIssues(issues []*gitlab.Issue) []store.StoreIssue {
+	out := make([]store.StoreIssue, len(issues))
+	for i, issue := range issues {
+		si := store.StoreIssue{
+			ID:           issue.ID,
+			IID:          issue.IID,
+			ProjectID:    issue.ProjectID,
+			Title:        issue.Title,
+			State:        issue.State,
+			Description:  issue.Description,
+			WebURL:       issue.WebURL,
+			Labels:       []string(issue.Labels),
+			CreatedAt:    ptrTime(issue.CreatedAt),
+			UpdatedAt:    ptrTime(issue.UpdatedAt),
+			ClosedAt:     ptrTime(issue.ClosedAt),
+			Weight:       issue.Weight,
+			Confidential: issue.Confidential,
+		}
+		if issue.DueDate != nil {
+			si.DueDate = time.Time(*issue.DueDate).Format("2006-01-02")
+		}
+		if issue.Author != nil {
+			si.AuthorID = issue.Author.ID
+			si.AuthorName = issue.Author.Name
+			si.AuthorUsername = issue.Author.Username
+		}
+		for _, a := range issue.Assignees {
+			si.Assignees = append(si.Assignees, store.StoreAssignee{
+				ID:       a.ID,
+				Name:     a.Name,
+				Username: a.Username,
+			})
+		}
+		if issue.Milestone != nil {
+			si.MilestoneTitle = issue.Milestone.Title
+			si.MilestoneID = issue.Milestone.ID
+		}
+		if issue.Iteration != nil {
+			si.IterationID = issue.Iteration.ID
+		}
+		if issue.TimeStats != nil {
+			si.TimeEstimate = issue.TimeStats.TimeEstimate
+			si.TimeSpent = issue.TimeStats.TotalTimeSpent
+		}
+		si.UserNotesCount = issue.UserNotesCount
+		if si.Labels == nil {
+			si.Labels = []string{}
+		}
+		if si.Assignees == nil {
+			si.Assignees = []store.StoreAssignee{}
+		}
+		out[i] = si
+	}
+	return out
+}
+
+// ConvertUser maps the API user to its store representation.
+func ConvertUser(u *gitlab.User) store.StoreUser {
+	return store.StoreUser{
+		ID:       u.ID,
+		Name:     u.Name,
+		Username: u.Username,
+		Email:    u.Email,
+		WebURL:   u.WebURL,
+	}
+}
+
+func ptrTime(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
+
+// ConvertIssueNotes maps the notes of one issue to their store
+// representation.
+func ConvertIssueNotes(issueID int64, notes []*gitlab.Note) []store.StoreIssueNote {
+	out := make([]store.StoreIssueNote, len(notes))
+	for i, n := range notes {
+		out[i] = store.StoreIssueNote{
+			ID:             n.ID,
+			IssueID:        issueID,
+			Body:           n.Body,
+			AuthorID:       n.Author.ID,
+			AuthorName:     n.Author.Name,
+			AuthorUsername: n.Author.Username,
+			System:         n.System,
+			Internal:       n.Internal,
+			CreatedAt:      ptrTime(n.CreatedAt),
+			UpdatedAt:      ptrTime(n.UpdatedAt),
+		}
+	}
+	return out
+}
+
+// ConvertIssueLinks maps the related issues of one issue to their store
+// representation.
+func ConvertIssueLinks(issueID int64, relations []*gitlab.IssueRelation) []store.StoreIssueLink {
+	out := make([]store.StoreIssueLink, len(relations))
+	for i, r := range relations {
+		l := store.StoreIssueLink{
+			IssueID:       issueID,
+			LinkedIssueID: r.ID,
+			LinkType:      r.LinkType,
+			ProjectID:     r.ProjectID,
+			IID:           r.IID,
+			Title:         r.Title,
+			State:         r.State,
+			WebURL:        r.WebURL,
+		}
+		if r.References != nil {
+			l.Reference = r.References.Full
+		}
+		out[i] = l
+	}
+	return out
+}
+
+// ConvertMergeRequests maps API merge requests to their store
+// representation. Pipeline and approval fields are left empty; see
+// ConvertMergeRequestDetail.
+func ConvertMergeRequests(mrs []*gitlab.BasicMergeRequest) []store.StoreMergeRequest {
+	out := make([]store.StoreMergeRequest, len(mrs))
+	for i, mr := range mrs {
+		out[i] = convertMergeRequest(mr)
+	}
+	return out
+}
+
+// ConvertMergeRequestDetail maps a single merge request including its head
+// pipeline and approval state.
+func ConvertMergeRequestDetail(d MergeRequestDetail) store.StoreMergeRequest {
+	smr := convertMergeRequest(&d.BasicMergeRequest)
+	if p := d.HeadPipeline; p != nil {
+		smr.PipelineID = p.ID
+		smr.PipelineStatus = p.Status
+	}
+	if a := d.Approvals; a != nil {
+		smr.ApprovalsRequired = a.ApprovalsRequired
+		smr.ApprovalsLeft = a.ApprovalsLeft
+		for _, u := range a.ApprovedBy {
+			if u != nil && u.User != nil {
+				smr.ApprovedBy = append(smr.ApprovedBy, convertBasicUser(u.User))
+			}
+		}
+	}
+	if smr.ApprovedBy == nil {
+		smr.ApprovedBy = []store.StoreAssignee{}
+	}
+	smr.DetailSyncedAt = time.Now().UTC()
+	return smr
+}
+
+func convertMergeRequest(mr *gitlab.BasicMergeRequest) store.StoreMergeRequest {
+	smr := store.StoreMergeRequest{
+		ID:                  mr.ID,
+		IID:                 mr.IID,
+		ProjectID:           mr.ProjectID,
+		Title:               mr.Title,
+		State:               mr.State,
+		Description:         mr.Description,
+		WebURL:              mr.WebURL,
+		SourceBranch:        mr.SourceBranch,
+		TargetBranch:        mr.TargetBranch,
+		Draft:               mr.Draft,
+		Labels:              []string(mr.Labels),
+		DetailedMergeStatus: mr.DetailedMergeStatus,
+		HasConflicts:        mr.HasConflicts,
+		CreatedAt:           ptrTime(mr.CreatedAt),
+		UpdatedAt:           ptrTime(mr.UpdatedAt),
+		MergedAt:            ptrTime(mr.MergedAt),
+		ClosedAt:            ptrTime(mr.ClosedAt),
+		Assignees:           []store.StoreAssignee{},
+		Reviewers:           []store.StoreAssignee{},
+	}
+	if mr.Author != nil {
+		smr.AuthorID = mr.Author.ID
+		smr.AuthorName = mr.Author.Name
+		smr.AuthorUsername = mr.Author.Username
+	}
+	for _, a := range mr.Assignees {
+		smr.Assignees = append(smr.Assignees, convertBasicUser(a))
+	}
+	for _, r := range mr.Reviewers {
+		smr.Reviewers = append(smr.Reviewers, convertBasicUser(r))
+	}
+	if smr.Labels == nil {
+		smr.Labels = []string{}
+	}
+	return smr
+}
+
+func convertBasicUser(u *gitlab.BasicUser) store.StoreAssignee {
+	return store.StoreAssignee{ID: u.ID, Name: u.Name, Username: u.Username}
+}
+
+// ConvertMilestones maps API project milestones to their store
+// representation.
+func ConvertMilestones(ms []*gitlab.Milestone) []store.StoreMilestone {
+	out := make([]store.StoreMilestone, len(ms))
+	for i, m := range ms {
+		out[i] = store.StoreMilestone{
+			ID:          m.ID,
+			IID:         m.IID,
+			GroupID:     m.GroupID,
+			ProjectID:   m.ProjectID,
+			Title:       m.Title,
+			Description: m.Description,
+			State:       m.State,
+			StartDate:   isoDate(m.StartDate),
+			DueDate:     isoDate(m.DueDate),
+			WebURL:      m.WebURL,
+			CreatedAt:   ptrTime(m.CreatedAt),
+			UpdatedAt:   ptrTime(m.UpdatedAt),
+		}
+	}
+	return out
+}
+
+// ConvertGroupMilestones maps API group milestones to their store
+// representation.
+func ConvertGroupMilestones(ms []*gitlab.GroupMilestone) []store.StoreMilestone {
+	out := make([]store.StoreMilestone, len(ms))
+	for i, m := range ms {
+		out[i] = store.StoreMilestone{
+			ID:          m.ID,
+			IID:         m.IID,
+			GroupID:     m.GroupID,
+			Title:       m.Title,
+			Description: m.Description,
+			State:       m.State,
+			StartDate:   isoDate(m.StartDate),
+			DueDate:     isoDate(m.DueDate),
+			CreatedAt:   ptrTime(m.CreatedAt),
+			UpdatedAt:   ptrTime(m.UpdatedAt),
+		}
+	}
+	return out
+}
+
+// iterationStates names the states GitLab reports iterations in.
+var iterationStates = map[int64]string{1: "upcoming", 2: "current", 3: "closed"}
+
+// ConvertIterations maps API iterations to their store representation.
+func ConvertIterations(its []*gitlab.GroupIteration) []store.StoreIteration {
+	out := make([]store.StoreIteration, len(its))
+	for i, it := range its {
+		out[i] = store.StoreIteration{
+			ID:          it.ID,
+			IID:         it.IID,
+			Sequence:    it.Sequence,
+			GroupID:     it.GroupID,
+			Title:       it.Title,
+			Description: it.Description,
+			State:       iterationStates[it.State],
+			StartDate:   isoDate(it.StartDate),
+			DueDate:     isoDate(it.DueDate),
+			WebURL:      it.WebURL,
+			CreatedAt:   ptrTime(it.CreatedAt),
+			UpdatedAt:   ptrTime(it.UpdatedAt),
+		}
+		if out[i].Title == "" {
+			// Iterations of automatic cadences are untitled.
+			out[i].Title = fmt.Sprintf("%s – %s", out[i].StartDate, out[i].DueDate)
+		}
+	}
+	return out
+}
+
+func isoDate(d *gitlab.ISOTime) string {
+	if d == nil {
+		return ""
+	}
+	return d.String()
+}
+
+// ConvertLabels maps API labels for rendering.
+func ConvertLabels(labels []*gitlab.Label) []render.Label {
+	out := make([]render.Label, len(labels))
+	for i, l := range labels {
+		out[i] = render.Label{
+			Name:              l.Name,
+			Color:             l.Color,
+			TextColor:         l.TextColor,
+			Description:       l.Description,
+			OpenIssues:        l.OpenIssuesCount,
+			ClosedIssues:      l.ClosedIssuesCount,
+			OpenMergeRequests: l.OpenMergeRequestsCount,
+		}
+	}
+	return out
+}
+
+// ConvertCatalogueLabels maps the labels defined by a group (groupID) or
+// a project (projectID) to their store representation.
+func ConvertCatalogueLabels(labels []*gitlab.Label, groupID, projectID int64) []store.StoreLabel {
+	out := make([]store.StoreLabel, len(labels))
+	for i, l := range labels {
+		out[i] = store.StoreLabel{
+			ID:          l.ID,
+			Name:        l.Name,
+			Color:       l.Color,
+			TextColor:   l.TextColor,
+			Description: l.Description,
+			GroupID:     groupID,
+			ProjectID:   projectID,
+		}
+	}
+	return out
+}
+
+// accessLevels names GitLab's access levels.
+var accessLevels = map[gitlab.AccessLevelValue]string{
+	gitlab.MinimalAccessPermissions: "minimal",
+	gitlab.GuestPermissions:         "guest",
+	gitlab.PlannerPermissions:       "planner",
+	gitlab.ReporterPermissions:      "reporter",
+	gitlab.DeveloperPermissions:     "developer",
+	gitlab.MaintainerPermissions:    "maintainer",
+	gitlab.OwnerPermissions:         "owner",
+}
+
+// ConvertMembers maps API project members for rendering.
+func ConvertMembers(members []*gitlab.ProjectMember) []render.Member {
+	out := make([]render.Member, len(members))
+	for i, m := range members {
+		level, ok := accessLevels[m.AccessLevel]
+		if !ok {
+			level = strconv.Itoa(int(m.AccessLevel))
+		}
+		out[i] = render.Member{ID: m.ID, Username: m.Username, Name: m.Name, State: m.State, AccessLevel: level}
+	}
+	return out
+}
+
+// ConvertPipeline maps an API pipeline to its store representation.
+func ConvertPipeline(p *gitlab.Pipeline) store.StorePipeline {
+	sp := store.StorePipeline{
+		ID:         p.ID,
+		IID:        p.IID,
+		ProjectID:  p.ProjectID,
+		Status:     p.Status,
+		Source:     string(p.Source),
+		Ref:        p.Ref,
+		SHA:        p.SHA,
+		WebURL:     p.WebURL,
+		Duration:   p.Duration,
+		CreatedAt:  ptrTime(p.CreatedAt),
+		UpdatedAt:  ptrTime(p.UpdatedAt),
+		StartedAt:  ptrTime(p.StartedAt),
+		FinishedAt: ptrTime(p.FinishedAt),
+	}
+	if p.User != nil {
+		sp.AuthorUsername = p.User.Username
+		sp.AuthorName = p.User.Name
+	}
+	return sp
+}
+
+// ConvertJobs maps API jobs to their store representation.
+func ConvertJobs(jobs []*gitlab.Job) []store.StoreJob {
+	out := make([]store.StoreJob, len(jobs))
+	for i, j := range jobs {
+		out[i] = store.StoreJob{
+			ID:            j.ID,
+			ProjectID:     j.Pipeline.ProjectID,
+			PipelineID:    j.Pipeline.ID,
+			Name:          j.Name,
+			Stage:         j.Stage,
+			Status:        j.Status,
+			Ref:           j.Ref,
+			AllowFailure:  j.AllowFailure,
+			FailureReason: j.FailureReason,
+			Duration:      int64(j.Duration),
+			WebURL:        j.WebURL,
+			CreatedAt:     ptrTime(j.CreatedAt),
+			StartedAt:     ptrTime(j.StartedAt),
+			FinishedAt:    ptrTime(j.FinishedAt),
+		}
+		if out[i].ProjectID == 0 && j.Project != nil {
+			out[i].ProjectID = j.Project.ID
+		}
+	}
+	return out
+}
+
+// ConvertTodos maps API to-do items to their store representation.
+func ConvertTodos(todos []*gitlab.Todo) []store.StoreTodo {
+	out := make([]store.StoreTodo, len(todos))
+	for i, t := range todos {
+		out[i] = store.StoreTodo{
+			ID:         t.ID,
+			ActionName: string(t.ActionName),
+			TargetType: string(t.TargetType),
+			TargetURL:  t.TargetURL,
+			Body:       t.Body,
+			State:      t.State,
+			CreatedAt:  ptrTime(t.CreatedAt),
+		}
+		if t.Project != nil {
+			out[i].ProjectID = t.Project.ID
+			out[i].ProjectPath = t.Project.PathWithNamespace
+		}
+		if t.Author != nil {
+			out[i].AuthorUsername = t.Author.Username
+		}
+		if t.Target != nil {
+			out[i].TargetIID = t.Target.IID
+			out[i].TargetTitle = t.Target.Title
+			out[i].TargetState = t.Target.State
+		}
+	}
+	return out
+}
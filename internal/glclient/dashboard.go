@@ -0,0 +1,155 @@
+package glclient
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/chazzychouse/g2o/internal/render"
+	"github.com/chazzychouse/g2o/internal/store"
+)
+
+// dashboardView is the last_seen key of the dashboard.
+const dashboardView = "dashboard"
+
+// RunDashboard summarises the current user's work from the store: issues,
+// merge requests and pending to-do items. Looking at it in plain output
+// records the time, so the next look can show what was updated in between;
+// scripts reading other formats leave it alone.
+func (g GitLab) RunDashboard() error {
+	if g.store == nil {
+		return fmt.Errorf("dashboard needs the local store")
+	}
+	user, err := g.store.GetCurrentUser()
+	if errors.Is(err, store.ErrRecordNotFound) {
+		return fmt.Errorf("no current user in the local store; run 'sync'")
+	}
+	if err != nil {
+		return err
+	}
+	lastSeen, err := g.store.GetLastSeen(dashboardView)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	d, err := g.dashboard(user, lastSeen, now)
+	if err != nil {
+		return err
+	}
+	if err := render.Dashboard(g.out, g.format, d); err != nil {
+		return err
+	}
+	if g.format == render.Plain {
+		return g.store.SetLastSeen(dashboardView, now)
+	}
+	return nil
+}
+
+func (g GitLab) dashboard(user store.StoreUser, lastSeen, now time.Time) (render.DashboardSummary, error) {
+	d := render.DashboardSummary{User: user, LastSeen: lastSeen, Today: now.Format(time.DateOnly)}
+	q, err := store.ParseIssueQuery([]string{"assignee:@me"})
+	if err != nil {
+		return d, err
+	}
+	issues, err := g.store.QueryIssues(q)
+	if err != nil {
+		return d, err
+	}
+	mrs, err := g.store.ListMergeRequests("opened")
+	if err != nil {
+		return d, err
+	}
+
+	weekOut := now.AddDate(0, 0, 7).Format(time.DateOnly)
+	projects := make(map[int64]*render.ProjectCounts)
+	project := func(id int64) *render.ProjectCounts {
+		if projects[id] == nil {
+			projects[id] = &render.ProjectCounts{ID: id}
+		}
+		return projects[id]
+	}
+	for _, i := range issues {
+		if !lastSeen.IsZero() && i.UpdatedAt.After(lastSeen) {
+			d.Updated = append(d.Updated, i)
+		}
+		if i.State != "opened" {
+			d.Closed++
+			continue
+		}
+		d.Open++
+		project(i.ProjectID).OpenIssues++
+		// Due dates are calendar days, so they compare as strings.
+		switch {
+		case i.DueDate == "":
+			d.NoDueDate++
+		case i.DueDate < d.Today:
+			d.Overdue = append(d.Overdue, i)
+		case i.DueDate <= weekOut:
+			d.DueSoon = append(d.DueSoon, i)
+		default:
+			d.DueLater++
+		}
+	}
+	byDue := func(a, b store.StoreIssue) int { return cmp.Compare(a.DueDate, b.DueDate) }
+	slices.SortStableFunc(d.Overdue, byDue)
+	slices.SortStableFunc(d.DueSoon, byDue)
+
+	for _, m := range mrs {
+		if !lastSeen.IsZero() && m.UpdatedAt.After(lastSeen) {
+			d.UpdatedMergeRequests = append(d.UpdatedMergeRequests, m)
+		}
+		if m.AuthorUsername == user.Username {
+			d.MergeRequests.Authored++
+		}
+		if hasUser(m.Assignees, user.Username) {
+			d.MergeRequests.Assigned++
+		}
+		if hasUser(m.Reviewers, user.Username) {
+			d.MergeRequests.Reviewing++
+		}
+		project(m.ProjectID).MergeRequests++
+	}
+
+	todos, err := g.store.ListTodos("pending")
+	if err != nil {
+		return d, err
+	}
+	actions := make(map[string]int64)
+	for _, t := range todos {
+		actions[t.ActionName]++
+	}
+	for action, n := range actions {
+		d.Todos = append(d.Todos, render.TodoCount{Action: action, Count: n})
+	}
+	slices.SortFunc(d.Todos, func(a, b render.TodoCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Action, b.Action))
+	})
+
+	paths, err := g.store.ListProjectPaths()
+	if err != nil {
+		return d, err
+	}
+	for _, p := range paths {
+		if c := projects[p.ID]; c != nil {
+			c.Path = p.Path
+		}
+	}
+	for _, c := range projects {
+		if c.Path == "" {
+			c.Path = fmt.Sprint(c.ID)
+		}
+		d.Projects = append(d.Projects, *c)
+	}
+	slices.SortFunc(d.Projects, func(a, b render.ProjectCounts) int {
+		return cmp.Or(
+			cmp.Compare(b.OpenIssues+b.MergeRequests, a.OpenIssues+a.MergeRequests),
+			cmp.Compare(a.Path, b.Path))
+	})
+	return d, nil
+}
+
+func hasUser(users []store.StoreAssignee, username string) bool {
+	return slices.ContainsFunc(users, func(u store.StoreAssignee) bool { return u.Username == username })
+}
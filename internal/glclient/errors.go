@@ -1,14 +1,229 @@
 package glclient
 
-import "fmt"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
 
 var (
-	ErrTokenRequired         = fmt.Errorf("token is required")
-	ErrClientCreationFailed  = fmt.Errorf("failed to create client")
-	ErrGetGroupFailed        = fmt.Errorf("failed to get group")
-	ErrListGroupsFailed      = fmt.Errorf("failed to list groups")
-	ErrCurrentUserFailed     = fmt.Errorf("failed to get current user")
-	ErrListProjectsFailed    = fmt.Errorf("failed to list projects")
-	ErrListIssuesFailed      = fmt.Errorf("failed to list issues")
-	ErrListGroupIssuesFailed = fmt.Errorf("failed to list group issues")
+	ErrTokenRequired           = fmt.Errorf("token is required")
+	ErrClientCreationFailed    = fmt.Errorf("failed to create client")
+	ErrInvalidCABundle         = fmt.Errorf("invalid CA bundle")
+	ErrGetGroupFailed          = fmt.Errorf("failed to get group")
+	ErrListGroupsFailed        = fmt.Errorf("failed to list groups")
+	ErrCurrentUserFailed       = fmt.Errorf("failed to get current user")
+	ErrListProjectsFailed      = fmt.Errorf("failed to list projects")
+	ErrGetProjectFailed        = fmt.Errorf("failed to get project")
+	ErrListMilestonesFailed    = fmt.Errorf("failed to list milestones")
+	ErrListIterationsFailed    = fmt.Errorf("failed to list iterations")
+	ErrListLabelsFailed        = fmt.Errorf("failed to list labels")
+	ErrListMembersFailed       = fmt.Errorf("failed to list members")
+	ErrListIssuesFailed        = fmt.Errorf("failed to list issues")
+	ErrListGroupIssuesFailed   = fmt.Errorf("failed to list group issues")
+	ErrListMergeRequestsFailed = fmt.Errorf("failed to list merge requests")
+	ErrGetMergeRequestFailed   = fmt.Errorf("failed to get merge request")
+	ErrGetIssueFailed          = fmt.Errorf("failed to get issue")
+	ErrListIssueNotesFailed    = fmt.Errorf("failed to list issue notes")
+	ErrListIssueLinksFailed    = fmt.Errorf("failed to list linked issues")
+	ErrCreateIssueFailed       = fmt.Errorf("failed to create issue")
+	ErrUpdateIssueFailed       = fmt.Errorf("failed to update issue")
+	ErrUserNotFound            = fmt.Errorf("user not found")
+	ErrMilestoneNotFound       = fmt.Errorf("milestone not found")
+	ErrCreateNoteFailed        = fmt.Errorf("failed to add comment")
+	ErrListPipelinesFailed     = fmt.Errorf("failed to list pipelines")
+	ErrGetPipelineFailed       = fmt.Errorf("failed to get pipeline")
+	ErrRetryPipelineFailed     = fmt.Errorf("failed to retry pipeline")
+	ErrCancelPipelineFailed    = fmt.Errorf("failed to cancel pipeline")
+	ErrListJobsFailed          = fmt.Errorf("failed to list jobs")
+	ErrGetJobFailed            = fmt.Errorf("failed to get job")
+	ErrGetJobLogFailed         = fmt.Errorf("failed to get job log")
+	ErrListTodosFailed         = fmt.Errorf("failed to list to-do items")
+	ErrMarkTodoDoneFailed      = fmt.Errorf("failed to mark to-do item done")
+	ErrOffline                 = fmt.Errorf("GitLab is unreachable")
+	ErrUnauthorized            = fmt.Errorf("token was rejected; run 'g2o auth login'")
+	ErrNotFound                = fmt.Errorf("not found")
+	ErrTokenInfoFailed         = fmt.Errorf("failed to get token details")
 )
+
+// APIError is a failed GitLab request. errors.Is matches it against the
+// sentinel of the operation (Op), against its cause, against
+// ErrUnauthorized and ErrNotFound by status, and against ErrOffline when
+// GitLab could not be reached or kept failing.
+type APIError struct {
+	Op         error  // what was attempted, e.g. ErrListIssuesFailed
+	StatusCode int    // HTTP status; 0 if no response arrived
+	Method     string // e.g. GET
+	Endpoint   string // request path, e.g. /api/v4/groups/42
+	RequestID  string // GitLab's X-Request-Id, for support requests
+	Message    string // GitLab's explanation, if it gave one
+	Err        error  // underlying cause
+}
+
+// apiError wraps the error of a client call made for op. resp is the
+// response the call returned, which may be nil.
+func apiError(op error, resp *gitlab.Response, err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	e := &APIError{Op: op, Err: err}
+
+	var hr *http.Response
+	if resp != nil {
+		hr = resp.Response
+	}
+	var er *gitlab.ErrorResponse
+	if errors.As(err, &er) {
+		hr = er.Response
+		e.Message = errorMessage(er.Body)
+	}
+	var te *TransientError
+	switch {
+	case errors.As(err, &te):
+		e.StatusCode, e.Method, e.Endpoint = te.StatusCode, te.Method, te.URL
+		if u, perr := url.Parse(te.URL); perr == nil {
+			e.Endpoint = u.EscapedPath()
+		}
+		e.Err = fmt.Errorf("%w: %w", ErrOffline, err)
+	case hr != nil:
+		e.StatusCode = hr.StatusCode
+		e.RequestID = hr.Header.Get("X-Request-Id")
+		if hr.Request != nil {
+			e.Method, e.Endpoint = hr.Request.Method, hr.Request.URL.EscapedPath()
+		}
+	default:
+		e.Err = fmt.Errorf("%w: %w", ErrOffline, err)
+	}
+	return e
+}
+
+func (e *APIError) Error() string {
+	var msg string
+	switch s := e.StatusCode; {
+	case s == 0:
+		return e.Op.Error() + ": " + e.Err.Error()
+	case e.Message != "" && (s == http.StatusBadRequest || s == http.StatusConflict || s == http.StatusUnprocessableEntity):
+		msg = e.Message
+	case s == http.StatusUnauthorized:
+		msg = "token expired or was revoked; run 'g2o auth login'"
+	case s == http.StatusForbidden:
+		msg = "permission denied"
+		if e.Message != "" {
+			msg += ": " + e.Message
+		}
+	case s == http.StatusNotFound:
+		msg = e.resource() + " not found"
+	case s == http.StatusTooManyRequests:
+		msg = "rate limit exceeded; try again later"
+	case s >= 500:
+		msg = "GitLab error (" + http.StatusText(s) + "); try again later"
+	default:
+		msg = http.StatusText(s)
+		if e.Message != "" {
+			msg = e.Message
+		}
+	}
+	out := fmt.Sprintf("%d: %s", e.StatusCode, msg)
+	if e.RequestID != "" && e.StatusCode >= 500 {
+		out += " (request ID " + e.RequestID + ")"
+	}
+	return out
+}
+
+func (e *APIError) Unwrap() []error { return []error{e.Op, e.Err} }
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	}
+	return false
+}
+
+// resourceNames turns API collections into what a 404 message calls them.
+var resourceNames = map[string]string{
+	"groups":         "group",
+	"projects":       "project",
+	"issues":         "issue",
+	"merge_requests": "merge request",
+	"users":          "user",
+	"milestones":     "milestone",
+	"notes":          "note",
+	"labels":         "label",
+	"pipelines":      "pipeline",
+	"jobs":           "job",
+	"todos":          "to-do item",
+	"iterations":     "iteration",
+}
+
+// resource names what a request was about from its path, e.g. "group 42"
+// for /api/v4/groups/42/issues or "issue foo/bar#7" for
+// /api/v4/projects/foo%2Fbar/issues/7.
+func (e *APIError) resource() string {
+	path := strings.TrimPrefix(e.Endpoint, "/")
+	if _, rest, ok := strings.Cut(path, "api/v4/"); ok {
+		path = rest
+	}
+	segs := strings.Split(path, "/")
+	for i := range segs {
+		if v, err := url.PathUnescape(segs[i]); err == nil {
+			segs[i] = v
+		}
+	}
+
+	var project, kind, id string
+	for i := 0; i+1 < len(segs); i += 2 {
+		if segs[i] == "projects" {
+			project = segs[i+1]
+		}
+		kind, id = segs[i], segs[i+1]
+	}
+	name, ok := resourceNames[kind]
+	switch {
+	case !ok || id == "":
+		return "resource"
+	case project != "" && kind == "issues":
+		return "issue " + project + "#" + id
+	case project != "" && kind == "merge_requests":
+		return "merge request " + project + "!" + id
+	}
+	return name + " " + id
+}
+
+// errorMessage extracts GitLab's message from an error body, which is
+// {"message": "..."}, {"error": "..."} or a message per field.
+func errorMessage(body []byte) string {
+	var v struct {
+		Message json.RawMessage `json:"message"`
+		Error   string          `json:"error"`
+	}
+	if json.Unmarshal(body, &v) != nil {
+		return ""
+	}
+	var s string
+	if json.Unmarshal(v.Message, &s) == nil && s != "" {
+		return s
+	}
+	var fields map[string][]string
+	if json.Unmarshal(v.Message, &fields) == nil && len(fields) > 0 {
+		var parts []string
+		for _, field := range slices.Sorted(maps.Keys(fields)) {
+			parts = append(parts, field+" "+strings.Join(fields[field], ", "))
+		}
+		return strings.Join(parts, "; ")
+	}
+	return v.Error
+}
@@ -0,0 +1,26 @@
+package glclient
+
+import "testing"
+
+func TestErrorMessage(t *testing.T) {
+	tests := []struct {
+		body string
+		want string
+	}{
+		{`{"message": "404 Project Not Found"}`, "404 Project Not Found"},
+		{`{"error": "invalid_token"}`, "invalid_token"},
+		{`{"message": {"title": ["is too long"], "due_date": ["is invalid", "is in the past"], "labels": ["is invalid"]}}`,
+			"due_date is invalid, is in the past; labels is invalid; title is too long"},
+		{`not json`, ""},
+		{`{}`, ""},
+	}
+	for _, tt := range tests {
+		// Map order varies from run to run, so check a few times.
+		for range 5 {
+			if got := errorMessage([]byte(tt.body)); got != tt.want {
+				t.Errorf("errorMessage(%s) = %q, want %q", tt.body, got, tt.want)
+				break
+			}
+		}
+	}
+}
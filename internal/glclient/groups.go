@@ -26,17 +26,17 @@ func (g Group) String() string {
 }
 
 func (g GitLab) MyGroups() ([]*gitlab.Group, error) {
-	groups, _, err := g.client.Groups.ListGroups(&gitlab.ListGroupsOptions{})
+	groups, resp, err := g.client.Groups.ListGroups(&gitlab.ListGroupsOptions{})
 	if err != nil {
-		return nil, ErrListGroupsFailed
+		return nil, apiError(ErrListGroupsFailed, resp, err)
 	}
 	return groups, nil
 }
 
 func (g GitLab) GetGroup(id any) (*gitlab.Group, error) {
-	group, _, err := g.client.Groups.GetGroup(id, nil)
+	group, resp, err := g.client.Groups.GetGroup(id, nil)
 	if err != nil {
-		return nil, ErrGetGroupFailed
+		return nil, apiError(ErrGetGroupFailed, resp, err)
 	}
 	return group, nil
 }
@@ -59,9 +59,9 @@ func (g GitLab) GetGroupsIssues(ctx context.Context, id any) (<-chan *gitlab.Iss
 				State: gitlab.Ptr(groupIssuesState),
 			}
 
-			issues, _, err := g.client.Issues.ListGroupIssues(id, opts)
+			issues, resp, err := g.client.Issues.ListGroupIssues(id, opts, gitlab.WithContext(ctx))
 			if err != nil {
-				errc <- ErrListGroupIssuesFailed
+				errc <- apiError(ErrListGroupIssuesFailed, resp, err)
 				return
 			}
 
@@ -95,29 +95,24 @@ const perPage = 100
 
 // AllGroups fetches all groups with pagination.
 func (g GitLab) AllGroups(ctx context.Context) ([]*gitlab.Group, error) {
-	var all []*gitlab.Group
-	page := int64(1)
-	for {
-		groups, resp, err := g.client.Groups.ListGroups(&gitlab.ListGroupsOptions{
-			ListOptions: gitlab.ListOptions{PerPage: perPage, Page: page},
-		})
-		if err != nil {
-			return nil, ErrListGroupsFailed
-		}
-		all = append(all, groups...)
-		if resp.NextPage == 0 {
-			break
-		}
-		page = resp.NextPage
-	}
-	return all, nil
+	return allPages(ctx, g, ErrListGroupsFailed, g.groupsPage)
+}
+
+// GroupPages hands the user's groups to fn a page at a time, starting at
+// page from.
+func (g GitLab) GroupPages(ctx context.Context, from int64, fn PageHandler[*gitlab.Group]) error {
+	return eachPage(ctx, g, ErrListGroupsFailed, from, g.groupsPage, fn)
+}
+
+func (g GitLab) groupsPage(page int64, ro ...gitlab.RequestOptionFunc) ([]*gitlab.Group, *gitlab.Response, error) {
+	return g.client.Groups.ListGroups(&gitlab.ListGroupsOptions{
+		ListOptions: gitlab.ListOptions{PerPage: perPage, Page: page},
+	}, ro...)
 }
 
 // AllGroupIssues fetches all issues for a group with optional UpdatedAfter filter.
 func (g GitLab) AllGroupIssues(ctx context.Context, id any, updatedAfter *time.Time) ([]*gitlab.Issue, error) {
-	var all []*gitlab.Issue
-	page := int64(1)
-	for {
+	return allPages(ctx, g, ErrListGroupIssuesFailed, func(page int64, ro ...gitlab.RequestOptionFunc) ([]*gitlab.Issue, *gitlab.Response, error) {
 		opts := &gitlab.ListGroupIssuesOptions{
 			ListOptions: gitlab.ListOptions{PerPage: perPage, Page: page},
 			State:       gitlab.Ptr(groupIssuesState),
@@ -125,23 +120,42 @@ func (g GitLab) AllGroupIssues(ctx context.Context, id any, updatedAfter *time.T
 		if updatedAfter != nil {
 			opts.UpdatedAfter = updatedAfter
 		}
-		issues, resp, err := g.client.Issues.ListGroupIssues(id, opts)
-		if err != nil {
-			return nil, ErrListGroupIssuesFailed
-		}
-		all = append(all, issues...)
-		if resp.NextPage == 0 {
-			break
-		}
-		page = resp.NextPage
-	}
-	return all, nil
+		return g.client.Issues.ListGroupIssues(id, opts, ro...)
+	})
+}
+
+// AllGroupMilestones fetches the milestones of a group, open and closed.
+func (g GitLab) AllGroupMilestones(ctx context.Context, gid any) ([]*gitlab.GroupMilestone, error) {
+	return allPages(ctx, g, ErrListMilestonesFailed, func(page int64, ro ...gitlab.RequestOptionFunc) ([]*gitlab.GroupMilestone, *gitlab.Response, error) {
+		return g.client.GroupMilestones.ListGroupMilestones(gid, &gitlab.ListGroupMilestonesOptions{
+			ListOptions: gitlab.ListOptions{PerPage: perPage, Page: page},
+		}, ro...)
+	})
+}
+
+// AllGroupIterations fetches the iterations of a group. Iterations need
+// GitLab Premium; without it GitLab answers 403 or 404.
+func (g GitLab) AllGroupIterations(ctx context.Context, gid any) ([]*gitlab.GroupIteration, error) {
+	return allPages(ctx, g, ErrListIterationsFailed, func(page int64, ro ...gitlab.RequestOptionFunc) ([]*gitlab.GroupIteration, *gitlab.Response, error) {
+		return g.client.GroupIterations.ListGroupIterations(gid, &gitlab.ListGroupIterationsOptions{
+			ListOptions: gitlab.ListOptions{PerPage: perPage, Page: page},
+		}, ro...)
+	})
 }
 
-func listGroups(groups []*gitlab.Group) {
-	fmt.Println(styles.Title.Render(fmt.Sprintf("Groups: %d", len(groups))))
-	for _, g := range groups {
-		fmt.Println(Group{g})
-		fmt.Println()
+// AllGroupLabels fetches the labels a group defines itself, without those
+// of its ancestors and subgroups.
+func (g GitLab) AllGroupLabels(ctx context.Context, gid any) ([]*gitlab.Label, error) {
+	labels, err := allPages(ctx, g, ErrListLabelsFailed, func(page int64, ro ...gitlab.RequestOptionFunc) ([]*gitlab.GroupLabel, *gitlab.Response, error) {
+		return g.client.GroupLabels.ListGroupLabels(gid, &gitlab.ListGroupLabelsOptions{
+			ListOptions:           gitlab.ListOptions{PerPage: perPage, Page: page},
+			OnlyGroupLabels:       gitlab.Ptr(true),
+			IncludeAncestorGroups: gitlab.Ptr(false),
+		}, ro...)
+	})
+	out := make([]*gitlab.Label, len(labels))
+	for i, l := range labels {
+		out[i] = (*gitlab.Label)(l)
 	}
+	return out, err
 }
@@ -0,0 +1,214 @@
+package glclient
+
+import (
+	"fmt"
+	"strings"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// IssueFields are the issue attributes set by CreateIssue and EditIssue.
+// Nil fields are left unchanged; an empty Milestone or DueDate clears it.
+type IssueFields struct {
+	Title       *string  `json:"title,omitempty"`
+	Description *string  `json:"description,omitempty"`
+	Labels      []string `json:"labels,omitempty"`    // names
+	Assignees   []string `json:"assignees,omitempty"` // usernames; "@me" is the current user
+	Milestone   *string  `json:"milestone,omitempty"` // title
+	DueDate     *string  `json:"due_date,omitempty"`  // YYYY-MM-DD
+}
+
+// CreateIssue opens a new issue in project pid.
+func (g GitLab) CreateIssue(pid any, f IssueFields) (*gitlab.Issue, error) {
+	if f.Title == nil || strings.TrimSpace(*f.Title) == "" {
+		return nil, fmt.Errorf("an issue needs a title")
+	}
+	opt := &gitlab.CreateIssueOptions{
+		Title:       f.Title,
+		Description: f.Description,
+	}
+	if len(f.Labels) > 0 {
+		opt.Labels = (*gitlab.LabelOptions)(&f.Labels)
+	}
+	if len(f.Assignees) > 0 {
+		ids, err := g.userIDs(f.Assignees)
+		if err != nil {
+			return nil, err
+		}
+		opt.AssigneeIDs = &ids
+	}
+	if f.Milestone != nil && *f.Milestone != "" {
+		id, err := g.milestoneID(pid, *f.Milestone)
+		if err != nil {
+			return nil, err
+		}
+		opt.MilestoneID = &id
+	}
+	if f.DueDate != nil && *f.DueDate != "" {
+		due, err := parseDueDate(*f.DueDate)
+		if err != nil {
+			return nil, err
+		}
+		opt.DueDate = &due
+	}
+
+	issue, resp, err := g.client.Issues.CreateIssue(pid, opt)
+	if err != nil {
+		g.log.Debug("create issue", "error", err)
+		return nil, apiError(ErrCreateIssueFailed, resp, err)
+	}
+	return issue, nil
+}
+
+// EditIssue changes the title, description, milestone, due date and
+// assignees of an issue. Labels are changed with LabelIssue.
+func (g GitLab) EditIssue(pid any, iid int64, f IssueFields) (*gitlab.Issue, error) {
+	opt := &gitlab.UpdateIssueOptions{
+		Title:       f.Title,
+		Description: f.Description,
+	}
+	if f.Assignees != nil {
+		ids, err := g.userIDs(f.Assignees)
+		if err != nil {
+			return nil, err
+		}
+		opt.AssigneeIDs = &ids
+	}
+	if f.Milestone != nil {
+		if *f.Milestone == "" {
+			opt.ResetMilestoneID = true
+		} else {
+			id, err := g.milestoneID(pid, *f.Milestone)
+			if err != nil {
+				return nil, err
+			}
+			opt.MilestoneID = &id
+		}
+	}
+	if f.DueDate != nil {
+		if *f.DueDate == "" {
+			opt.ResetDueDate = true
+		} else {
+			due, err := parseDueDate(*f.DueDate)
+			if err != nil {
+				return nil, err
+			}
+			opt.DueDate = &due
+		}
+	}
+	return g.updateIssue(pid, iid, opt)
+}
+
+// CloseIssue closes an issue.
+func (g GitLab) CloseIssue(pid any, iid int64) (*gitlab.Issue, error) {
+	return g.updateIssue(pid, iid, &gitlab.UpdateIssueOptions{StateEvent: gitlab.Ptr("close")})
+}
+
+// ReopenIssue reopens a closed issue.
+func (g GitLab) ReopenIssue(pid any, iid int64) (*gitlab.Issue, error) {
+	return g.updateIssue(pid, iid, &gitlab.UpdateIssueOptions{StateEvent: gitlab.Ptr("reopen")})
+}
+
+// AssignIssue replaces the assignees of an issue. No usernames unassigns
+// everyone.
+func (g GitLab) AssignIssue(pid any, iid int64, usernames []string) (*gitlab.Issue, error) {
+	ids, err := g.userIDs(usernames)
+	if err != nil {
+		return nil, err
+	}
+	return g.updateIssue(pid, iid, &gitlab.UpdateIssueOptions{AssigneeIDs: &ids})
+}
+
+// LabelIssue adds and removes labels, leaving the others in place.
+func (g GitLab) LabelIssue(pid any, iid int64, add, remove []string) (*gitlab.Issue, error) {
+	opt := &gitlab.UpdateIssueOptions{}
+	if len(add) > 0 {
+		opt.AddLabels = (*gitlab.LabelOptions)(&add)
+	}
+	if len(remove) > 0 {
+		opt.RemoveLabels = (*gitlab.LabelOptions)(&remove)
+	}
+	return g.updateIssue(pid, iid, opt)
+}
+
+// CommentIssue adds a comment to an issue and returns the updated issue.
+// Once the comment is posted, failing to fetch the issue is not reported as
+// ErrOffline, so the comment is never queued to be posted again.
+func (g GitLab) CommentIssue(pid any, iid int64, body string) (*gitlab.Issue, error) {
+	if err := g.createNote(pid, iid, body); err != nil {
+		return nil, err
+	}
+	issue, resp, err := g.client.Issues.GetIssue(pid, iid)
+	if err != nil {
+		return nil, fmt.Errorf("comment posted, but the issue could not be fetched again: %v", apiError(ErrGetIssueFailed, resp, err))
+	}
+	return issue, nil
+}
+
+func (g GitLab) createNote(pid any, iid int64, body string) error {
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("empty comment")
+	}
+	if _, resp, err := g.client.Notes.CreateIssueNote(pid, iid, &gitlab.CreateIssueNoteOptions{Body: gitlab.Ptr(body)}); err != nil {
+		g.log.Debug("create issue note", "iid", iid, "error", err)
+		return apiError(ErrCreateNoteFailed, resp, err)
+	}
+	return nil
+}
+
+func (g GitLab) updateIssue(pid any, iid int64, opt *gitlab.UpdateIssueOptions) (*gitlab.Issue, error) {
+	issue, resp, err := g.client.Issues.UpdateIssue(pid, iid, opt)
+	if err != nil {
+		g.log.Debug("update issue", "iid", iid, "error", err)
+		return nil, apiError(ErrUpdateIssueFailed, resp, err)
+	}
+	return issue, nil
+}
+
+// userIDs resolves usernames, with or without a leading "@", to user IDs.
+func (g GitLab) userIDs(usernames []string) ([]int64, error) {
+	ids := make([]int64, 0, len(usernames))
+	for _, name := range usernames {
+		name = strings.TrimPrefix(name, "@")
+		if name == "me" {
+			u, resp, err := g.client.Users.CurrentUser()
+			if err != nil {
+				return nil, apiError(ErrCurrentUserFailed, resp, err)
+			}
+			ids = append(ids, u.ID)
+			continue
+		}
+		users, resp, err := g.client.Users.ListUsers(&gitlab.ListUsersOptions{Username: gitlab.Ptr(name)})
+		if err != nil {
+			return nil, apiError(fmt.Errorf("%w: %s", ErrUserNotFound, name), resp, err)
+		}
+		if len(users) == 0 {
+			return nil, fmt.Errorf("%w: %s", ErrUserNotFound, name)
+		}
+		ids = append(ids, users[0].ID)
+	}
+	return ids, nil
+}
+
+// milestoneID looks up a milestone of the project or its groups by title.
+func (g GitLab) milestoneID(pid any, title string) (int64, error) {
+	milestones, resp, err := g.client.Milestones.ListMilestones(pid, &gitlab.ListMilestonesOptions{
+		Title:            gitlab.Ptr(title),
+		IncludeAncestors: gitlab.Ptr(true),
+	})
+	if err != nil {
+		return 0, apiError(fmt.Errorf("%w: %s", ErrMilestoneNotFound, title), resp, err)
+	}
+	if len(milestones) == 0 {
+		return 0, fmt.Errorf("%w: %s", ErrMilestoneNotFound, title)
+	}
+	return milestones[0].ID, nil
+}
+
+func parseDueDate(s string) (gitlab.ISOTime, error) {
+	due, err := gitlab.ParseISOTime(s)
+	if err != nil {
+		return due, fmt.Errorf("invalid due date %q (want YYYY-MM-DD)", s)
+	}
+	return due, nil
+}
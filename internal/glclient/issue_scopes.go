@@ -0,0 +1,72 @@
+package glclient
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kinds of IssueScope.
+const (
+	ScopeAssigned = "assigned" // issues assigned to the user
+	ScopeCreated  = "created"  // issues the user opened
+	ScopeProject  = "project"  // every issue of a project
+	ScopeGroup    = "group"    // every issue of a group and its subgroups
+)
+
+// IssueScope is one set of issues a sync fetches. It is written
+// "assigned", "created", "project:<path>" or "group:<path>", where the path
+// may also be a numeric ID.
+type IssueScope struct {
+	Kind string
+	Path string // project or group, for those kinds
+}
+
+// DefaultIssueScopes is what a profile syncs unless it lists issue_scopes.
+var DefaultIssueScopes = []IssueScope{{Kind: ScopeAssigned}}
+
+func (sc IssueScope) String() string {
+	if sc.Path == "" {
+		return sc.Kind
+	}
+	return sc.Kind + ":" + sc.Path
+}
+
+// ParseIssueScope parses the written form of a scope.
+func ParseIssueScope(s string) (IssueScope, error) {
+	kind, path, _ := strings.Cut(strings.TrimSpace(s), ":")
+	sc := IssueScope{Kind: kind, Path: strings.Trim(strings.TrimSpace(path), "/")}
+	switch kind {
+	case ScopeAssigned, ScopeCreated:
+		if sc.Path != "" {
+			return sc, fmt.Errorf("issue scope %q takes no path", kind)
+		}
+	case ScopeProject, ScopeGroup:
+		if sc.Path == "" {
+			return sc, fmt.Errorf("issue scope %q needs a path, as in %s:group/name", kind, kind)
+		}
+	default:
+		return sc, fmt.Errorf("unknown issue scope %q (want assigned, created, project:<path> or group:<path>)", s)
+	}
+	return sc, nil
+}
+
+// ParseIssueScopes parses a profile's scopes, dropping repeats. None at all
+// means DefaultIssueScopes.
+func ParseIssueScopes(ss []string) ([]IssueScope, error) {
+	if len(ss) == 0 {
+		return DefaultIssueScopes, nil
+	}
+	var scopes []IssueScope
+	seen := make(map[IssueScope]bool)
+	for _, s := range ss {
+		sc, err := ParseIssueScope(s)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[sc] {
+			seen[sc] = true
+			scopes = append(scopes, sc)
+		}
+	}
+	return scopes, nil
+}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/chazzychouse/g2o/internal/store"
 	"github.com/chazzychouse/g2o/internal/styles"
 	gitlab "gitlab.com/gitlab-org/api/client-go"
 )
@@ -19,41 +20,92 @@ func (i Issue) String() string {
 }
 
 func (g GitLab) Issues() ([]*gitlab.Issue, error) {
-	issues, _, err := g.client.Issues.ListIssues(&gitlab.ListIssuesOptions{})
+	issues, resp, err := g.client.Issues.ListIssues(&gitlab.ListIssuesOptions{})
 	if err != nil {
-		return nil, ErrListIssuesFailed
+		return nil, apiError(ErrListIssuesFailed, resp, err)
 	}
 	return issues, nil
 }
 
-// AllIssues fetches all issues with pagination and optional UpdatedAfter filter.
-func (g GitLab) AllIssues(ctx context.Context, updatedAfter *time.Time) ([]*gitlab.Issue, error) {
-	var all []*gitlab.Issue
-	page := int64(1)
-	for {
-		opts := &gitlab.ListIssuesOptions{
-			ListOptions: gitlab.ListOptions{PerPage: perPage, Page: page},
-			Scope:       gitlab.Ptr("assigned_to_me"),
-		}
-		if updatedAfter != nil {
-			opts.UpdatedAfter = updatedAfter
-		}
-		issues, resp, err := g.client.Issues.ListIssues(opts)
-		if err != nil {
-			return nil, ErrListIssuesFailed
+// AllIssues fetches, with pagination, the issues in scope with optional
+// UpdatedAfter filter.
+func (g GitLab) AllIssues(ctx context.Context, scope IssueScope, updatedAfter *time.Time) ([]*gitlab.Issue, error) {
+	return allPages(ctx, g, ErrListIssuesFailed, g.issuesPage(scope, updatedAfter))
+}
+
+// IssuePages hands the issues AllIssues would return to fn a page at a
+// time, starting at page from.
+func (g GitLab) IssuePages(ctx context.Context, scope IssueScope, updatedAfter *time.Time, from int64, fn PageHandler[*gitlab.Issue]) error {
+	return eachPage(ctx, g, ErrListIssuesFailed, from, g.issuesPage(scope, updatedAfter), fn)
+}
+
+// issuesPage lists the issues of scope in any state.
+func (g GitLab) issuesPage(scope IssueScope, updatedAfter *time.Time) pageFetcher[*gitlab.Issue] {
+	return func(page int64, ro ...gitlab.RequestOptionFunc) ([]*gitlab.Issue, *gitlab.Response, error) {
+		list := gitlab.ListOptions{PerPage: perPage, Page: page}
+		switch scope.Kind {
+		case ScopeProject:
+			return g.client.Issues.ListProjectIssues(scope.Path, &gitlab.ListProjectIssuesOptions{
+				ListOptions:  list,
+				State:        gitlab.Ptr("all"),
+				UpdatedAfter: updatedAfter,
+			}, ro...)
+		case ScopeGroup:
+			return g.client.Issues.ListGroupIssues(scope.Path, &gitlab.ListGroupIssuesOptions{
+				ListOptions:  list,
+				State:        gitlab.Ptr("all"),
+				UpdatedAfter: updatedAfter,
+			}, ro...)
 		}
-		all = append(all, issues...)
-		if resp.NextPage == 0 {
-			break
+		userScope := "assigned_to_me"
+		if scope.Kind == ScopeCreated {
+			userScope = "created_by_me"
 		}
-		page = resp.NextPage
+		return g.client.Issues.ListIssues(&gitlab.ListIssuesOptions{
+			ListOptions:  list,
+			Scope:        gitlab.Ptr(userScope),
+			UpdatedAfter: updatedAfter,
+		}, ro...)
+	}
+}
+
+func (g GitLab) GetIssue(pid any, iid int64) (*gitlab.Issue, error) {
+	issue, resp, err := g.client.Issues.GetIssue(pid, iid)
+	if err != nil {
+		return nil, apiError(ErrGetIssueFailed, resp, err)
 	}
-	return all, nil
+	return issue, nil
 }
 
-func listIssues(issues []*gitlab.Issue) {
-	fmt.Println(styles.Title.Render(fmt.Sprintf("Issues: %d", len(issues))))
-	for _, i := range issues {
-		fmt.Println(Issue{i})
+// AllIssueNotes fetches every note on an issue, oldest first.
+func (g GitLab) AllIssueNotes(ctx context.Context, pid any, iid int64) ([]*gitlab.Note, error) {
+	return allPages(ctx, g, ErrListIssueNotesFailed, func(page int64, ro ...gitlab.RequestOptionFunc) ([]*gitlab.Note, *gitlab.Response, error) {
+		return g.client.Notes.ListIssueNotes(pid, iid, &gitlab.ListIssueNotesOptions{
+			ListOptions: gitlab.ListOptions{PerPage: perPage, Page: page},
+			OrderBy:     gitlab.Ptr("created_at"),
+			Sort:        gitlab.Ptr("asc"),
+		}, ro...)
+	})
+}
+
+func (g GitLab) IssueRelations(pid any, iid int64) ([]*gitlab.IssueRelation, error) {
+	relations, resp, err := g.client.IssueLinks.ListIssueRelations(pid, iid)
+	if err != nil {
+		return nil, apiError(ErrListIssueLinksFailed, resp, err)
+	}
+	return relations, nil
+}
+
+// IssueDiscussion fetches the notes and linked issues of the issue with the
+// given ID and IID, converted for the store.
+func (g GitLab) IssueDiscussion(ctx context.Context, pid any, issueID, iid int64) ([]store.StoreIssueNote, []store.StoreIssueLink, error) {
+	notes, err := g.AllIssueNotes(ctx, pid, iid)
+	if err != nil {
+		return nil, nil, err
+	}
+	relations, err := g.IssueRelations(pid, iid)
+	if err != nil {
+		return nil, nil, err
 	}
+	return ConvertIssueNotes(issueID, notes), ConvertIssueLinks(issueID, relations), nil
 }
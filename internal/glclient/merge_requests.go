@@ -0,0 +1,86 @@
+package glclient
+
+import (
+	"context"
+	"time"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// MergeRequestScopes are the global MR scopes that together cover every
+// merge request the current user authored, is assigned to or reviews.
+var MergeRequestScopes = []string{"created_by_me", "assigned_to_me", "reviews_for_me"}
+
+// MergeRequestDetail bundles a merge request with its approval state.
+type MergeRequestDetail struct {
+	*gitlab.MergeRequest
+	Approvals *gitlab.MergeRequestApprovals
+}
+
+func (g GitLab) MergeRequests() ([]*gitlab.BasicMergeRequest, error) {
+	mrs, resp, err := g.client.MergeRequests.ListMergeRequests(&gitlab.ListMergeRequestsOptions{
+		Scope: gitlab.Ptr("created_by_me"),
+		State: gitlab.Ptr("opened"),
+	})
+	if err != nil {
+		return nil, apiError(ErrListMergeRequestsFailed, resp, err)
+	}
+	return mrs, nil
+}
+
+// AllMergeRequests fetches, with pagination, every merge request the current
+// user authored, is assigned to or reviews, with optional UpdatedAfter filter.
+func (g GitLab) AllMergeRequests(ctx context.Context, updatedAfter *time.Time) ([]*gitlab.BasicMergeRequest, error) {
+	var all []*gitlab.BasicMergeRequest
+	seen := make(map[int64]bool)
+	for _, scope := range MergeRequestScopes {
+		mrs, err := allPages(ctx, g, ErrListMergeRequestsFailed, g.mergeRequestsPage(scope, updatedAfter))
+		if err != nil {
+			return nil, err
+		}
+		for _, mr := range mrs {
+			if !seen[mr.ID] {
+				seen[mr.ID] = true
+				all = append(all, mr)
+			}
+		}
+	}
+	return all, nil
+}
+
+// MergeRequestPages hands the merge requests of one of MergeRequestScopes
+// to fn a page at a time, starting at page from.
+func (g GitLab) MergeRequestPages(ctx context.Context, scope string, updatedAfter *time.Time, from int64, fn PageHandler[*gitlab.BasicMergeRequest]) error {
+	return eachPage(ctx, g, ErrListMergeRequestsFailed, from, g.mergeRequestsPage(scope, updatedAfter), fn)
+}
+
+func (g GitLab) mergeRequestsPage(scope string, updatedAfter *time.Time) pageFetcher[*gitlab.BasicMergeRequest] {
+	return func(page int64, ro ...gitlab.RequestOptionFunc) ([]*gitlab.BasicMergeRequest, *gitlab.Response, error) {
+		opts := &gitlab.ListMergeRequestsOptions{
+			ListOptions: gitlab.ListOptions{PerPage: perPage, Page: page},
+			Scope:       gitlab.Ptr(scope),
+			State:       gitlab.Ptr("all"),
+		}
+		if updatedAfter != nil {
+			opts.UpdatedAfter = updatedAfter
+		}
+		return g.client.MergeRequests.ListMergeRequests(opts, ro...)
+	}
+}
+
+// GetMergeRequest fetches one merge request, including its head pipeline,
+// together with its approval state.
+func (g GitLab) GetMergeRequest(pid any, iid int64) (MergeRequestDetail, error) {
+	mr, resp, err := g.client.MergeRequests.GetMergeRequest(pid, iid, nil)
+	if err != nil {
+		return MergeRequestDetail{}, apiError(ErrGetMergeRequestFailed, resp, err)
+	}
+	approvals, _, err := g.client.MergeRequestApprovals.GetConfiguration(pid, iid)
+	if err != nil {
+		// Approval details are unavailable on some tiers; the MR is still
+		// worth showing without them.
+		g.log.Debug("merge request approvals unavailable", "project", pid, "iid", iid, "error", err)
+		approvals = nil
+	}
+	return MergeRequestDetail{MergeRequest: mr, Approvals: approvals}, nil
+}
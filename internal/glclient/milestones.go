@@ -0,0 +1,159 @@
+package glclient
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chazzychouse/g2o/internal/render"
+	"github.com/chazzychouse/g2o/internal/store"
+)
+
+// RunMilestone shows the progress of a milestone from the stored issues:
+// open and closed counts, total and remaining weight and a burndown chart.
+// milestone is an ID or a title; a title no milestone has is looked up
+// among the iterations.
+func (g GitLab) RunMilestone(milestone string) error {
+	if g.store == nil {
+		return fmt.Errorf("milestone needs the local store")
+	}
+	r, err := g.milestoneReport(milestone)
+	if errors.Is(err, store.ErrRecordNotFound) {
+		return fmt.Errorf("no milestone or iteration %q in the local store; run 'sync milestones'", milestone)
+	}
+	if err != nil {
+		return err
+	}
+	return render.Milestone(g.out, g.format, r)
+}
+
+func (g GitLab) milestoneReport(milestone string) (render.MilestoneReport, error) {
+	var m store.StoreMilestone
+	var err error
+	if id, perr := strconv.ParseInt(milestone, 10, 64); perr == nil {
+		m, err = g.store.GetMilestone(id)
+		if errors.Is(err, store.ErrRecordNotFound) {
+			if it, err := g.store.GetIteration(id); err == nil {
+				return g.iterationReport(it)
+			}
+		}
+	} else {
+		var ms []store.StoreMilestone
+		ms, err = g.store.FindMilestones(milestone)
+		switch {
+		case err != nil:
+		case len(ms) == 0:
+			it, err := g.store.FindIteration(milestone)
+			if err != nil {
+				return render.MilestoneReport{}, err
+			}
+			return g.iterationReport(it)
+		case len(ms) > 1 && ms[0].State == ms[1].State:
+			ids := make([]string, len(ms))
+			for i, m := range ms {
+				ids[i] = strconv.FormatInt(m.ID, 10)
+			}
+			return render.MilestoneReport{}, fmt.Errorf("%d milestones are titled %q; pick one by ID: %s", len(ms), milestone, strings.Join(ids, ", "))
+		default:
+			m = ms[0]
+		}
+	}
+	if err != nil {
+		return render.MilestoneReport{}, err
+	}
+	issues, err := g.store.ListMilestoneIssues(m.ID)
+	if err != nil {
+		return render.MilestoneReport{}, err
+	}
+	r := render.MilestoneReport{Kind: "milestone", ID: m.ID, Title: m.Title, State: m.State,
+		StartDate: m.StartDate, DueDate: m.DueDate, WebURL: m.WebURL}
+	burndown(&r, issues, m.CreatedAt, time.Now().UTC())
+	return r, nil
+}
+
+func (g GitLab) iterationReport(it store.StoreIteration) (render.MilestoneReport, error) {
+	issues, err := g.store.ListIterationIssues(it.ID)
+	if err != nil {
+		return render.MilestoneReport{}, err
+	}
+	r := render.MilestoneReport{Kind: "iteration", ID: it.ID, Title: it.Title, State: it.State,
+		StartDate: it.StartDate, DueDate: it.DueDate, WebURL: it.WebURL}
+	burndown(&r, issues, it.CreatedAt, time.Now().UTC())
+	return r, nil
+}
+
+// burndown fills in r's counts and the work left at the end of each day
+// from its start date, or the first issue's creation, up to today or the
+// due date, whichever is earlier. Work is weight when any issue has one and
+// the number of issues otherwise; an issue is done from the day it closed.
+func burndown(r *render.MilestoneReport, issues []store.StoreIssue, created, now time.Time) {
+	r.Unit = "weight"
+	for _, issue := range issues {
+		r.TotalWeight += issue.Weight
+		if issue.State == "closed" {
+			r.Closed++
+		} else {
+			r.Open++
+			r.RemainingWeight += issue.Weight
+		}
+	}
+	work := func(issue store.StoreIssue) int64 { return issue.Weight }
+	if r.TotalWeight == 0 {
+		r.Unit = "issues"
+		work = func(store.StoreIssue) int64 { return 1 }
+	}
+
+	start := parseDay(r.StartDate)
+	if start.IsZero() {
+		for _, issue := range issues {
+			if c := issue.CreatedAt.UTC().Truncate(24 * time.Hour); start.IsZero() || c.Before(start) {
+				start = c
+			}
+		}
+	}
+	if start.IsZero() {
+		start = created.UTC().Truncate(24 * time.Hour)
+	}
+	today := now.UTC().Truncate(24 * time.Hour)
+	end := parseDay(r.DueDate)
+	if end.IsZero() || end.Before(start) {
+		end = today
+	}
+	if start.IsZero() || start.After(today) {
+		return
+	}
+	r.Days = int(end.Sub(start)/(24*time.Hour)) + 1
+
+	var total int64
+	done := map[time.Time]int64{} // work finished per day
+	for _, issue := range issues {
+		total += work(issue)
+		if issue.State != "closed" {
+			continue
+		}
+		day := issue.ClosedAt.UTC().Truncate(24 * time.Hour)
+		if issue.ClosedAt.IsZero() || day.Before(start) {
+			day = start
+		}
+		done[day] += work(issue)
+	}
+	last := end
+	if today.Before(last) {
+		last = today
+	}
+	remaining := total
+	for day := start; !day.After(last); day = day.AddDate(0, 0, 1) {
+		remaining -= done[day]
+		r.Burndown = append(r.Burndown, render.BurndownPoint{Date: day.Format(time.DateOnly), Remaining: remaining})
+	}
+}
+
+func parseDay(s string) time.Time {
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
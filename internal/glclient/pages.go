@@ -0,0 +1,178 @@
+package glclient
+
+import (
+	"context"
+	"sync"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// DefaultWorkers is how many list requests run at once unless configured
+// otherwise.
+const DefaultWorkers = 4
+
+// Workers returns how many requests the client runs at once, and so how
+// many workers a caller fanning out over the client should use.
+func (g GitLab) Workers() int {
+	if g.workers < 1 {
+		return DefaultWorkers
+	}
+	return g.workers
+}
+
+// acquire takes one of the client's request slots, which are shared by
+// every copy of g, and returns the function that gives it back.
+func (g GitLab) acquire(ctx context.Context) (func(), error) {
+	if g.slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case g.slots <- struct{}{}:
+		return func() { <-g.slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// PageFunc is told about every page a list request receives: its number,
+// the total if GitLab sent one, and how many items it held.
+type PageFunc func(page, totalPages int64, items int)
+
+type pageFuncKey struct{}
+
+// WithPageFunc returns a context under which list requests report each page
+// to fn, which may be called from several goroutines at once. A nil fn
+// turns reporting off.
+func WithPageFunc(ctx context.Context, fn PageFunc) context.Context {
+	return context.WithValue(ctx, pageFuncKey{}, fn)
+}
+
+// pageFetcher requests one page of a list endpoint.
+type pageFetcher[T any] func(page int64, opts ...gitlab.RequestOptionFunc) ([]T, *gitlab.Response, error)
+
+// allPages fetches every page of a list endpoint and returns the items in
+// page order. Request errors are returned as an *APIError for failed.
+func allPages[T any](ctx context.Context, g GitLab, failed error, fetch pageFetcher[T]) ([]T, error) {
+	var all []T
+	err := eachPage(ctx, g, failed, 1, fetch, func(_ int64, items []T) error {
+		all = append(all, items...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// PageHandler receives the pages of a list one at a time, in page order.
+// Returning an error stops the list.
+type PageHandler[T any] func(page int64, items []T) error
+
+// eachPage fetches the pages of a list endpoint from page from onwards and
+// hands each to handle in order. The first page says how many there are,
+// and the rest are fetched concurrently, up to Workers pages ahead of the
+// one being handled, within the client's request slots.
+// GitLab leaves out the total for very large lists, which are then walked
+// one page after another.
+func eachPage[T any](ctx context.Context, g GitLab, failed error, from int64, fetch pageFetcher[T], handle PageHandler[T]) error {
+	if from < 1 {
+		from = 1
+	}
+	report, _ := ctx.Value(pageFuncKey{}).(PageFunc)
+	get := func(ctx context.Context, page int64) ([]T, *gitlab.Response, error) {
+		release, err := g.acquire(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer release()
+		items, resp, err := fetch(page, gitlab.WithContext(ctx))
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, nil, ctx.Err()
+			}
+			return nil, nil, apiError(failed, resp, err)
+		}
+		if report != nil {
+			report(page, resp.TotalPages, len(items))
+		}
+		return items, resp, nil
+	}
+
+	first, resp, err := get(ctx, from)
+	if err != nil {
+		return err
+	}
+	if err := handle(from, first); err != nil {
+		return err
+	}
+	if resp.NextPage == 0 {
+		return nil
+	}
+
+	if resp.TotalPages == 0 {
+		for page := resp.NextPage; page != 0; page = resp.NextPage {
+			var items []T
+			if items, resp, err = get(ctx, page); err != nil {
+				return err
+			}
+			if err := handle(page, items); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	type result struct {
+		items []T
+		err   error
+	}
+	// Pages are fetched a window ahead of the one being handled, so a long
+	// list is not held in memory waiting for its turn.
+	pages := make([]chan result, resp.TotalPages-from)
+	window := min(g.Workers(), len(pages))
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	fetchPage := func(i int) {
+		pages[i] = make(chan result, 1)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			items, _, err := get(ctx, from+1+int64(i))
+			if err != nil {
+				// A failed page makes the later ones pointless.
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+			pages[i] <- result{items, err}
+		}()
+	}
+	for i := range window {
+		fetchPage(i)
+	}
+	// Stop the fetches still running before returning.
+	defer wg.Wait()
+	defer cancel()
+
+	for i := range pages {
+		r := <-pages[i]
+		pages[i] = nil
+		if r.err != nil {
+			wg.Wait()
+			return firstErr
+		}
+		if next := i + window; next < len(pages) {
+			fetchPage(next)
+		}
+		if err := handle(from+1+int64(i), r.items); err != nil {
+			return err
+		}
+	}
+	return nil
+}
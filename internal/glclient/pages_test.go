@@ -0,0 +1,89 @@
+package glclient
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// numberedPages serves total pages, each holding its own number, and counts
+// how many were fetched.
+func numberedPages(total int64, fetched *atomic.Int64, fail int64) pageFetcher[int64] {
+	return func(page int64, _ ...gitlab.RequestOptionFunc) ([]int64, *gitlab.Response, error) {
+		fetched.Add(1)
+		if page == fail {
+			return nil, nil, errors.New("boom")
+		}
+		next := page + 1
+		if next > total {
+			next = 0
+		}
+		return []int64{page}, &gitlab.Response{TotalPages: total, NextPage: next}, nil
+	}
+}
+
+func TestEachPageWindow(t *testing.T) {
+	const total = 50
+	g := GitLab{workers: 3}
+	var fetched, handled atomic.Int64
+	var ahead int64
+	var got []int64
+	err := eachPage(context.Background(), g, ErrListIssuesFailed, 1, numberedPages(total, &fetched, 0), func(page int64, items []int64) error {
+		// Give the fetches time to run as far ahead as they may.
+		time.Sleep(2 * time.Millisecond)
+		ahead = max(ahead, fetched.Load()-handled.Add(1))
+		got = append(got, items...)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != total {
+		t.Fatalf("handled %d pages, want %d", len(got), total)
+	}
+	for i, page := range got {
+		if page != int64(i+1) {
+			t.Fatalf("page %d handled in position %d; want page order", page, i+1)
+		}
+	}
+	if limit := int64(g.Workers()) + 1; ahead > limit {
+		t.Errorf("fetched up to %d pages ahead of the one handled, want at most %d", ahead, limit)
+	}
+}
+
+func TestEachPageFrom(t *testing.T) {
+	var fetched atomic.Int64
+	var got []int64
+	err := eachPage(context.Background(), GitLab{}, ErrListIssuesFailed, 8, numberedPages(10, &fetched, 0), func(page int64, items []int64) error {
+		got = append(got, page)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[0] != 8 || got[2] != 10 {
+		t.Errorf("got pages %v, want 8 to 10", got)
+	}
+}
+
+func TestEachPageStopsAtError(t *testing.T) {
+	var fetched atomic.Int64
+	var handled []int64
+	err := eachPage(context.Background(), GitLab{workers: 2}, ErrListIssuesFailed, 1, numberedPages(100, &fetched, 5), func(page int64, _ []int64) error {
+		handled = append(handled, page)
+		return nil
+	})
+	if !errors.Is(err, ErrListIssuesFailed) {
+		t.Fatalf("got error %v, want ErrListIssuesFailed", err)
+	}
+	if len(handled) != 4 {
+		t.Errorf("handled pages %v, want 1 to 4", handled)
+	}
+	if n := fetched.Load(); n > 10 {
+		t.Errorf("fetched %d pages after page 5 failed", n)
+	}
+}
@@ -0,0 +1,359 @@
+package glclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chazzychouse/g2o/internal/render"
+	"github.com/chazzychouse/g2o/internal/store"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+const (
+	// recentPipelines is how many pipelines of a project are listed and
+	// kept in the store.
+	recentPipelines = 20
+
+	// jobLogPoll is how often a running job's log is checked for more
+	// output.
+	jobLogPoll = 2 * time.Second
+)
+
+// jobActive lists the statuses of a job that may still write to its log.
+var jobActive = []string{"created", "waiting_for_resource", "preparing", "pending", "running"}
+
+// RecentPipelines fetches the n most recent pipelines of a project. The
+// list endpoint leaves out who triggered a pipeline and how long it ran,
+// so each one is then fetched in full.
+func (g GitLab) RecentPipelines(ctx context.Context, pid any, n int) ([]*gitlab.Pipeline, error) {
+	release, err := g.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	infos, resp, err := g.client.Pipelines.ListProjectPipelines(pid, &gitlab.ListProjectPipelinesOptions{
+		ListOptions: gitlab.ListOptions{PerPage: int64(n)},
+		OrderBy:     gitlab.Ptr("id"),
+		Sort:        gitlab.Ptr("desc"),
+	}, gitlab.WithContext(ctx))
+	release()
+	if err != nil {
+		return nil, apiError(ErrListPipelinesFailed, resp, err)
+	}
+
+	pipelines := make([]*gitlab.Pipeline, len(infos))
+	errs := make([]error, len(infos))
+	var wg sync.WaitGroup
+	for i, info := range infos {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pipelines[i], errs[i] = g.GetPipeline(ctx, pid, info.ID)
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return pipelines, nil
+}
+
+// GetPipeline fetches one pipeline of a project.
+func (g GitLab) GetPipeline(ctx context.Context, pid any, id int64) (*gitlab.Pipeline, error) {
+	release, err := g.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	p, resp, err := g.client.Pipelines.GetPipeline(pid, id, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, apiError(ErrGetPipelineFailed, resp, err)
+	}
+	return p, nil
+}
+
+// RetryPipeline retries the failed and canceled jobs of a pipeline.
+func (g GitLab) RetryPipeline(ctx context.Context, pid any, id int64) (*gitlab.Pipeline, error) {
+	p, resp, err := g.client.Pipelines.RetryPipelineBuild(pid, id, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, apiError(ErrRetryPipelineFailed, resp, err)
+	}
+	return p, nil
+}
+
+// CancelPipeline cancels the jobs of a pipeline that have not finished.
+func (g GitLab) CancelPipeline(ctx context.Context, pid any, id int64) (*gitlab.Pipeline, error) {
+	p, resp, err := g.client.Pipelines.CancelPipelineBuild(pid, id, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, apiError(ErrCancelPipelineFailed, resp, err)
+	}
+	return p, nil
+}
+
+// AllPipelineJobs fetches the jobs of a pipeline, leaving out retried ones.
+func (g GitLab) AllPipelineJobs(ctx context.Context, pid any, id int64) ([]*gitlab.Job, error) {
+	return allPages(ctx, g, ErrListJobsFailed, func(page int64, ro ...gitlab.RequestOptionFunc) ([]*gitlab.Job, *gitlab.Response, error) {
+		return g.client.Jobs.ListPipelineJobs(pid, id, &gitlab.ListJobsOptions{
+			ListOptions: gitlab.ListOptions{PerPage: perPage, Page: page},
+		}, ro...)
+	})
+}
+
+// GetJob fetches one job of a project.
+func (g GitLab) GetJob(ctx context.Context, pid any, id int64) (*gitlab.Job, error) {
+	j, resp, err := g.client.Jobs.GetJob(pid, id, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, apiError(ErrGetJobFailed, resp, err)
+	}
+	return j, nil
+}
+
+// JobLog fetches the log of a job as far as it has been written, asking
+// only for what follows byte from. It returns the bytes with the offset
+// they start at: from when the server honoured the range, 0 when it sent
+// the whole log.
+func (g GitLab) JobLog(ctx context.Context, pid any, id int64, from int) ([]byte, int, error) {
+	opts := []gitlab.RequestOptionFunc{gitlab.WithContext(ctx)}
+	if from > 0 {
+		opts = append(opts, gitlab.WithHeader("Range", fmt.Sprintf("bytes=%d-", from)))
+	}
+	r, resp, err := g.client.Jobs.GetTraceFile(pid, id, opts...)
+	if from > 0 && resp != nil {
+		switch resp.StatusCode {
+		case http.StatusPartialContent:
+			// The client counts anything but a 200 as an error, keeping the
+			// body with it.
+			var e *gitlab.ErrorResponse
+			if !errors.As(err, &e) {
+				break
+			}
+			if start, _, ok := contentRange(resp.Header.Get("Content-Range")); ok && start >= 0 {
+				return e.Body, start, nil
+			}
+			return e.Body, from, nil
+		case http.StatusRequestedRangeNotSatisfiable:
+			// Nothing follows from yet, unless the log is now shorter than
+			// that, in which case it is fetched again whole.
+			if _, total, ok := contentRange(resp.Header.Get("Content-Range")); ok && total < from {
+				return g.JobLog(ctx, pid, id, 0)
+			}
+			return nil, from, nil
+		}
+	}
+	if err != nil {
+		return nil, 0, apiError(ErrGetJobLogFailed, resp, err)
+	}
+	log, err := io.ReadAll(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	return log, 0, nil
+}
+
+// contentRange parses a Content-Range header such as "bytes 100-199/200"
+// or "bytes */200". start is -1 when the header gives no range and total
+// is -1 when the length is unknown ("/*").
+func contentRange(h string) (start, total int, ok bool) {
+	spec, found := strings.CutPrefix(h, "bytes ")
+	if !found {
+		return 0, 0, false
+	}
+	rng, size, found := strings.Cut(spec, "/")
+	if !found {
+		return 0, 0, false
+	}
+	start, total = -1, -1
+	if rng != "*" {
+		first, _, _ := strings.Cut(rng, "-")
+		n, err := strconv.Atoi(first)
+		if err != nil {
+			return 0, 0, false
+		}
+		start = n
+	}
+	if size != "*" {
+		n, err := strconv.Atoi(size)
+		if err != nil {
+			return 0, 0, false
+		}
+		total = n
+	}
+	return start, total, true
+}
+
+// RunPipelines lists the recent pipelines of a project and keeps them in
+// the store, which is listed instead when GitLab cannot be reached.
+func (g GitLab) RunPipelines(ctx context.Context, project string) error {
+	pipelines, err := g.RecentPipelines(ctx, project, recentPipelines)
+	if err != nil {
+		if errors.Is(err, ErrOffline) && g.store != nil {
+			if pid, serr := g.storeProjectID(project); serr == nil {
+				if cached, serr := g.store.ListProjectPipelines(pid, recentPipelines); serr == nil && len(cached) > 0 {
+					return render.Pipelines(g.out, g.format, cached)
+				}
+			}
+		}
+		return err
+	}
+	out := make([]store.StorePipeline, len(pipelines))
+	for i, p := range pipelines {
+		out[i] = ConvertPipeline(p)
+	}
+	if g.store != nil && len(out) > 0 {
+		if err := g.store.UpsertPipelines(out); err != nil {
+			g.log.Debug("cache pipelines", "error", err)
+		} else if err := g.store.PruneProjectPipelines(out[0].ProjectID, recentPipelines); err != nil {
+			g.log.Debug("prune pipelines", "error", err)
+		}
+	}
+	return render.Pipelines(g.out, g.format, out)
+}
+
+// RunPipeline shows one pipeline of a project with its jobs, caching both.
+// The stored copy is shown when GitLab cannot be reached.
+func (g GitLab) RunPipeline(ctx context.Context, project, id string) error {
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid pipeline ID %q", id)
+	}
+	p, err := g.GetPipeline(ctx, project, n)
+	var jobs []*gitlab.Job
+	if err == nil {
+		jobs, err = g.AllPipelineJobs(ctx, project, n)
+	}
+	if err != nil {
+		if errors.Is(err, ErrOffline) && g.store != nil {
+			if d, serr := g.storedPipeline(n); serr == nil {
+				return render.Pipeline(g.out, g.format, d)
+			}
+		}
+		return err
+	}
+	d := render.PipelineDetail{StorePipeline: ConvertPipeline(p), Jobs: ConvertJobs(jobs)}
+	// GitLab lists jobs newest first; stage order reads better.
+	slices.Reverse(d.Jobs)
+	if g.store != nil {
+		if err := g.store.UpsertPipelines([]store.StorePipeline{d.StorePipeline}); err != nil {
+			g.log.Debug("cache pipeline", "error", err)
+		}
+		if err := g.store.UpsertJobs(d.Jobs); err != nil {
+			g.log.Debug("cache jobs", "error", err)
+		}
+	}
+	return render.Pipeline(g.out, g.format, d)
+}
+
+func (g GitLab) storedPipeline(id int64) (render.PipelineDetail, error) {
+	p, err := g.store.GetPipeline(id)
+	if err != nil {
+		return render.PipelineDetail{}, err
+	}
+	jobs, err := g.store.ListPipelineJobs(id)
+	return render.PipelineDetail{StorePipeline: p, Jobs: jobs}, err
+}
+
+// RunRetryPipeline retries the failed jobs of a pipeline.
+func (g GitLab) RunRetryPipeline(ctx context.Context, project, id string) error {
+	return g.runPipelineWrite(ctx, "retried", project, id, g.RetryPipeline)
+}
+
+// RunCancelPipeline cancels a running pipeline.
+func (g GitLab) RunCancelPipeline(ctx context.Context, project, id string) error {
+	return g.runPipelineWrite(ctx, "canceled", project, id, g.CancelPipeline)
+}
+
+func (g GitLab) runPipelineWrite(ctx context.Context, verb, project, id string, write func(context.Context, any, int64) (*gitlab.Pipeline, error)) error {
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid pipeline ID %q", id)
+	}
+	p, err := write(ctx, project, n)
+	if err != nil {
+		return err
+	}
+	sp := ConvertPipeline(p)
+	if g.store != nil {
+		if err := g.store.UpsertPipelines([]store.StorePipeline{sp}); err != nil {
+			g.log.Debug("cache pipeline", "error", err)
+		}
+	}
+	return render.PipelineChanged(g.out, g.format, verb, sp)
+}
+
+// RunJobLog writes the log of a job and, while the job is still running,
+// follows it, writing new output as it arrives until the job finishes or
+// ctx is canceled. project may be empty for a job stored with its
+// pipeline.
+func (g GitLab) RunJobLog(ctx context.Context, id, project string) error {
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid job ID %q", id)
+	}
+	pid, err := g.jobProject(n, project)
+	if err != nil {
+		return err
+	}
+
+	var written int
+	for {
+		job, err := g.GetJob(ctx, pid, n)
+		if err != nil {
+			return err
+		}
+		if g.store != nil {
+			if err := g.store.UpsertJobs(ConvertJobs([]*gitlab.Job{job})); err != nil {
+				g.log.Debug("cache job", "error", err)
+			}
+		}
+		log, start, err := g.JobLog(ctx, pid, n, written)
+		if err != nil {
+			return err
+		}
+		// A log is normally only appended to, but GitLab may rewrite it,
+		// e.g. when a job is retried under the same ID. A log shorter than
+		// what was written is shown again from the start.
+		if start == 0 && len(log) < written {
+			if _, err := fmt.Fprint(g.out, "\n-- the job log was restarted --\n"); err != nil {
+				return err
+			}
+			written = 0
+		}
+		if end := start + len(log); end > written && start <= written {
+			if _, err := g.out.Write(log[written-start:]); err != nil {
+				return err
+			}
+			written = end
+		}
+		if !slices.Contains(jobActive, job.Status) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(jobLogPoll):
+		}
+	}
+}
+
+// jobProject finds the project a job belongs to: project when given,
+// otherwise the one stored with the job.
+func (g GitLab) jobProject(id int64, project string) (any, error) {
+	if project != "" {
+		return project, nil
+	}
+	if g.store != nil {
+		if j, err := g.store.GetJob(id); err == nil && j.ProjectID != 0 {
+			return j.ProjectID, nil
+		}
+	}
+	return nil, fmt.Errorf("job %d is not in the local store; give its project: job %d log <project>", id, id)
+}
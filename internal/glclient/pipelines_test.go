@@ -0,0 +1,113 @@
+package glclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chazzychouse/g2o/internal/render"
+)
+
+// traceServer serves *trace as the log of every job, honouring Range
+// requests unless ranges is false.
+func traceServer(t *testing.T, trace *string, ranges bool) GitLab {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/trace") {
+			http.NotFound(w, r)
+			return
+		}
+		if ranges {
+			http.ServeContent(w, r, "trace", time.Time{}, strings.NewReader(*trace))
+			return
+		}
+		io.WriteString(w, *trace)
+	}))
+	t.Cleanup(srv.Close)
+	g, err := NewGitlab("token", WithBaseURL(srv.URL), WithRetries(0), WithOutput(io.Discard, render.Plain))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestJobLog(t *testing.T) {
+	tests := []struct {
+		name      string
+		ranges    bool
+		trace     string
+		from      int
+		want      string
+		wantStart int
+	}{
+		{"first fetch", true, "line 1\n", 0, "line 1\n", 0},
+		{"only what follows", true, "line 1\nline 2\n", 7, "line 2\n", 7},
+		{"nothing new", true, "line 1\n", 7, "", 7},
+		{"log shrank", true, "new\n", 7, "new\n", 0},
+		{"range ignored", false, "line 1\nline 2\n", 7, "line 1\nline 2\n", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := traceServer(t, &tt.trace, tt.ranges)
+			log, start, err := g.JobLog(context.Background(), 1, 5, tt.from)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(log) != tt.want || start != tt.wantStart {
+				t.Errorf("got %q from %d, want %q from %d", log, start, tt.want, tt.wantStart)
+			}
+		})
+	}
+}
+
+func TestContentRange(t *testing.T) {
+	tests := []struct {
+		header       string
+		start, total int
+		ok           bool
+	}{
+		{"bytes 100-199/200", 100, 200, true},
+		{"bytes */200", -1, 200, true},
+		{"bytes 0-9/*", 0, -1, true},
+		{"items 0-9/10", 0, 0, false},
+		{"bytes 0-9", 0, 0, false},
+		{"", 0, 0, false},
+	}
+	for _, tt := range tests {
+		start, total, ok := contentRange(tt.header)
+		if start != tt.start || total != tt.total || ok != tt.ok {
+			t.Errorf("contentRange(%q) = %d, %d, %v; want %d, %d, %v",
+				tt.header, start, total, ok, tt.start, tt.total, tt.ok)
+		}
+	}
+}
+
+// TestRunJobLogFinished checks a finished job's log is written once, whole.
+func TestRunJobLogFinished(t *testing.T) {
+	trace := "line 1\nline 2\n"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/trace") {
+			http.ServeContent(w, r, "trace", time.Time{}, strings.NewReader(trace))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id": 5, "status": "success"}`)
+	}))
+	t.Cleanup(srv.Close)
+	var out bytes.Buffer
+	g, err := NewGitlab("token", WithBaseURL(srv.URL), WithRetries(0), WithOutput(&out, render.Plain))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.RunJobLog(context.Background(), "5", "acme/api"); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != trace {
+		t.Errorf("wrote %q, want %q", out.String(), trace)
+	}
+}
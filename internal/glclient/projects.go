@@ -18,12 +18,12 @@ func (p Project) String() string {
 }
 
 func (g GitLab) MyProjects() ([]*gitlab.Project, error) {
-	projects, _, err := g.client.Projects.ListProjects(&gitlab.ListProjectsOptions{
+	projects, resp, err := g.client.Projects.ListProjects(&gitlab.ListProjectsOptions{
 		Membership: gitlab.Ptr(true),
 		Archived:   gitlab.Ptr(false),
 	})
 	if err != nil {
-		return nil, ErrListProjectsFailed
+		return nil, apiError(ErrListProjectsFailed, resp, err)
 	}
 
 	var active []*gitlab.Project
@@ -38,8 +38,20 @@ func (g GitLab) MyProjects() ([]*gitlab.Project, error) {
 // AllProjects fetches all projects with pagination and optional LastActivityAfter filter.
 func (g GitLab) AllProjects(ctx context.Context, lastActivityAfter *time.Time) ([]*gitlab.Project, error) {
 	var all []*gitlab.Project
-	page := int64(1)
-	for {
+	err := g.ProjectPages(ctx, lastActivityAfter, 1, func(_ int64, projects []*gitlab.Project) error {
+		all = append(all, projects...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// ProjectPages hands the projects AllProjects would return to fn a page at
+// a time, starting at page from. Projects pending deletion are left out.
+func (g GitLab) ProjectPages(ctx context.Context, lastActivityAfter *time.Time, from int64, fn PageHandler[*gitlab.Project]) error {
+	return eachPage(ctx, g, ErrListProjectsFailed, from, func(page int64, ro ...gitlab.RequestOptionFunc) ([]*gitlab.Project, *gitlab.Response, error) {
 		opts := &gitlab.ListProjectsOptions{
 			ListOptions: gitlab.ListOptions{PerPage: perPage, Page: page},
 			Membership:  gitlab.Ptr(true),
@@ -48,26 +60,92 @@ func (g GitLab) AllProjects(ctx context.Context, lastActivityAfter *time.Time) (
 		if lastActivityAfter != nil {
 			opts.LastActivityAfter = lastActivityAfter
 		}
-		projects, resp, err := g.client.Projects.ListProjects(opts)
-		if err != nil {
-			return nil, ErrListProjectsFailed
-		}
+		return g.client.Projects.ListProjects(opts, ro...)
+	}, func(page int64, projects []*gitlab.Project) error {
+		active := projects[:0]
 		for _, p := range projects {
 			if p.MarkedForDeletionOn == nil {
-				all = append(all, p)
+				active = append(active, p)
 			}
 		}
-		if resp.NextPage == 0 {
-			break
-		}
-		page = resp.NextPage
+		return fn(page, active)
+	})
+}
+
+// GetProject fetches one project by numeric ID or path.
+func (g GitLab) GetProject(ctx context.Context, pid any) (*gitlab.Project, error) {
+	p, resp, err := g.client.Projects.GetProject(pid, nil, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, apiError(ErrGetProjectFailed, resp, err)
 	}
-	return all, nil
+	return p, nil
 }
 
-func listProjects(projects []*gitlab.Project) {
-	fmt.Println(styles.Title.Render(fmt.Sprintf("Projects: %d", len(projects))))
-	for _, p := range projects {
-		fmt.Println(Project{p})
+// ProjectPath resolves project, a numeric ID or a path such as
+// "group/sub/project", to the path_with_namespace GitLab has for it. The
+// store is asked first, then the API.
+func (g GitLab) ProjectPath(ctx context.Context, project string) (string, error) {
+	if g.store != nil {
+		if p, err := g.storeProject(project); err == nil {
+			return p.PathWithNamespace, nil
+		}
+	}
+	p, err := g.GetProject(ctx, project)
+	if err != nil {
+		return "", err
 	}
+	return p.PathWithNamespace, nil
+}
+
+// AllProjectMergeRequests fetches the merge requests of a project in state
+// ("opened", "merged", "closed" or "all").
+func (g GitLab) AllProjectMergeRequests(ctx context.Context, pid any, state string) ([]*gitlab.BasicMergeRequest, error) {
+	return allPages(ctx, g, ErrListMergeRequestsFailed, func(page int64, ro ...gitlab.RequestOptionFunc) ([]*gitlab.BasicMergeRequest, *gitlab.Response, error) {
+		return g.client.MergeRequests.ListProjectMergeRequests(pid, &gitlab.ListProjectMergeRequestsOptions{
+			ListOptions: gitlab.ListOptions{PerPage: perPage, Page: page},
+			State:       gitlab.Ptr(state),
+		}, ro...)
+	})
+}
+
+// AllProjectMilestones fetches the milestones of a project.
+func (g GitLab) AllProjectMilestones(ctx context.Context, pid any) ([]*gitlab.Milestone, error) {
+	return allPages(ctx, g, ErrListMilestonesFailed, func(page int64, ro ...gitlab.RequestOptionFunc) ([]*gitlab.Milestone, *gitlab.Response, error) {
+		return g.client.Milestones.ListMilestones(pid, &gitlab.ListMilestonesOptions{
+			ListOptions: gitlab.ListOptions{PerPage: perPage, Page: page},
+		}, ro...)
+	})
+}
+
+// AllProjectLabels fetches the labels of a project, including those it
+// inherits from its groups, with their issue counts.
+func (g GitLab) AllProjectLabels(ctx context.Context, pid any) ([]*gitlab.Label, error) {
+	return allPages(ctx, g, ErrListLabelsFailed, func(page int64, ro ...gitlab.RequestOptionFunc) ([]*gitlab.Label, *gitlab.Response, error) {
+		return g.client.Labels.ListLabels(pid, &gitlab.ListLabelsOptions{
+			ListOptions:           gitlab.ListOptions{PerPage: perPage, Page: page},
+			WithCounts:            gitlab.Ptr(true),
+			IncludeAncestorGroups: gitlab.Ptr(true),
+		}, ro...)
+	})
+}
+
+// AllProjectOwnLabels fetches the labels a project defines itself, without
+// those of its groups.
+func (g GitLab) AllProjectOwnLabels(ctx context.Context, pid any) ([]*gitlab.Label, error) {
+	return allPages(ctx, g, ErrListLabelsFailed, func(page int64, ro ...gitlab.RequestOptionFunc) ([]*gitlab.Label, *gitlab.Response, error) {
+		return g.client.Labels.ListLabels(pid, &gitlab.ListLabelsOptions{
+			ListOptions:           gitlab.ListOptions{PerPage: perPage, Page: page},
+			IncludeAncestorGroups: gitlab.Ptr(false),
+		}, ro...)
+	})
+}
+
+// AllProjectMembers fetches the members of a project, including those it
+// inherits from its groups.
+func (g GitLab) AllProjectMembers(ctx context.Context, pid any) ([]*gitlab.ProjectMember, error) {
+	return allPages(ctx, g, ErrListMembersFailed, func(page int64, ro ...gitlab.RequestOptionFunc) ([]*gitlab.ProjectMember, *gitlab.Response, error) {
+		return g.client.ProjectMembers.ListAllProjectMembers(pid, &gitlab.ListProjectMembersOptions{
+			ListOptions: gitlab.ListOptions{PerPage: perPage, Page: page},
+		}, ro...)
+	})
 }
@@ -0,0 +1,383 @@
+package glclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chazzychouse/g2o/internal/render"
+	"github.com/chazzychouse/g2o/internal/store"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// Kinds of issue writes. Every kind except creating an issue can be queued
+// while GitLab is unreachable.
+const (
+	OpEdit    = "edit"
+	OpClose   = "close"
+	OpReopen  = "reopen"
+	OpAssign  = "assign"
+	OpLabel   = "label"
+	OpComment = "comment"
+)
+
+// issueWrite is one change to an existing issue. It is applied directly when
+// GitLab is reachable and stored as the payload of a pending_ops row when it
+// is not.
+type issueWrite struct {
+	Kind   string      `json:"-"`
+	Fields IssueFields `json:"fields,omitzero"` // edit
+	Users  []string    `json:"users,omitempty"` // assign
+	Add    []string    `json:"add,omitempty"`   // label
+	Remove []string    `json:"remove,omitempty"`
+	Body   string      `json:"body,omitempty"` // comment
+}
+
+// apply sends w to the API and returns the updated issue.
+func (g GitLab) apply(pid any, iid int64, w issueWrite) (*gitlab.Issue, error) {
+	switch w.Kind {
+	case OpEdit:
+		return g.EditIssue(pid, iid, w.Fields)
+	case OpClose:
+		return g.CloseIssue(pid, iid)
+	case OpReopen:
+		return g.ReopenIssue(pid, iid)
+	case OpAssign:
+		return g.AssignIssue(pid, iid, w.Users)
+	case OpLabel:
+		return g.LabelIssue(pid, iid, w.Add, w.Remove)
+	case OpComment:
+		return g.CommentIssue(pid, iid, w.Body)
+	}
+	return nil, fmt.Errorf("unknown write %q", w.Kind)
+}
+
+// applyLocal makes the change of w to the stored copy of an issue, as the
+// server is expected to once the write is replayed.
+func (g GitLab) applyLocal(issue store.StoreIssue, w issueWrite) store.StoreIssue {
+	switch w.Kind {
+	case OpEdit:
+		f := w.Fields
+		if f.Title != nil {
+			issue.Title = *f.Title
+		}
+		if f.Description != nil {
+			issue.Description = *f.Description
+		}
+		if f.Milestone != nil {
+			issue.MilestoneTitle = *f.Milestone
+		}
+		if f.DueDate != nil {
+			issue.DueDate = *f.DueDate
+		}
+		if f.Assignees != nil {
+			issue.Assignees = g.localAssignees(f.Assignees)
+		}
+	case OpClose:
+		issue.State = "closed"
+		issue.ClosedAt = time.Now().UTC()
+	case OpReopen:
+		issue.State = "opened"
+		issue.ClosedAt = time.Time{}
+	case OpAssign:
+		issue.Assignees = g.localAssignees(w.Users)
+	case OpLabel:
+		labels := slices.DeleteFunc(slices.Clone(issue.Labels), func(l string) bool {
+			return slices.Contains(w.Remove, l)
+		})
+		for _, l := range w.Add {
+			if !slices.Contains(labels, l) {
+				labels = append(labels, l)
+			}
+		}
+		issue.Labels = labels
+	case OpComment:
+		issue.UserNotesCount++
+	}
+	return issue
+}
+
+// localAssignees stands in for the users the server will resolve, with only
+// usernames known.
+func (g GitLab) localAssignees(usernames []string) []store.StoreAssignee {
+	out := make([]store.StoreAssignee, 0, len(usernames))
+	for _, name := range usernames {
+		name = strings.TrimPrefix(name, "@")
+		if name == "me" {
+			if u, err := g.store.GetCurrentUser(); err == nil {
+				out = append(out, store.StoreAssignee{ID: u.ID, Name: u.Name, Username: u.Username})
+				continue
+			}
+		}
+		out = append(out, store.StoreAssignee{Username: name})
+	}
+	return out
+}
+
+// queue stores w in the outbox and applies it to the local copy of the
+// issue, so listings show the change before it reaches GitLab.
+func (g GitLab) queue(project string, iid int64, w issueWrite) (store.StoreIssue, error) {
+	if g.store == nil {
+		return store.StoreIssue{}, ErrOffline
+	}
+	pid, err := g.storeProjectID(project)
+	if err != nil {
+		return store.StoreIssue{}, fmt.Errorf("%w, and project %s is not in the local store", ErrOffline, project)
+	}
+	issue, err := g.store.GetIssue(pid, iid)
+	if err != nil {
+		return store.StoreIssue{}, fmt.Errorf("%w, and issue %s#%d is not in the local store", ErrOffline, project, iid)
+	}
+	payload, err := json.Marshal(w)
+	if err != nil {
+		return issue, err
+	}
+	id, err := g.store.EnqueueOp(store.StorePendingOp{
+		Kind:          w.Kind,
+		ProjectID:     issue.ProjectID,
+		IssueID:       issue.ID,
+		IID:           issue.IID,
+		Payload:       payload,
+		BaseUpdatedAt: issue.UpdatedAt,
+	})
+	if err != nil {
+		return issue, err
+	}
+
+	issue = g.applyLocal(issue, w)
+	if err := g.store.UpsertIssues([]store.StoreIssue{issue}); err != nil {
+		return issue, err
+	}
+	if w.Kind == OpComment {
+		// The local copy uses the negated op ID so it cannot collide with a
+		// real note; it is removed once the comment has been replayed.
+		note := store.StoreIssueNote{ID: -id, IssueID: issue.ID, Body: w.Body, CreatedAt: time.Now().UTC()}
+		if u, err := g.store.GetCurrentUser(); err == nil {
+			note.AuthorID, note.AuthorName, note.AuthorUsername = u.ID, u.Name, u.Username
+		}
+		if err := g.store.AddIssueNote(note); err != nil {
+			return issue, err
+		}
+	}
+	return issue, nil
+}
+
+// ReplayResult counts the outcome of replaying the outbox.
+type ReplayResult struct {
+	Applied   int
+	Conflicts int
+	Failed    int
+	Remaining int // still pending, e.g. because GitLab went away again
+}
+
+// ReplayPendingOps sends queued writes to GitLab in the order they were
+// made. A write whose issue changed on the server since it was queued is
+// marked as a conflict instead of overwriting that change, and later writes
+// to the same issue wait behind a conflicted or failed one. Replay stops at
+// the first write that cannot reach GitLab.
+func (g GitLab) ReplayPendingOps(ctx context.Context) (ReplayResult, error) {
+	var res ReplayResult
+	if g.store == nil {
+		return res, nil
+	}
+	ops, err := g.store.ListPendingOps()
+	if err != nil {
+		return res, err
+	}
+
+	blocked := map[int64]bool{}      // issues with an unresolved earlier write
+	written := map[int64]time.Time{} // issue updated_at after our own replayed write
+	for i, op := range ops {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		if op.Status != store.OpPending || blocked[op.IssueID] {
+			blocked[op.IssueID] = true
+			if op.Status == store.OpPending {
+				res.Remaining++
+			}
+			continue
+		}
+
+		w := issueWrite{Kind: op.Kind}
+		if err := json.Unmarshal(op.Payload, &w); err != nil {
+			if err := g.store.MarkPendingOp(op.ID, store.OpFailed, "invalid payload: "+err.Error()); err != nil {
+				return res, err
+			}
+			res.Failed++
+			blocked[op.IssueID] = true
+			continue
+		}
+
+		base := op.BaseUpdatedAt
+		if t, ok := written[op.IssueID]; ok {
+			base = t
+		}
+		if !base.IsZero() {
+			current, resp, err := g.client.Issues.GetIssue(op.ProjectID, op.IID)
+			if err != nil {
+				err = apiError(ErrGetIssueFailed, resp, err)
+				if errors.Is(err, ErrOffline) {
+					res.Remaining += countPending(ops[i:])
+					return res, err
+				}
+				if err := g.store.MarkPendingOp(op.ID, store.OpFailed, err.Error()); err != nil {
+					return res, err
+				}
+				res.Failed++
+				blocked[op.IssueID] = true
+				continue
+			}
+			// The store keeps whole seconds.
+			if current.UpdatedAt != nil && current.UpdatedAt.Truncate(time.Second).After(base) {
+				msg := "issue changed on the server at " + current.UpdatedAt.UTC().Format(time.RFC3339)
+				if err := g.store.MarkPendingOp(op.ID, store.OpConflict, msg); err != nil {
+					return res, err
+				}
+				res.Conflicts++
+				blocked[op.IssueID] = true
+				continue
+			}
+		}
+
+		// A comment cannot be told apart from a second copy of it, so it is
+		// done as soon as it is posted; the issue is fetched after that.
+		var issue *gitlab.Issue
+		if w.Kind == OpComment {
+			err = g.createNote(op.ProjectID, op.IID, w.Body)
+		} else {
+			issue, err = g.apply(op.ProjectID, op.IID, w)
+		}
+		if errors.Is(err, ErrOffline) {
+			res.Remaining += countPending(ops[i:])
+			return res, err
+		}
+		if err != nil {
+			if err := g.store.MarkPendingOp(op.ID, store.OpFailed, err.Error()); err != nil {
+				return res, err
+			}
+			res.Failed++
+			blocked[op.IssueID] = true
+			continue
+		}
+
+		if err := g.store.DeletePendingOp(op.ID); err != nil {
+			return res, err
+		}
+		if op.Kind == OpComment {
+			if err := g.store.DeleteIssueNote(-op.ID); err != nil {
+				return res, err
+			}
+		}
+		res.Applied++
+		if issue == nil {
+			var resp *gitlab.Response
+			issue, resp, err = g.client.Issues.GetIssue(op.ProjectID, op.IID)
+			if err != nil {
+				// Without the new updated_at, later writes to the issue
+				// cannot be checked; they wait for the next replay.
+				g.log.Debug("refresh commented issue", "iid", op.IID, "error", apiError(ErrGetIssueFailed, resp, err))
+				blocked[op.IssueID] = true
+				continue
+			}
+		}
+		g.cacheIssue(issue)
+		if issue.UpdatedAt != nil {
+			written[op.IssueID] = issue.UpdatedAt.Truncate(time.Second)
+		}
+	}
+	return res, nil
+}
+
+func countPending(ops []store.StorePendingOp) int {
+	n := 0
+	for _, op := range ops {
+		if op.Status == store.OpPending {
+			n++
+		}
+	}
+	return n
+}
+
+// RunQueue lists the writes waiting in the outbox.
+func (g GitLab) RunQueue() error {
+	if g.store == nil {
+		return fmt.Errorf("the write queue needs the local store")
+	}
+	ops, err := g.store.ListPendingOps()
+	if err != nil {
+		return err
+	}
+	return render.PendingOps(g.out, g.format, ops)
+}
+
+// RunRetryOp puts a conflicted or failed write back in the queue, dropping
+// its conflict check, and replays the queue.
+func (g GitLab) RunRetryOp(ctx context.Context, id string) error {
+	n, err := parseOpID(id)
+	if err != nil {
+		return err
+	}
+	if g.store == nil {
+		return fmt.Errorf("the write queue needs the local store")
+	}
+	if err := g.store.RetryPendingOp(n); err != nil {
+		if errors.Is(err, store.ErrRecordNotFound) {
+			return fmt.Errorf("no queued write %d", n)
+		}
+		return err
+	}
+	res, err := g.ReplayPendingOps(ctx)
+	if err != nil && !errors.Is(err, ErrOffline) {
+		return err
+	}
+	if rerr := render.Replay(g.out, g.format, res.Applied, res.Conflicts, res.Failed, res.Remaining); rerr != nil {
+		return rerr
+	}
+	return err
+}
+
+// RunDropOp removes a write from the outbox without sending it. The local
+// copy of the issue is refreshed from GitLab when it can be reached, and
+// otherwise keeps the dropped change until the issue is next synced.
+func (g GitLab) RunDropOp(id string) error {
+	n, err := parseOpID(id)
+	if err != nil {
+		return err
+	}
+	if g.store == nil {
+		return fmt.Errorf("the write queue needs the local store")
+	}
+	op, err := g.store.GetPendingOp(n)
+	if err != nil {
+		if errors.Is(err, store.ErrRecordNotFound) {
+			return fmt.Errorf("no queued write %d", n)
+		}
+		return err
+	}
+	if err := g.store.DeletePendingOp(n); err != nil {
+		return err
+	}
+	if op.Kind == OpComment {
+		if err := g.store.DeleteIssueNote(-n); err != nil {
+			return err
+		}
+	}
+	if issue, _, err := g.client.Issues.GetIssue(op.ProjectID, op.IID); err == nil {
+		g.cacheIssue(issue)
+	}
+	return render.PendingOpDropped(g.out, g.format, op)
+}
+
+func parseOpID(id string) (int64, error) {
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid queue ID %q", id)
+	}
+	return n, nil
+}
@@ -0,0 +1,327 @@
+package glclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chazzychouse/g2o/internal/render"
+	"github.com/chazzychouse/g2o/internal/store"
+)
+
+// fakeIssue is a one-issue GitLab: project 10, issue #1. Every write moves
+// its updated_at forward, as GitLab does, with a fractional second the store
+// does not keep.
+type fakeIssue struct {
+	mu        sync.Mutex
+	state     string
+	labels    []string
+	updatedAt time.Time
+	writes    []string // method and path of each write received
+
+	failAfterNote bool // answer GETs with an error once a note is posted
+	noted         bool
+}
+
+func (f *fakeIssue) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/10/issues/1":
+		if f.failAfterNote && f.noted {
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		}
+	case r.Method == http.MethodPut && r.URL.Path == "/api/v4/projects/10/issues/1":
+		var opt struct {
+			StateEvent string `json:"state_event"`
+			AddLabels  string `json:"add_labels"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&opt)
+		switch opt.StateEvent {
+		case "close":
+			f.state = "closed"
+		case "reopen":
+			f.state = "opened"
+		}
+		if opt.AddLabels != "" {
+			f.labels = append(f.labels, opt.AddLabels)
+		}
+		f.touch(r)
+	case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/10/issues/1/notes":
+		f.touch(r)
+		f.noted = true
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"id": 900, "body": "note"}`)
+		return
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"id": 100, "iid": 1, "project_id": 10, "title": "Crash", "state": f.state,
+		"labels": f.labels, "assignees": []any{}, "updated_at": f.updatedAt,
+	})
+}
+
+func (f *fakeIssue) touch(r *http.Request) {
+	f.updatedAt = f.updatedAt.Add(time.Minute + 300*time.Millisecond)
+	f.writes = append(f.writes, r.Method+" "+r.URL.Path)
+}
+
+func (f *fakeIssue) setUpdatedAt(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.updatedAt = t
+}
+
+func (f *fakeIssue) received() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.writes...)
+}
+
+func openTestStore(t *testing.T) *store.Store {
+	t.Helper()
+	s, err := store.Open(filepath.Join(t.TempDir(), "g2o.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// newQueueTest returns a client for a fake GitLab whose issue was last
+// stored at updated, as it was when writes to it were queued.
+func newQueueTest(t *testing.T, updated time.Time) (GitLab, *fakeIssue, *store.Store) {
+	t.Helper()
+	fake := &fakeIssue{state: "opened", labels: []string{}, updatedAt: updated}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	s := openTestStore(t)
+	if err := s.UpsertProjects([]store.StoreProject{{ID: 10, Path: "api", PathWithNamespace: "acme/api"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpsertIssues([]store.StoreIssue{{ID: 100, IID: 1, ProjectID: 10, Title: "Crash", State: "opened",
+		Labels: []string{}, Assignees: []store.StoreAssignee{}, UpdatedAt: updated.Truncate(time.Second)}}); err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewGitlab("token", WithBaseURL(srv.URL), WithStore(s), WithRetries(0), WithOutput(io.Discard, render.Plain))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g, fake, s
+}
+
+func queueWrites(t *testing.T, g GitLab, writes ...issueWrite) {
+	t.Helper()
+	for _, w := range writes {
+		if _, err := g.queue("acme/api", 1, w); err != nil {
+			t.Fatalf("queue %s: %v", w.Kind, err)
+		}
+	}
+}
+
+func TestReplayPendingOpsApplies(t *testing.T) {
+	// The server copy carries a fraction of a second the store dropped; that
+	// alone is not a change.
+	updated := time.Date(2026, 10, 1, 12, 0, 0, 700_000_000, time.UTC)
+	g, fake, s := newQueueTest(t, updated)
+	queueWrites(t, g,
+		issueWrite{Kind: OpClose},
+		issueWrite{Kind: OpComment, Body: "fixed in !4"},
+		issueWrite{Kind: OpLabel, Add: []string{"done"}},
+	)
+
+	notes, err := s.ListIssueNotes(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notes) != 1 || notes[0].ID >= 0 {
+		t.Fatalf("queued comment: got notes %+v, want one with a negative ID", notes)
+	}
+
+	// Each replayed write moves updated_at on; the writes after it must be
+	// checked against that, not against when they were queued.
+	res, err := g.ReplayPendingOps(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != (ReplayResult{Applied: 3}) {
+		t.Errorf("got %+v, want all three applied", res)
+	}
+	if got := fake.received(); len(got) != 3 {
+		t.Errorf("server received %v, want three writes", got)
+	}
+
+	ops, err := s.ListPendingOps()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != 0 {
+		t.Errorf("%d ops left in the outbox, want none", len(ops))
+	}
+	if notes, err = s.ListIssueNotes(100); err != nil {
+		t.Fatal(err)
+	}
+	if len(notes) != 0 {
+		t.Errorf("got notes %+v, want the local copy of the comment removed", notes)
+	}
+	issue, err := s.GetIssue(10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issue.State != "closed" {
+		t.Errorf("stored issue is %s, want the server's closed state", issue.State)
+	}
+}
+
+func TestReplayPendingOpsConflict(t *testing.T) {
+	updated := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	g, fake, s := newQueueTest(t, updated)
+	queueWrites(t, g,
+		issueWrite{Kind: OpClose},
+		issueWrite{Kind: OpComment, Body: "closing"},
+	)
+	// Someone else edits the issue before the queue is replayed.
+	fake.setUpdatedAt(updated.Add(time.Hour))
+
+	res, err := g.ReplayPendingOps(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != (ReplayResult{Conflicts: 1, Remaining: 1}) {
+		t.Errorf("got %+v, want the close in conflict and the comment waiting behind it", res)
+	}
+	if got := fake.received(); len(got) != 0 {
+		t.Errorf("server received %v, want no writes over its change", got)
+	}
+
+	ops, err := s.ListPendingOps()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != 2 || ops[0].Status != store.OpConflict || ops[1].Status != store.OpPending {
+		t.Fatalf("got ops %+v, want a conflict followed by a pending write", ops)
+	}
+	notes, err := s.ListIssueNotes(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notes) != 1 || notes[0].ID != -ops[1].ID {
+		t.Errorf("got notes %+v, want the queued comment kept as note %d", notes, -ops[1].ID)
+	}
+
+	// Retrying drops the check: the close goes through, and the comment is
+	// checked against the close rather than the stale base.
+	if err := s.RetryPendingOp(ops[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	res, err = g.ReplayPendingOps(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != (ReplayResult{Applied: 2}) {
+		t.Errorf("after retry got %+v, want both applied", res)
+	}
+}
+
+// TestReplayPendingOpsCommentOnce checks that a comment counts as made once
+// it is posted, even when fetching the issue afterwards fails, so it is not
+// posted again.
+func TestReplayPendingOpsCommentOnce(t *testing.T) {
+	updated := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	g, fake, s := newQueueTest(t, updated)
+	queueWrites(t, g,
+		issueWrite{Kind: OpComment, Body: "fixed in !4"},
+		issueWrite{Kind: OpClose},
+	)
+	fake.mu.Lock()
+	fake.failAfterNote = true
+	fake.mu.Unlock()
+
+	res, err := g.ReplayPendingOps(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The close cannot be checked against the comment's updated_at, so it
+	// waits.
+	if res != (ReplayResult{Applied: 1, Remaining: 1}) {
+		t.Errorf("got %+v, want the comment applied and the close waiting", res)
+	}
+	ops, err := s.ListPendingOps()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != 1 || ops[0].Kind != OpClose || ops[0].Status != store.OpPending {
+		t.Fatalf("got ops %+v, want only the close pending", ops)
+	}
+	notes, err := s.ListIssueNotes(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notes) != 0 {
+		t.Errorf("got notes %+v, want the local copy of the comment removed", notes)
+	}
+
+	// The server still fails, now on the close's check.
+	if _, err := g.ReplayPendingOps(context.Background()); !errors.Is(err, ErrOffline) {
+		t.Fatalf("got error %v, want ErrOffline", err)
+	}
+	if got := fake.received(); len(got) != 1 {
+		t.Errorf("server received %v, want the comment posted once", got)
+	}
+}
+
+// TestCommentIssueNotQueuedAfterPost checks that a comment posted directly
+// is not queued when only fetching the issue afterwards fails.
+func TestCommentIssueNotQueuedAfterPost(t *testing.T) {
+	g, fake, s := newQueueTest(t, time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC))
+	fake.mu.Lock()
+	fake.failAfterNote = true
+	fake.mu.Unlock()
+
+	err := g.RunCommentIssue("10", "1", "fixed in !4")
+	if err == nil || errors.Is(err, ErrOffline) {
+		t.Errorf("got error %v, want one that is not ErrOffline", err)
+	}
+	if n, err := s.CountPendingOps(store.OpPending); err != nil || n != 0 {
+		t.Errorf("%d writes queued (%v), want none", n, err)
+	}
+	if got := fake.received(); len(got) != 1 {
+		t.Errorf("server received %v, want the comment posted once", got)
+	}
+}
+
+func TestReplayPendingOpsOffline(t *testing.T) {
+	updated := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	g, _, s := newQueueTest(t, updated)
+	queueWrites(t, g, issueWrite{Kind: OpClose}, issueWrite{Kind: OpReopen})
+
+	// Point the client at a server that is gone.
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	g, err := NewGitlab("token", WithBaseURL(srv.URL), WithStore(s), WithRetries(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := g.ReplayPendingOps(context.Background())
+	if !errors.Is(err, ErrOffline) {
+		t.Fatalf("got error %v, want ErrOffline", err)
+	}
+	if res != (ReplayResult{Remaining: 2}) {
+		t.Errorf("got %+v, want both writes still pending", res)
+	}
+	if n, err := s.CountPendingOps(store.OpPending); err != nil || n != 2 {
+		t.Errorf("got %d pending ops (%v), want 2", n, err)
+	}
+}
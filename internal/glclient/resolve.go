@@ -0,0 +1,151 @@
+package glclient
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/chazzychouse/g2o/internal/store"
+)
+
+// AmbiguousError is returned when a group or project name matches more than
+// one stored path equally well, or only matches part of a path, which is
+// too loose to act on without asking. Matches are in path order.
+type AmbiguousError struct {
+	Kind    string // "group" or "project"
+	Query   string
+	Matches []store.PathRef
+}
+
+func (e *AmbiguousError) Error() string {
+	const shown = 10
+	var paths []string
+	for _, m := range e.Matches[:min(len(e.Matches), shown)] {
+		paths = append(paths, m.Path)
+	}
+	if n := len(e.Matches) - shown; n > 0 {
+		paths = append(paths, fmt.Sprintf("and %d more", n))
+	}
+	if len(e.Matches) == 1 {
+		return fmt.Sprintf("%s %q is not a full name; did you mean %s?", e.Kind, e.Query, e.Matches[0].Path)
+	}
+	return fmt.Sprintf("%s %q is ambiguous: %s", e.Kind, e.Query, strings.Join(paths, ", "))
+}
+
+// ResolveProject turns a numeric ID, a path_with_namespace or its trailing
+// segments such as "api" or "plat/api" into the path of a stored project.
+// Names the store does not know are returned unchanged for GitLab to look
+// up; partial names come back as an AmbiguousError to confirm.
+func (g GitLab) ResolveProject(project string) (string, error) {
+	if g.store == nil {
+		return project, nil
+	}
+	refs, err := g.store.ListProjectPaths()
+	if err != nil {
+		return "", err
+	}
+	m, err := resolvePath("project", project, refs)
+	if err != nil || m == nil {
+		return project, err
+	}
+	return m.Path, nil
+}
+
+// ResolveGroup is ResolveProject for groups and their full_path. It returns
+// the group's ID, which its stored issues are kept under.
+func (g GitLab) ResolveGroup(group string) (string, error) {
+	if g.store == nil {
+		return group, nil
+	}
+	refs, err := g.store.ListGroupPaths()
+	if err != nil {
+		return "", err
+	}
+	m, err := resolvePath("group", group, refs)
+	if err != nil || m == nil {
+		return group, err
+	}
+	return strconv.FormatInt(m.ID, 10), nil
+}
+
+// ProjectPaths returns the stored project paths, for completion.
+func (g GitLab) ProjectPaths() []string {
+	return g.paths(g.store.ListProjectPaths)
+}
+
+// GroupPaths returns the stored group paths, for completion.
+func (g GitLab) GroupPaths() []string {
+	return g.paths(g.store.ListGroupPaths)
+}
+
+func (g GitLab) paths(list func() ([]store.PathRef, error)) []string {
+	if g.store == nil {
+		return nil
+	}
+	refs, err := list()
+	if err != nil {
+		return nil
+	}
+	paths := make([]string, len(refs))
+	for i, r := range refs {
+		paths[i] = r.Path
+	}
+	return paths
+}
+
+// resolvePath picks the ref query names. Numeric IDs and exact paths are
+// taken as given; otherwise the best-scoring refs win. A tie among them, or
+// a lone match on less than whole trailing segments, is an AmbiguousError:
+// "api" must not silently pick acme/api-gateway. It returns nil when nothing
+// matches.
+func resolvePath(kind, query string, refs []store.PathRef) (*store.PathRef, error) {
+	if id, err := strconv.ParseInt(query, 10, 64); err == nil {
+		for i := range refs {
+			if refs[i].ID == id {
+				return &refs[i], nil
+			}
+		}
+		return nil, nil
+	}
+	var best []store.PathRef
+	top := 0
+	for _, r := range refs {
+		score := matchScore(query, r.Path)
+		switch {
+		case score == 0 || score < top:
+		case score > top:
+			top, best = score, []store.PathRef{r}
+		default:
+			best = append(best, r)
+		}
+	}
+	switch {
+	case len(best) == 0:
+		return nil, nil
+	case len(best) == 1 && top >= segmentMatch:
+		return &best[0], nil
+	}
+	return nil, &AmbiguousError{Kind: kind, Query: query, Matches: best}
+}
+
+// segmentMatch is the lowest score resolvePath accepts on its own.
+const segmentMatch = 3
+
+// matchScore rates how well query names path, ignoring case: 4 for the
+// whole path, 3 for its trailing segments ("api" or "plat/api" for
+// "acme/plat/api"), 2 for a prefix and 1 for a substring. 0 means no
+// match.
+func matchScore(query, path string) int {
+	q, p := strings.ToLower(query), strings.ToLower(path)
+	switch {
+	case q == p:
+		return 4
+	case strings.HasSuffix(p, "/"+q):
+		return segmentMatch
+	case strings.HasPrefix(p, q):
+		return 2
+	case strings.Contains(p, q):
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,105 @@
+package glclient
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/chazzychouse/g2o/internal/store"
+)
+
+func TestMatchScore(t *testing.T) {
+	tests := []struct {
+		query, path string
+		want        int
+	}{
+		{"acme/api", "acme/api", 4},
+		{"ACME/Api", "acme/api", 4},
+		{"api", "acme/plat/api", 3},
+		{"plat/api", "acme/plat/api", 3},
+		{"acme/pl", "acme/plat/api", 2},
+		{"plat", "acme/plat/api", 1},
+		{"api", "acme/api-gateway", 1},
+		{"api", "acme/a-p-i-gateway", 0},
+		{"api", "acme/web", 0},
+	}
+	for _, tt := range tests {
+		if got := matchScore(tt.query, tt.path); got != tt.want {
+			t.Errorf("matchScore(%q, %q) = %d, want %d", tt.query, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestResolvePath(t *testing.T) {
+	refs := []store.PathRef{
+		{ID: 1, Path: "acme/api"},
+		{ID: 2, Path: "acme/api-gateway"},
+		{ID: 3, Path: "acme/a-p-i-tools"},
+		{ID: 4, Path: "other/api"},
+		{ID: 5, Path: "acme/web"},
+		{ID: 6, Path: "acme/plat/billing"},
+	}
+	tests := []struct {
+		query   string
+		want    int64   // resolved ID, 0 for none
+		matches []int64 // IDs in the AmbiguousError
+	}{
+		{query: "5", want: 5},
+		{query: "99"},
+		{query: "acme/api", want: 1},
+		{query: "ACME/WEB", want: 5},
+		{query: "web", want: 5},
+		{query: "plat/billing", want: 6},
+		{query: "nothing"},
+		// Two projects end in /api.
+		{query: "api", matches: []int64{1, 4}},
+		// Partial matches are never taken on their own.
+		{query: "gateway", matches: []int64{2}},
+		{query: "acme/api-g", matches: []int64{2}},
+		{query: "bill", matches: []int64{6}},
+		{query: "acme/a", matches: []int64{1, 2, 3}},
+		// Letters in order are no match at all.
+		{query: "apit"},
+	}
+	for _, tt := range tests {
+		got, err := resolvePath("project", tt.query, refs)
+		var amb *AmbiguousError
+		switch {
+		case tt.matches != nil:
+			if !errors.As(err, &amb) {
+				t.Errorf("resolvePath(%q) = %v, %v; want an AmbiguousError", tt.query, got, err)
+				continue
+			}
+			var ids []int64
+			for _, m := range amb.Matches {
+				ids = append(ids, m.ID)
+			}
+			if len(ids) != len(tt.matches) {
+				t.Errorf("resolvePath(%q) matched %v, want %v", tt.query, ids, tt.matches)
+				continue
+			}
+			for i := range ids {
+				if ids[i] != tt.matches[i] {
+					t.Errorf("resolvePath(%q) matched %v, want %v", tt.query, ids, tt.matches)
+					break
+				}
+			}
+		case err != nil:
+			t.Errorf("resolvePath(%q): %v", tt.query, err)
+		case tt.want == 0 && got != nil:
+			t.Errorf("resolvePath(%q) = %s, want no match", tt.query, got.Path)
+		case tt.want != 0 && (got == nil || got.ID != tt.want):
+			t.Errorf("resolvePath(%q) = %v, want ID %d", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestAmbiguousErrorMessage(t *testing.T) {
+	one := &AmbiguousError{Kind: "project", Query: "gateway", Matches: []store.PathRef{{ID: 2, Path: "acme/api-gateway"}}}
+	if got, want := one.Error(), `project "gateway" is not a full name; did you mean acme/api-gateway?`; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	two := &AmbiguousError{Kind: "group", Query: "api", Matches: []store.PathRef{{Path: "acme/api"}, {Path: "other/api"}}}
+	if got, want := two.Error(), `group "api" is ambiguous: acme/api, other/api`; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -2,81 +2,102 @@ package glclient
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 
+	"github.com/chazzychouse/g2o/internal/render"
 	"github.com/chazzychouse/g2o/internal/store"
-	"github.com/chazzychouse/g2o/internal/styles"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
 )
 
 func (g GitLab) RunGroups() error {
 	if g.store != nil {
 		groups, err := g.store.ListGroups()
 		if err == nil && len(groups) > 0 {
-			listStoreGroups(groups)
-			return nil
+			return render.Groups(g.out, g.format, groups)
 		}
 	}
 	groups, err := g.MyGroups()
 	if err != nil {
 		return err
 	}
-	listGroups(groups)
-	return nil
+	return render.Groups(g.out, g.format, ConvertGroups(groups))
 }
 
 func (g GitLab) RunProjects() error {
 	if g.store != nil {
 		projects, err := g.store.ListProjects()
 		if err == nil && len(projects) > 0 {
-			listStoreProjects(projects)
-			return nil
+			return render.Projects(g.out, g.format, projects)
 		}
 	}
 	projects, err := g.MyProjects()
 	if err != nil {
 		return err
 	}
-	listProjects(projects)
-	return nil
+	return render.Projects(g.out, g.format, ConvertProjects(projects))
 }
 
 func (g GitLab) RunCurrentUser() error {
 	if g.store != nil {
 		u, err := g.store.GetCurrentUser()
 		if err == nil {
-			fmt.Printf("%s %s\n%s %s\n%s %s\n",
-				styles.Label.Render("name:    "), styles.Value.Render(u.Name),
-				styles.Label.Render("username:"), styles.Value.Render(u.Username),
-				styles.Label.Render("email:   "), styles.Value.Render(u.Email))
-			return nil
+			return render.User(g.out, g.format, u)
 		}
 	}
 	user, err := g.CurrentUser()
 	if err != nil {
 		return err
 	}
-	fmt.Printf("%s %s\n%s %s\n%s %s\n",
-		styles.Label.Render("name:    "), styles.Value.Render(user.Name),
-		styles.Label.Render("username:"), styles.Value.Render(user.Username),
-		styles.Label.Render("email:   "), styles.Value.Render(user.Email))
-	return nil
+	return render.User(g.out, g.format, ConvertUser(user))
 }
 
-func (g GitLab) RunIssues() error {
+// RunIssues lists issues, narrowed by filter terms in the store query
+// language (see store.ParseIssueQuery). Filtering needs the local store; an
+// unfiltered listing falls back to the API when the store is empty.
+func (g GitLab) RunIssues(filter []string) error {
+	if len(filter) > 0 {
+		if g.store == nil {
+			return fmt.Errorf("issue filters need the local store")
+		}
+		q, err := store.ParseIssueQuery(filter)
+		if err != nil {
+			return err
+		}
+		issues, err := g.store.QueryIssues(q)
+		if err != nil {
+			return err
+		}
+		return g.listStoreIssues(issues)
+	}
 	if g.store != nil {
 		issues, err := g.store.ListIssues()
 		if err == nil && len(issues) > 0 {
-			listStoreIssues(issues)
-			return nil
+			return g.listStoreIssues(issues)
 		}
 	}
 	issues, err := g.Issues()
 	if err != nil {
 		return err
 	}
-	listIssues(issues)
-	return nil
+	return g.listStoreIssues(ConvertIssues(issues))
+}
+
+// searchLimit caps the number of full-text search hits shown.
+const searchLimit = 50
+
+// RunSearch runs a full-text search over the locally stored issues.
+func (g GitLab) RunSearch(terms []string) error {
+	if g.store == nil {
+		return fmt.Errorf("search needs the local store")
+	}
+	hits, err := g.store.SearchIssues(terms, searchLimit)
+	if err != nil {
+		return err
+	}
+	return render.SearchHits(g.out, g.format, hits)
 }
 
 func (g GitLab) RunGroupsIssues(ctx context.Context, id any) error {
@@ -85,57 +106,365 @@ func (g GitLab) RunGroupsIssues(ctx context.Context, id any) error {
 		if gid, err := toInt64(id); err == nil {
 			issues, err := g.store.ListIssuesByGroup(gid)
 			if err == nil && len(issues) > 0 {
-				for _, issue := range issues {
-					fmt.Printf("%s %s\n",
-						styles.Value.Render(issue.Title),
-						styles.Label.Render("("+strconv.FormatInt(issue.IID, 10)+")"))
-				}
-				fmt.Println(styles.Title.Render(fmt.Sprintf("Total: %d", len(issues))))
-				return nil
+				return g.listStoreIssues(issues)
 			}
 		}
 	}
 
 	ch, errc := g.GetGroupsIssues(ctx, id)
-	count := 0
+	var issues []*gitlab.Issue
 	for issue := range ch {
-		fmt.Println(Issue{issue})
-		count++
+		issues = append(issues, issue)
 	}
 	if err := <-errc; err != nil {
 		return err
 	}
-	fmt.Println(styles.Title.Render(fmt.Sprintf("Total: %d", count)))
-	return nil
+	return g.listStoreIssues(ConvertIssues(issues))
+}
+
+func (g GitLab) RunMergeRequests() error {
+	if g.store != nil {
+		mrs, err := g.store.ListMergeRequests("opened")
+		if err == nil && len(mrs) > 0 {
+			return render.MergeRequests(g.out, g.format, mrs)
+		}
+	}
+	mrs, err := g.MergeRequests()
+	if err != nil {
+		return err
+	}
+	return render.MergeRequests(g.out, g.format, ConvertMergeRequests(mrs))
+}
+
+// RunMergeRequest shows one merge request. project is a numeric ID or a
+// path such as "group/project". A merge request whose details are not in the
+// store is fetched from the API and cached.
+func (g GitLab) RunMergeRequest(project, iid string) error {
+	n, err := strconv.ParseInt(iid, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid merge request IID %q", iid)
+	}
+	// A row without pipeline/approval details is only shown if the API
+	// cannot be reached.
+	var cached *store.StoreMergeRequest
+	if g.store != nil {
+		if pid, err := g.storeProjectID(project); err == nil {
+			mr, err := g.store.GetMergeRequest(pid, n)
+			if err == nil && !mr.DetailSyncedAt.IsZero() {
+				return render.MergeRequest(g.out, g.format, mr)
+			}
+			if err == nil {
+				cached = &mr
+			}
+		}
+	}
+	d, err := g.GetMergeRequest(project, n)
+	if err != nil {
+		if cached != nil {
+			return render.MergeRequest(g.out, g.format, *cached)
+		}
+		return err
+	}
+	mr := ConvertMergeRequestDetail(d)
+	if g.store != nil {
+		if err := g.store.UpsertMergeRequests([]store.StoreMergeRequest{mr}); err != nil {
+			g.log.Debug("cache merge request", "error", err)
+		}
+	}
+	return render.MergeRequest(g.out, g.format, mr)
+}
+
+// RunIssue shows one issue with its description, linked issues and notes.
+// project is a numeric ID or a path such as "group/project". The stored copy
+// is used when its notes are at least as new as the issue; otherwise the
+// issue is fetched from the API and cached, and the stored copy is only
+// shown if the API cannot be reached.
+func (g GitLab) RunIssue(ctx context.Context, project, iid string) error {
+	n, err := strconv.ParseInt(iid, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid issue IID %q", iid)
+	}
+	var cached *render.IssueDetail
+	if g.store != nil {
+		if pid, err := g.storeProjectID(project); err == nil {
+			if issue, err := g.store.GetIssue(pid, n); err == nil {
+				d, err := g.storedIssueDetail(issue)
+				if err == nil && !issue.NotesSyncedAt.IsZero() && !issue.NotesSyncedAt.Before(issue.UpdatedAt) {
+					return render.Issue(g.out, g.format, d)
+				}
+				if err == nil {
+					cached = &d
+				}
+			}
+		}
+	}
+
+	d, err := g.fetchIssueDetail(ctx, project, n)
+	if err != nil {
+		if cached != nil {
+			return render.Issue(g.out, g.format, *cached)
+		}
+		return err
+	}
+	if g.store != nil {
+		if err := g.store.UpsertIssues([]store.StoreIssue{d.StoreIssue}); err != nil {
+			g.log.Debug("cache issue", "error", err)
+		} else if err := g.store.ReplaceIssueDiscussion(d.ID, d.Notes, d.Links); err != nil {
+			g.log.Debug("cache issue notes", "error", err)
+		}
+	}
+	return render.Issue(g.out, g.format, d)
+}
+
+func (g GitLab) storedIssueDetail(issue store.StoreIssue) (render.IssueDetail, error) {
+	d := render.IssueDetail{StoreIssue: issue}
+	var err error
+	if d.Notes, err = g.store.ListIssueNotes(issue.ID); err != nil {
+		return d, err
+	}
+	d.Links, err = g.store.ListIssueLinks(issue.ID)
+	return d, err
+}
+
+func (g GitLab) fetchIssueDetail(ctx context.Context, project string, iid int64) (render.IssueDetail, error) {
+	issue, err := g.GetIssue(project, iid)
+	if err != nil {
+		return render.IssueDetail{}, err
+	}
+	d := render.IssueDetail{StoreIssue: ConvertIssues([]*gitlab.Issue{issue})[0]}
+	d.Notes, d.Links, err = g.IssueDiscussion(ctx, issue.ProjectID, issue.ID, issue.IID)
+	return d, err
+}
+
+// RunCreateIssue opens a new issue in project and adds it to the store.
+func (g GitLab) RunCreateIssue(project string, f IssueFields) error {
+	issue, err := g.CreateIssue(project, f)
+	if err != nil {
+		return err
+	}
+	return render.IssueChanged(g.out, g.format, "created", g.cacheIssue(issue))
+}
+
+// RunEditIssue changes the fields set in f. Like the other issue writes
+// below, it is queued when GitLab cannot be reached.
+func (g GitLab) RunEditIssue(project, iid string, f IssueFields) error {
+	return g.runIssueWrite("updated", project, iid, issueWrite{Kind: OpEdit, Fields: f})
+}
+
+func (g GitLab) RunCloseIssue(project, iid string) error {
+	return g.runIssueWrite("closed", project, iid, issueWrite{Kind: OpClose})
+}
+
+func (g GitLab) RunReopenIssue(project, iid string) error {
+	return g.runIssueWrite("reopened", project, iid, issueWrite{Kind: OpReopen})
+}
+
+// RunAssignIssue replaces the assignees. The single username "none"
+// unassigns everyone.
+func (g GitLab) RunAssignIssue(project, iid string, usernames []string) error {
+	if len(usernames) == 1 && usernames[0] == "none" {
+		usernames = []string{}
+	}
+	return g.runIssueWrite("assigned", project, iid, issueWrite{Kind: OpAssign, Users: usernames})
+}
+
+// RunLabelIssue applies label changes written as "+name" (or just "name")
+// to add and "-name" to remove.
+func (g GitLab) RunLabelIssue(project, iid string, changes []string) error {
+	var add, remove []string
+	for _, c := range changes {
+		switch {
+		case strings.HasPrefix(c, "-"):
+			remove = append(remove, c[1:])
+		default:
+			add = append(add, strings.TrimPrefix(c, "+"))
+		}
+	}
+	if len(add) == 0 && len(remove) == 0 {
+		return fmt.Errorf("no label changes given (e.g. +bug -triage)")
+	}
+	return g.runIssueWrite("labeled", project, iid, issueWrite{Kind: OpLabel, Add: add, Remove: remove})
+}
+
+// RunCommentIssue adds a comment to an issue.
+func (g GitLab) RunCommentIssue(project, iid, body string) error {
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("empty comment")
+	}
+	return g.runIssueWrite("commented", project, iid, issueWrite{Kind: OpComment, Body: body})
+}
+
+// runIssueWrite parses iid, applies w and stores and reports the updated
+// issue. When GitLab is unreachable, w is queued and applied to the local
+// copy instead.
+func (g GitLab) runIssueWrite(verb, project, iid string, w issueWrite) error {
+	n, err := strconv.ParseInt(iid, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid issue IID %q", iid)
+	}
+	issue, err := g.apply(project, n, w)
+	if errors.Is(err, ErrOffline) {
+		queued, err := g.queue(project, n, w)
+		if err != nil {
+			return err
+		}
+		return render.IssueQueued(g.out, g.format, verb, queued)
+	}
+	if err != nil {
+		return err
+	}
+	return render.IssueChanged(g.out, g.format, verb, g.cacheIssue(issue))
+}
+
+// cacheIssue writes an issue returned by the API to the store, so listings
+// reflect a change without waiting for the next sync.
+func (g GitLab) cacheIssue(issue *gitlab.Issue) store.StoreIssue {
+	si := ConvertIssues([]*gitlab.Issue{issue})[0]
+	if g.store != nil {
+		if err := g.store.UpsertIssues([]store.StoreIssue{si}); err != nil {
+			g.log.Debug("cache issue", "error", err)
+		}
+	}
+	return si
+}
+
+// RunProject shows one project. project is a numeric ID or a path such as
+// "group/sub/project"; one missing from the store is fetched from the API.
+func (g GitLab) RunProject(ctx context.Context, project string) error {
+	if g.store != nil {
+		if p, err := g.storeProject(project); err == nil {
+			return render.Project(g.out, g.format, p)
+		}
+	}
+	p, err := g.GetProject(ctx, project)
+	if err != nil {
+		return err
+	}
+	return render.Project(g.out, g.format, ConvertProjects([]*gitlab.Project{p})[0])
+}
+
+// RunProjectIssues lists the issues of a project, narrowed by filter terms
+// as in RunIssues. Stored issues are used when there are any; otherwise the
+// project's issues are fetched from the API, which filters cannot apply to.
+func (g GitLab) RunProjectIssues(ctx context.Context, project string, filter []string) error {
+	if g.store != nil {
+		if pid, err := g.storeProjectID(project); err == nil {
+			scope := "project:" + strconv.FormatInt(pid, 10)
+			q, err := store.ParseIssueQuery([]string{scope})
+			if err != nil {
+				return err
+			}
+			issues, err := g.store.QueryIssues(q)
+			if err == nil && len(issues) > 0 {
+				if len(filter) == 0 {
+					return g.listStoreIssues(issues)
+				}
+				if q, err = store.ParseIssueQuery(append([]string{scope}, filter...)); err != nil {
+					return err
+				}
+				if issues, err = g.store.QueryIssues(q); err != nil {
+					return err
+				}
+				return g.listStoreIssues(issues)
+			}
+		}
+	}
+	if len(filter) > 0 {
+		return fmt.Errorf("issue filters need the project's issues in the store; run 'sync project %s'", project)
+	}
+	issues, err := g.AllIssues(ctx, IssueScope{Kind: ScopeProject, Path: project}, nil)
+	if err != nil {
+		return err
+	}
+	return g.listStoreIssues(ConvertIssues(issues))
 }
 
-func listStoreGroups(groups []store.StoreGroup) {
-	fmt.Println(styles.Title.Render(fmt.Sprintf("Groups: %d", len(groups))))
-	for _, g := range groups {
-		fmt.Printf("%s %s\n%s %s\n\n",
-			styles.Label.Render("name: "),
-			styles.Value.Render(g.Name),
-			styles.Label.Render("gid:  "),
-			styles.Value.Render(strconv.FormatInt(g.ID, 10)))
+// RunProjectMergeRequests lists the open merge requests of a project,
+// from the store when it holds any and from the API otherwise.
+func (g GitLab) RunProjectMergeRequests(ctx context.Context, project string) error {
+	if g.store != nil {
+		if pid, err := g.storeProjectID(project); err == nil {
+			mrs, err := g.store.ListProjectMergeRequests(pid, "opened")
+			if err == nil && len(mrs) > 0 {
+				return render.MergeRequests(g.out, g.format, mrs)
+			}
+		}
+	}
+	mrs, err := g.AllProjectMergeRequests(ctx, project, "opened")
+	if err != nil {
+		return err
+	}
+	return render.MergeRequests(g.out, g.format, ConvertMergeRequests(mrs))
+}
+
+// RunProjectMilestones lists the milestones of a project and its group,
+// from the store once they are synced.
+func (g GitLab) RunProjectMilestones(ctx context.Context, project string) error {
+	if g.store != nil {
+		if pid, err := g.storeProjectID(project); err == nil {
+			ms, err := g.store.ListProjectMilestones(pid)
+			if err == nil && len(ms) > 0 {
+				return render.Milestones(g.out, g.format, ms)
+			}
+		}
+	}
+	ms, err := g.AllProjectMilestones(ctx, project)
+	if err != nil {
+		return err
+	}
+	return render.Milestones(g.out, g.format, ConvertMilestones(ms))
+}
+
+// RunProjectLabels lists the labels a project can use with how many of its
+// issues carry each. Like "labels", the counts come from the stored issues;
+// a project missing from the store is asked of GitLab, which counts them
+// itself.
+func (g GitLab) RunProjectLabels(ctx context.Context, project string) error {
+	if g.store != nil {
+		if p, err := g.storeProject(project); err == nil {
+			usage, err := g.store.ListProjectLabelUsage(p.ID)
+			if err != nil {
+				return err
+			}
+			return render.LabelUsage(g.out, g.format, usage)
+		}
+	}
+	labels, err := g.AllProjectLabels(ctx, project)
+	if err != nil {
+		return err
+	}
+	return render.Labels(g.out, g.format, ConvertLabels(labels))
+}
+
+// RunProjectMembers lists who has access to a project.
+func (g GitLab) RunProjectMembers(ctx context.Context, project string) error {
+	members, err := g.AllProjectMembers(ctx, project)
+	if err != nil {
+		return err
 	}
+	return render.Members(g.out, g.format, ConvertMembers(members))
 }
 
-func listStoreProjects(projects []store.StoreProject) {
-	fmt.Println(styles.Title.Render(fmt.Sprintf("Projects: %d", len(projects))))
-	for _, p := range projects {
-		fmt.Printf("%s %s\n",
-			styles.Value.Render(p.Name),
-			styles.Label.Render("("+p.PathWithNamespace+")"))
+// storeProject looks up a project by numeric ID or path_with_namespace in
+// the local store.
+func (g GitLab) storeProject(project string) (store.StoreProject, error) {
+	if id, err := strconv.ParseInt(project, 10, 64); err == nil {
+		return g.store.GetProject(id)
 	}
+	return g.store.GetProjectByPath(project)
 }
 
-func listStoreIssues(issues []store.StoreIssue) {
-	fmt.Println(styles.Title.Render(fmt.Sprintf("Issues: %d", len(issues))))
-	for _, i := range issues {
-		fmt.Printf("%s %s\n",
-			styles.Value.Render(i.Title),
-			styles.Label.Render("("+strconv.FormatInt(i.IID, 10)+")"))
+// storeProjectID resolves a numeric ID or path_with_namespace to a project
+// ID using the local store.
+func (g GitLab) storeProjectID(project string) (int64, error) {
+	if id, err := strconv.ParseInt(project, 10, 64); err == nil {
+		return id, nil
+	}
+	p, err := g.store.GetProjectByPath(project)
+	if err != nil {
+		return 0, err
 	}
+	return p.ID, nil
 }
 
 func toInt64(v any) (int64, error) {
@@ -150,3 +479,27 @@ func toInt64(v any) (int64, error) {
 		return 0, fmt.Errorf("cannot convert %T to int64", v)
 	}
 }
+
+// RunLabels lists the labels of the catalogue and those found on stored
+// issues, with how many open and closed issues carry each.
+func (g GitLab) RunLabels() error {
+	if g.store == nil {
+		return fmt.Errorf("labels needs the local store")
+	}
+	usage, err := g.store.ListLabelUsage()
+	if err != nil {
+		return err
+	}
+	return render.LabelUsage(g.out, g.format, usage)
+}
+
+// listStoreIssues renders issues with their labels in the colours of the
+// stored label catalogue.
+func (g GitLab) listStoreIssues(issues []store.StoreIssue) error {
+	var catalogue map[string]store.StoreLabel
+	if g.store != nil && g.format == render.Plain {
+		// Without colours the labels still show, so a failed read is not fatal.
+		catalogue, _ = g.store.LabelCatalogue()
+	}
+	return render.LabelledIssues(g.out, g.format, issues, catalogue)
+}
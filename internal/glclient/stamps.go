@@ -0,0 +1,115 @@
+package glclient
+
+import (
+	"context"
+	"time"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// Stamp identifies a record and when it last changed, which is all that
+// reconciling the store against GitLab or verifying it needs. Groups carry
+// no change time.
+type Stamp struct {
+	ID        int64
+	UpdatedAt time.Time
+}
+
+// The *Stamps methods list the same records as their All* counterparts but
+// keep only a Stamp of each, asking GitLab for its smallest representation
+// where the endpoint has one.
+
+// GroupStamps lists the groups AllGroups would return.
+func (g GitLab) GroupStamps(ctx context.Context) ([]Stamp, error) {
+	return pageStamps(ctx, g, ErrListGroupsFailed, g.groupsPage, func(gr *gitlab.Group) Stamp {
+		return Stamp{ID: gr.ID}
+	})
+}
+
+// ProjectStamps lists the projects AllProjects would return, stamped with
+// their last activity as that is what incremental syncs go by. The simple
+// representation leaves out whether a project is pending deletion, so the
+// full one is listed through ProjectPages, which drops those projects.
+func (g GitLab) ProjectStamps(ctx context.Context) ([]Stamp, error) {
+	var stamps []Stamp
+	err := g.ProjectPages(ctx, nil, 1, func(_ int64, projects []*gitlab.Project) error {
+		for _, p := range projects {
+			stamps = append(stamps, Stamp{p.ID, ptrTime(p.LastActivityAt)})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stamps, nil
+}
+
+// IssueStamps lists the issues AllIssues would return for scope.
+func (g GitLab) IssueStamps(ctx context.Context, scope IssueScope) ([]Stamp, error) {
+	return pageStamps(ctx, g, ErrListIssuesFailed, g.issuesPage(scope, nil), issueStamp)
+}
+
+// GroupIssueStamps lists the issues AllGroupIssues would return for group
+// id.
+func (g GitLab) GroupIssueStamps(ctx context.Context, id any) ([]Stamp, error) {
+	return pageStamps(ctx, g, ErrListGroupIssuesFailed, func(page int64, ro ...gitlab.RequestOptionFunc) ([]*gitlab.Issue, *gitlab.Response, error) {
+		return g.client.Issues.ListGroupIssues(id, &gitlab.ListGroupIssuesOptions{
+			ListOptions: gitlab.ListOptions{PerPage: perPage, Page: page},
+			State:       gitlab.Ptr(groupIssuesState),
+		}, ro...)
+	}, issueStamp)
+}
+
+// MergeRequestStamps lists the merge requests AllMergeRequests would
+// return.
+func (g GitLab) MergeRequestStamps(ctx context.Context) ([]Stamp, error) {
+	var stamps []Stamp
+	seen := make(map[int64]bool)
+	for _, scope := range MergeRequestScopes {
+		scoped, err := pageStamps(ctx, g, ErrListMergeRequestsFailed, func(page int64, ro ...gitlab.RequestOptionFunc) ([]*gitlab.BasicMergeRequest, *gitlab.Response, error) {
+			return g.client.MergeRequests.ListMergeRequests(&gitlab.ListMergeRequestsOptions{
+				ListOptions: gitlab.ListOptions{PerPage: perPage, Page: page},
+				Scope:       gitlab.Ptr(scope),
+				State:       gitlab.Ptr("all"),
+				View:        gitlab.Ptr("simple"),
+			}, ro...)
+		}, func(mr *gitlab.BasicMergeRequest) Stamp { return Stamp{mr.ID, ptrTime(mr.UpdatedAt)} })
+		if err != nil {
+			return nil, err
+		}
+		for _, st := range scoped {
+			if !seen[st.ID] {
+				seen[st.ID] = true
+				stamps = append(stamps, st)
+			}
+		}
+	}
+	return stamps, nil
+}
+
+// StampIDs returns the IDs of stamps.
+func StampIDs(stamps []Stamp) []int64 {
+	ids := make([]int64, len(stamps))
+	for i, st := range stamps {
+		ids[i] = st.ID
+	}
+	return ids
+}
+
+func issueStamp(i *gitlab.Issue) Stamp {
+	return Stamp{i.ID, ptrTime(i.UpdatedAt)}
+}
+
+func pageStamps[T any](ctx context.Context, g GitLab, failed error, fetch pageFetcher[T], stamp func(T) Stamp) ([]Stamp, error) {
+	var stamps []Stamp
+	err := eachPage(ctx, g, failed, 1, fetch, func(_ int64, items []T) error {
+		for _, item := range items {
+			stamps = append(stamps, stamp(item))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stamps, nil
+}
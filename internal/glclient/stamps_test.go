@@ -0,0 +1,39 @@
+package glclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chazzychouse/g2o/internal/render"
+)
+
+func TestProjectStampsSkipsPendingDeletion(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v4/projects" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `[
+			{"id": 1, "last_activity_at": "2026-10-01T12:00:00Z"},
+			{"id": 2, "last_activity_at": "2026-10-02T12:00:00Z", "marked_for_deletion_on": "2026-10-03"},
+			{"id": 3, "last_activity_at": "2026-10-03T12:00:00Z"}
+		]`)
+	}))
+	defer srv.Close()
+
+	g, err := NewGitlab("token", WithBaseURL(srv.URL), WithRetries(0), WithOutput(io.Discard, render.Plain))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stamps, err := g.ProjectStamps(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stamps) != 2 || stamps[0].ID != 1 || stamps[1].ID != 3 {
+		t.Errorf("got stamps %+v, want projects 1 and 3", stamps)
+	}
+}
@@ -0,0 +1,104 @@
+package glclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/chazzychouse/g2o/internal/render"
+	"github.com/chazzychouse/g2o/internal/store"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// AllPendingTodos fetches the current user's pending to-do items.
+func (g GitLab) AllPendingTodos(ctx context.Context) ([]*gitlab.Todo, error) {
+	return allPages(ctx, g, ErrListTodosFailed, func(page int64, ro ...gitlab.RequestOptionFunc) ([]*gitlab.Todo, *gitlab.Response, error) {
+		return g.client.Todos.ListTodos(&gitlab.ListTodosOptions{
+			ListOptions: gitlab.ListOptions{PerPage: perPage, Page: page},
+			State:       gitlab.Ptr("pending"),
+		}, ro...)
+	})
+}
+
+// MarkTodoDone marks one to-do item done.
+func (g GitLab) MarkTodoDone(ctx context.Context, id int64) error {
+	resp, err := g.client.Todos.MarkTodoAsDone(id, gitlab.WithContext(ctx))
+	return apiError(ErrMarkTodoDoneFailed, resp, err)
+}
+
+// MarkAllTodosDone marks every pending to-do item done.
+func (g GitLab) MarkAllTodosDone(ctx context.Context) error {
+	resp, err := g.client.Todos.MarkAllTodosAsDone(gitlab.WithContext(ctx))
+	return apiError(ErrMarkTodoDoneFailed, resp, err)
+}
+
+// RunTodos lists the pending to-do items, from the store when it holds any
+// and from the API otherwise.
+func (g GitLab) RunTodos(ctx context.Context) error {
+	if g.store != nil {
+		todos, err := g.store.ListTodos("pending")
+		if err == nil && len(todos) > 0 {
+			return render.Todos(g.out, g.format, todos)
+		}
+	}
+	todos, err := g.AllPendingTodos(ctx)
+	if err != nil {
+		return err
+	}
+	out := ConvertTodos(todos)
+	if g.store != nil {
+		if err := g.store.UpsertTodos(out); err != nil {
+			g.log.Debug("cache to-do items", "error", err)
+		}
+	}
+	return render.Todos(g.out, g.format, out)
+}
+
+// RunTodoDone marks one to-do item done on GitLab and in the store.
+func (g GitLab) RunTodoDone(ctx context.Context, id string) error {
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid to-do item ID %q", id)
+	}
+	if err := g.MarkTodoDone(ctx, n); err != nil {
+		return err
+	}
+	todo := store.StoreTodo{ID: n}
+	if g.store != nil {
+		if err := g.store.MarkTodosDone(n); err != nil {
+			return err
+		}
+		if t, err := g.store.GetTodo(n); err == nil {
+			todo = t
+		} else if !errors.Is(err, store.ErrRecordNotFound) {
+			return err
+		}
+	}
+	todo.State = "done"
+	return render.TodosDone(g.out, g.format, []store.StoreTodo{todo})
+}
+
+// RunAllTodosDone marks every pending to-do item done on GitLab and in the
+// store.
+func (g GitLab) RunAllTodosDone(ctx context.Context) error {
+	var pending []store.StoreTodo
+	if g.store != nil {
+		var err error
+		if pending, err = g.store.ListTodos("pending"); err != nil {
+			return err
+		}
+	}
+	if err := g.MarkAllTodosDone(ctx); err != nil {
+		return err
+	}
+	if g.store != nil {
+		if err := g.store.MarkTodosDone(); err != nil {
+			return err
+		}
+	}
+	for i := range pending {
+		pending[i].State = "done"
+	}
+	return render.TodosDone(g.out, g.format, pending)
+}
@@ -0,0 +1,256 @@
+package glclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Retry policy of the transport.
+const (
+	DefaultRetries = 4
+
+	retryBaseDelay = 500 * time.Millisecond
+	retryMaxDelay  = 30 * time.Second
+	maxRetryAfter  = 5 * time.Minute // longer waits fail instead
+
+	// rateLimitLowWater is how many requests of the current window are kept
+	// in reserve; below it the transport waits for the window to reset.
+	rateLimitLowWater = 5
+)
+
+// TransientError reports a request that still failed after the transport
+// retried it: GitLab kept answering 429 or 5xx, or the connection kept
+// breaking.
+type TransientError struct {
+	Method     string
+	URL        string
+	StatusCode int // last HTTP status; 0 if no response arrived
+	Attempts   int
+	Err        error // last connection error, if any
+}
+
+func (e *TransientError) Error() string {
+	cause := http.StatusText(e.StatusCode)
+	if e.StatusCode != 0 {
+		cause = fmt.Sprintf("%d %s", e.StatusCode, cause)
+	}
+	if e.Err != nil {
+		cause = e.Err.Error()
+	}
+	return fmt.Sprintf("%s %s: %s (gave up after %d attempts)", e.Method, e.URL, cause, e.Attempts)
+}
+
+func (e *TransientError) Unwrap() error { return e.Err }
+
+// transport retries requests that failed for reasons likely to pass and
+// slows down before GitLab's rate limit is used up.
+//
+// A 429 is always retried, since GitLab did not act on the request. Other
+// 5xx answers and broken connections are only retried for requests that
+// are safe to repeat. Failing to connect at all is not retried, so an
+// offline client notices quickly.
+type transport struct {
+	base    http.RoundTripper
+	retries int
+	log     *slog.Logger
+
+	mu         sync.Mutex
+	pauseUntil time.Time // shared by all requests once a limit is near or hit
+}
+
+func newTransport(base http.RoundTripper, retries int, log *slog.Logger) *transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: base, retries: retries, log: log}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	for attempt := 1; ; attempt++ {
+		if err := t.waitForWindow(ctx); err != nil {
+			return nil, err
+		}
+		if attempt > 1 && req.Body != nil {
+			if req.GetBody == nil {
+				return nil, fmt.Errorf("cannot retry %s %s: body is not replayable", req.Method, req.URL.Redacted())
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(req)
+		if err == nil {
+			t.observe(resp)
+		}
+		delay, retry := t.shouldRetry(req, resp, err, attempt)
+		if !retry {
+			return resp, err
+		}
+		if attempt > t.retries {
+			if resp != nil {
+				drain(resp)
+			}
+			te := &TransientError{Method: req.Method, URL: req.URL.Redacted(), Attempts: attempt, Err: err}
+			if resp != nil {
+				te.StatusCode = resp.StatusCode
+			}
+			return nil, te
+		}
+
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
+			drain(resp)
+		}
+		t.log.Debug("retrying request", "method", req.Method, "url", req.URL.Redacted(),
+			"status", status, "error", err, "attempt", attempt, "delay", delay)
+		if !sleep(ctx, delay) {
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// shouldRetry decides whether to send req again and how long to wait
+// first.
+func (t *transport) shouldRetry(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	if err != nil {
+		if req.Context().Err() != nil || !idempotent(req) || dialFailed(err) {
+			return 0, false
+		}
+		return backoff(attempt), true
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		delay, ok := retryAfter(resp)
+		if !ok {
+			delay = backoff(attempt)
+		}
+		if delay > maxRetryAfter {
+			return 0, false
+		}
+		// Every request would get the same answer, so all of them wait.
+		t.pause(time.Now().Add(delay))
+		return delay, true
+	case resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented && idempotent(req):
+		if delay, ok := retryAfter(resp); ok && delay <= maxRetryAfter {
+			return delay, true
+		}
+		return backoff(attempt), true
+	}
+	return 0, false
+}
+
+// observe pauses further requests when resp says the rate limit window is
+// nearly used up.
+func (t *transport) observe(resp *http.Response) {
+	remaining, err := strconv.Atoi(resp.Header.Get("RateLimit-Remaining"))
+	if err != nil || remaining > rateLimitLowWater {
+		return
+	}
+	reset, err := strconv.ParseInt(resp.Header.Get("RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+	until := time.Unix(reset, 0)
+	if wait := time.Until(until); wait > 0 && wait <= maxRetryAfter {
+		t.log.Debug("rate limit nearly reached, pausing", "remaining", remaining, "until", until)
+		t.pause(until)
+	}
+}
+
+func (t *transport) pause(until time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if until.After(t.pauseUntil) {
+		t.pauseUntil = until
+	}
+}
+
+func (t *transport) waitForWindow(ctx context.Context) error {
+	t.mu.Lock()
+	wait := time.Until(t.pauseUntil)
+	t.mu.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+	if !sleep(ctx, wait) {
+		return ctx.Err()
+	}
+	return nil
+}
+
+// backoff returns the exponential delay before retry attempt, with jitter
+// so that concurrent workers do not retry in lockstep.
+func backoff(attempt int) time.Duration {
+	d := retryBaseDelay << (attempt - 1)
+	if d <= 0 || d > retryMaxDelay {
+		d = retryMaxDelay
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// retryAfter reads the Retry-After header, in seconds or as an HTTP date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if s, err := strconv.Atoi(v); err == nil && s >= 0 {
+		return time.Duration(s) * time.Second, true
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// dialFailed reports whether err means no connection was made at all.
+func dialFailed(err error) bool {
+	var op *net.OpError
+	if errors.As(err, &op) && op.Op == "dial" {
+		return true
+	}
+	var dns *net.DNSError
+	return errors.As(err, &dns)
+}
+
+// drain discards the rest of a response that will not be used so its
+// connection can be reused.
+func drain(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	resp.Body.Close()
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
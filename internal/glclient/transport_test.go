@@ -0,0 +1,176 @@
+package glclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// answer serves the given statuses in turn, repeating the last, and counts
+// the requests. Retry-After is 0 so retries do not wait.
+func answer(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int32, *[]string) {
+	t.Helper()
+	var n atomic.Int32
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := int(n.Add(1)) - 1
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(statuses[min(i, len(statuses)-1)])
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &n, &bodies
+}
+
+func testTransport(retries int) *http.Client {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return &http.Client{Transport: newTransport(http.DefaultTransport, retries, log)}
+}
+
+func TestTransportRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		statuses []int
+		want     int // final status
+		attempts int32
+	}{
+		{"GET 503 then 200", http.MethodGet, []int{503, 503, 200}, 200, 3},
+		{"GET 429 then 200", http.MethodGet, []int{429, 200}, 200, 2},
+		{"POST 429 then 201", http.MethodPost, []int{429, 201}, 201, 2},
+		{"POST 503 not retried", http.MethodPost, []int{503, 201}, 503, 1},
+		{"PUT 502 not retried", http.MethodPut, []int{502, 200}, 502, 1},
+		{"GET 501 not retried", http.MethodGet, []int{501, 200}, 501, 1},
+		{"GET 404 not retried", http.MethodGet, []int{404, 200}, 404, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, n, bodies := answer(t, tt.statuses...)
+			req, err := http.NewRequest(tt.method, srv.URL, strings.NewReader("payload"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := testTransport(4).Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("got status %d, want %d", resp.StatusCode, tt.want)
+			}
+			if got := n.Load(); got != tt.attempts {
+				t.Errorf("got %d attempts, want %d", got, tt.attempts)
+			}
+			// A retried request sends its body again in full.
+			for i, b := range *bodies {
+				if b != "payload" {
+					t.Errorf("attempt %d sent body %q", i+1, b)
+				}
+			}
+		})
+	}
+}
+
+func TestTransportGivesUp(t *testing.T) {
+	srv, n, _ := answer(t, 503)
+	_, err := testTransport(2).Get(srv.URL)
+	var te *TransientError
+	if !errors.As(err, &te) {
+		t.Fatalf("got error %v, want a TransientError", err)
+	}
+	if te.StatusCode != 503 || te.Attempts != 3 {
+		t.Errorf("got status %d after %d attempts, want 503 after 3", te.StatusCode, te.Attempts)
+	}
+	if got := n.Load(); got != 3 {
+		t.Errorf("server saw %d requests, want 3", got)
+	}
+}
+
+func TestTransportLongRetryAfter(t *testing.T) {
+	var n atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n.Add(1)
+		w.Header().Set("Retry-After", strconv.Itoa(int((maxRetryAfter + time.Minute).Seconds())))
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	resp, err := testTransport(4).Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests || n.Load() != 1 {
+		t.Errorf("got %d after %d attempts, want the 429 returned at once", resp.StatusCode, n.Load())
+	}
+}
+
+func TestTransportPausesNearRateLimit(t *testing.T) {
+	var n atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n.Add(1) == 1 {
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(rateLimitLowWater))
+			w.Header().Set("RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10))
+		}
+	}))
+	defer srv.Close()
+
+	client := testTransport(4)
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	// The next request waits for the window to reset, or until its
+	// context gives up.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	_, err = client.Do(req)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want the context deadline", err)
+	}
+	if waited := time.Since(start); waited > 5*time.Second {
+		t.Errorf("waited %s after the context ended", waited)
+	}
+	if got := n.Load(); got != 1 {
+		t.Errorf("server saw %d requests, want the second held back", got)
+	}
+}
+
+func TestTransportRetryWaitIsCanceled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	_, err = testTransport(4).Do(req)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want the context deadline", err)
+	}
+	if waited := time.Since(start); waited > 5*time.Second {
+		t.Errorf("waited %s for a canceled retry", waited)
+	}
+}
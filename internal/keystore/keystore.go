@@ -0,0 +1,257 @@
+// Package keystore keeps the credentials created by "g2o auth login" in an
+// encrypted file under ~/.g2o, one entry per profile.
+//
+// The file is sealed with AES-256-GCM. The key is derived from
+// $G2O_KEYSTORE_PASSPHRASE when it is set, and is otherwise a random key
+// kept next to the keystore in a file only the user can read. The latter
+// keeps tokens out of plain-text backups and dotfile repositories but is no
+// protection against someone who can read the user's home directory.
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Kinds of stored credentials.
+const (
+	KindPAT   = "pat"
+	KindOAuth = "oauth"
+)
+
+const (
+	keystoreFile = "keystore"
+	keyFile      = "keystore.key"
+
+	kdfKeyFile = "keyfile"
+	kdfPBKDF2  = "pbkdf2-sha256"
+
+	pbkdf2Iterations = 600_000
+)
+
+var (
+	ErrNotFound         = fmt.Errorf("no stored credential")
+	ErrPassphrase       = fmt.Errorf("the keystore is protected by a passphrase; set G2O_KEYSTORE_PASSPHRASE")
+	ErrDecryptionFailed = fmt.Errorf("cannot decrypt the keystore (wrong passphrase or key?)")
+)
+
+// Credential is what "auth login" stores for a profile.
+type Credential struct {
+	Kind         string    `json:"kind"`
+	Host         string    `json:"host"`
+	Username     string    `json:"username,omitempty"`
+	Token        string    `json:"token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Expiry       time.Time `json:"expiry,omitzero"`
+	ClientID     string    `json:"client_id,omitempty"`
+	Scopes       []string  `json:"scopes,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// sealed is the on-disk form of the keystore.
+type sealed struct {
+	Version int    `json:"version"`
+	KDF     string `json:"kdf"`
+	Salt    []byte `json:"salt,omitempty"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// Keystore is the encrypted credentials file in a directory.
+type Keystore struct {
+	dir string
+}
+
+// New returns the keystore in dir, normally config.Dir(). Nothing is read
+// until it is used.
+func New(dir string) *Keystore {
+	return &Keystore{dir: dir}
+}
+
+// Path returns the keystore file.
+func (k *Keystore) Path() string {
+	return filepath.Join(k.dir, keystoreFile)
+}
+
+// Get returns the credential stored for profile.
+func (k *Keystore) Get(profile string) (Credential, error) {
+	creds, err := k.load()
+	if err != nil {
+		return Credential{}, err
+	}
+	c, ok := creds[profile]
+	if !ok {
+		return Credential{}, ErrNotFound
+	}
+	return c, nil
+}
+
+// Put stores c for profile, replacing any earlier credential.
+func (k *Keystore) Put(profile string, c Credential) error {
+	creds, err := k.load()
+	if err != nil {
+		return err
+	}
+	creds[profile] = c
+	return k.save(creds)
+}
+
+// Delete removes the credential of profile.
+func (k *Keystore) Delete(profile string) error {
+	creds, err := k.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := creds[profile]; !ok {
+		return ErrNotFound
+	}
+	delete(creds, profile)
+	return k.save(creds)
+}
+
+func (k *Keystore) load() (map[string]Credential, error) {
+	creds := map[string]Credential{}
+	data, err := os.ReadFile(k.Path())
+	if errors.Is(err, os.ErrNotExist) {
+		return creds, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var s sealed
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("read keystore: %w", err)
+	}
+	if s.Version != 1 {
+		return nil, fmt.Errorf("unsupported keystore version %d", s.Version)
+	}
+	key, err := k.key(s.KDF, s.Salt, false)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	// Open panics on a nonce of the wrong size rather than failing.
+	if len(s.Nonce) != gcm.NonceSize() {
+		return nil, ErrDecryptionFailed
+	}
+	plain, err := gcm.Open(nil, s.Nonce, s.Data, []byte(s.KDF))
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+	if err := json.Unmarshal(plain, &creds); err != nil {
+		return nil, fmt.Errorf("read keystore: %w", err)
+	}
+	return creds, nil
+}
+
+// save re-encrypts the whole keystore with a fresh nonce and replaces the
+// file atomically.
+func (k *Keystore) save(creds map[string]Credential) error {
+	plain, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+	s := sealed{Version: 1, KDF: kdfKeyFile}
+	if os.Getenv("G2O_KEYSTORE_PASSPHRASE") != "" {
+		s.KDF = kdfPBKDF2
+		s.Salt = make([]byte, 16)
+		if _, err := rand.Read(s.Salt); err != nil {
+			return err
+		}
+	}
+	key, err := k.key(s.KDF, s.Salt, true)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	s.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(s.Nonce); err != nil {
+		return err
+	}
+	s.Data = gcm.Seal(nil, s.Nonce, plain, []byte(s.KDF))
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return writePrivate(k.Path(), data)
+}
+
+// key returns the encryption key for kdf, creating the key file when
+// create is set and there is none yet.
+func (k *Keystore) key(kdf string, salt []byte, create bool) ([]byte, error) {
+	switch kdf {
+	case kdfPBKDF2:
+		pass := os.Getenv("G2O_KEYSTORE_PASSPHRASE")
+		if pass == "" {
+			return nil, ErrPassphrase
+		}
+		return pbkdf2.Key(sha256.New, pass, salt, pbkdf2Iterations, 32)
+	case kdfKeyFile:
+		path := filepath.Join(k.dir, keyFile)
+		key, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) && create {
+			key = make([]byte, 32)
+			if _, err := rand.Read(key); err != nil {
+				return nil, err
+			}
+			return key, writePrivate(path, key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read keystore key: %w", err)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("keystore key %s is corrupt", path)
+		}
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported keystore kdf %q", kdf)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// writePrivate writes data to path readable only by the user, via a
+// temporary file so a crash cannot leave it half written.
+func writePrivate(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".keystore-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if err := f.Chmod(0o600); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,256 @@
+package keystore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func testCredential() Credential {
+	return Credential{
+		Kind:      KindPAT,
+		Host:      "gitlab.example.com",
+		Username:  "alice",
+		Token:     "glpat-secret",
+		Scopes:    []string{"api"},
+		CreatedAt: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestKeyfileRoundTrip(t *testing.T) {
+	t.Setenv("G2O_KEYSTORE_PASSPHRASE", "")
+	// The directory does not exist yet, as on a first login.
+	dir := filepath.Join(t.TempDir(), "g2o")
+	k := New(dir)
+
+	if _, err := k.Get("work"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get on an empty keystore: got %v, want ErrNotFound", err)
+	}
+	want := testCredential()
+	if err := k.Put("work", want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := New(dir).Get("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Token != want.Token || got.Username != want.Username || !got.CreatedAt.Equal(want.CreatedAt) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	for _, name := range []string{keystoreFile, keyFile} {
+		fi, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mode := fi.Mode().Perm(); mode != 0o600 {
+			t.Errorf("%s has mode %v, want 0600", name, mode)
+		}
+	}
+	if key, err := os.ReadFile(filepath.Join(dir, keyFile)); err != nil || len(key) != 32 {
+		t.Errorf("key file holds %d bytes (%v), want 32", len(key), err)
+	}
+	data, err := os.ReadFile(k.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte(want.Token)) {
+		t.Error("keystore holds the token in plain text")
+	}
+
+	if err := k.Delete("work"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := k.Get("work"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete: got %v, want ErrNotFound", err)
+	}
+}
+
+func TestPassphraseRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	k := New(dir)
+	t.Setenv("G2O_KEYSTORE_PASSPHRASE", "correct horse")
+	if err := k.Put("work", testCredential()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, keyFile)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("passphrase mode created a key file (%v)", err)
+	}
+	if got, err := k.Get("work"); err != nil || got.Token != testCredential().Token {
+		t.Fatalf("Get = %+v, %v", got, err)
+	}
+
+	t.Setenv("G2O_KEYSTORE_PASSPHRASE", "wrong horse")
+	if _, err := k.Get("work"); !errors.Is(err, ErrDecryptionFailed) {
+		t.Errorf("wrong passphrase: got %v, want ErrDecryptionFailed", err)
+	}
+	t.Setenv("G2O_KEYSTORE_PASSPHRASE", "")
+	if _, err := k.Get("work"); !errors.Is(err, ErrPassphrase) {
+		t.Errorf("no passphrase: got %v, want ErrPassphrase", err)
+	}
+}
+
+func TestDamagedKeystore(t *testing.T) {
+	t.Setenv("G2O_KEYSTORE_PASSPHRASE", "")
+	tests := []struct {
+		name   string
+		damage func(s *sealed)
+	}{
+		{"flipped bit", func(s *sealed) { s.Data[0] ^= 1 }},
+		{"truncated data", func(s *sealed) { s.Data = s.Data[:len(s.Data)-1] }},
+		{"no data", func(s *sealed) { s.Data = nil }},
+		{"short nonce", func(s *sealed) { s.Nonce = s.Nonce[:4] }},
+		{"no nonce", func(s *sealed) { s.Nonce = nil }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := New(t.TempDir())
+			if err := k.Put("work", testCredential()); err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(k.Path())
+			if err != nil {
+				t.Fatal(err)
+			}
+			var s sealed
+			if err := json.Unmarshal(data, &s); err != nil {
+				t.Fatal(err)
+			}
+			tt.damage(&s)
+			if data, err = json.Marshal(s); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(k.Path(), data, 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := k.Get("work"); !errors.Is(err, ErrDecryptionFailed) {
+				t.Errorf("got %v, want ErrDecryptionFailed", err)
+			}
+		})
+	}
+}
+
+func TestDamagedFiles(t *testing.T) {
+	t.Setenv("G2O_KEYSTORE_PASSPHRASE", "")
+	tests := []struct {
+		name string
+		file string
+		keep int // bytes of the file kept
+		want string
+	}{
+		{"truncated keystore", keystoreFile, 20, "read keystore"},
+		{"truncated key", keyFile, 16, "is corrupt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			k := New(dir)
+			if err := k.Put("work", testCredential()); err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(dir, tt.file)
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, data[:tt.keep], 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := k.Get("work"); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// tokenServer is an OAuth token endpoint that rotates the refresh token on
+// every refresh, as GitLab does.
+func tokenServer(t *testing.T, refreshes *atomic.Int64) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/oauth/token" || r.FormValue("grant_type") != "refresh_token" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		n := refreshes.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token":  fmt.Sprintf("access-%d", n),
+			"refresh_token": fmt.Sprintf("refresh-%d", n),
+			"token_type":    "Bearer",
+			"expires_in":    7200,
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestTokenSourceSavesRotatedToken(t *testing.T) {
+	t.Setenv("G2O_KEYSTORE_PASSPHRASE", "")
+	var refreshes atomic.Int64
+	srv := tokenServer(t, &refreshes)
+
+	k := New(t.TempDir())
+	c := Credential{Kind: KindOAuth, Host: "gitlab.example.com", Token: "access-0", RefreshToken: "refresh-0",
+		Expiry: time.Now().Add(-time.Minute), ClientID: "app"}
+	if err := k.Put("work", c); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := k.TokenSource(srv.Client(), srv.URL, "work", c)
+	for range 2 {
+		tok, err := ts.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tok.AccessToken != "access-1" {
+			t.Errorf("got access token %q, want the refreshed access-1", tok.AccessToken)
+		}
+	}
+	if n := refreshes.Load(); n != 1 {
+		t.Errorf("refreshed %d times, want once", n)
+	}
+
+	stored, err := k.Get("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Token != "access-1" || stored.RefreshToken != "refresh-1" || !stored.Expiry.After(time.Now()) {
+		t.Errorf("stored %+v, want the rotated tokens saved", stored)
+	}
+}
+
+func TestTokenSourceRefreshIsCanceled(t *testing.T) {
+	t.Setenv("G2O_KEYSTORE_PASSPHRASE", "")
+	var refreshes atomic.Int64
+	srv := tokenServer(t, &refreshes)
+
+	k := New(t.TempDir())
+	c := Credential{Kind: KindOAuth, Token: "access-0", RefreshToken: "refresh-0", Expiry: time.Now().Add(-time.Minute)}
+	ts := k.TokenSource(srv.Client(), srv.URL, "work", c).(interface {
+		TokenContext(context.Context) (*oauth2.Token, error)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ts.TokenContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want context.Canceled", err)
+	}
+	if n := refreshes.Load(); n != 0 {
+		t.Errorf("refreshed %d times after the context was canceled", n)
+	}
+	if _, err := k.Get("work"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("canceled refresh stored a credential (%v)", err)
+	}
+}
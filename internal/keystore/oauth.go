@@ -0,0 +1,149 @@
+package keystore
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// DefaultScopes are requested by the device flow; g2o reads and writes
+// issues, so it needs the full API scope.
+var DefaultScopes = []string{"api"}
+
+// OAuthConfig returns the OAuth 2.0 endpoints of the instance at baseURL
+// (gitlab.com when empty) for the application clientID.
+func OAuthConfig(baseURL, clientID string, scopes []string) *oauth2.Config {
+	if baseURL == "" {
+		baseURL = "https://gitlab.com"
+	}
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	return &oauth2.Config{
+		ClientID: clientID,
+		Scopes:   scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:       baseURL + "/oauth/authorize",
+			DeviceAuthURL: baseURL + "/oauth/authorize_device",
+			TokenURL:      baseURL + "/oauth/token",
+			AuthStyle:     oauth2.AuthStyleInParams,
+		},
+	}
+}
+
+// DeviceLogin runs the device authorization flow: prompt is called with the
+// verification URL and user code to show, then DeviceLogin waits until the
+// user has approved the request in a browser or ctx is done.
+func DeviceLogin(ctx context.Context, hc *http.Client, cfg *oauth2.Config, prompt func(*oauth2.DeviceAuthResponse)) (*oauth2.Token, error) {
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, hc)
+	da, err := cfg.DeviceAuth(ctx)
+	if err != nil {
+		return nil, err
+	}
+	prompt(da)
+	return cfg.DeviceAccessToken(ctx, da)
+}
+
+// FromToken turns an OAuth token into a credential for host.
+func FromToken(host, clientID string, scopes []string, t *oauth2.Token) Credential {
+	return Credential{
+		Kind:         KindOAuth,
+		Host:         host,
+		Token:        t.AccessToken,
+		RefreshToken: t.RefreshToken,
+		Expiry:       t.Expiry,
+		ClientID:     clientID,
+		Scopes:       scopes,
+		CreatedAt:    time.Now().UTC(),
+	}
+}
+
+// TokenSource returns a token source for an OAuth credential of profile
+// that refreshes the access token when it expires and writes the new
+// tokens back to the keystore. GitLab rotates refresh tokens, so a refresh
+// that is not saved would lock the profile out. hc makes the refresh
+// requests; the source also has a TokenContext method, which the GitLab
+// client calls with the request's context so a refresh can be cancelled.
+func (k *Keystore) TokenSource(hc *http.Client, baseURL, profile string, c Credential) oauth2.TokenSource {
+	return &savingTokenSource{
+		cfg:     OAuthConfig(baseURL, c.ClientID, c.Scopes),
+		hc:      hc,
+		ks:      k,
+		profile: profile,
+		tok:     &oauth2.Token{AccessToken: c.Token, RefreshToken: c.RefreshToken, Expiry: c.Expiry, TokenType: "Bearer"},
+		cred:    c,
+	}
+}
+
+// savingTokenSource hands out the access token of a stored credential,
+// refreshing it when it has expired and persisting every new token.
+type savingTokenSource struct {
+	cfg     *oauth2.Config
+	hc      *http.Client
+	ks      *Keystore
+	profile string
+
+	// mu is held across a refresh, so concurrent requests wait for one
+	// refresh rather than each spending the same refresh token.
+	mu   sync.Mutex
+	tok  *oauth2.Token
+	cred Credential
+}
+
+// Token returns a valid access token, refreshing it without a deadline.
+func (s *savingTokenSource) Token() (*oauth2.Token, error) {
+	return s.TokenContext(context.Background())
+}
+
+// TokenContext is Token with the refresh, if one is due, made within ctx,
+// normally that of the request needing the token, so Ctrl-C cancels it.
+func (s *savingTokenSource) TokenContext(ctx context.Context) (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tok.Valid() {
+		return s.tok, nil
+	}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, s.hc)
+	t, err := s.cfg.TokenSource(ctx, s.tok).Token()
+	if err != nil {
+		return nil, err
+	}
+	if t.AccessToken != s.cred.Token {
+		s.cred.Token, s.cred.Expiry = t.AccessToken, t.Expiry
+		if t.RefreshToken != "" {
+			s.cred.RefreshToken = t.RefreshToken
+		}
+		if err := s.ks.Put(s.profile, s.cred); err != nil {
+			return nil, fmt.Errorf("save refreshed token: %w", err)
+		}
+	}
+	s.tok = t
+	return t, nil
+}
+
+// Revoke invalidates an OAuth credential on the server, so logging out does
+// not leave a working token behind.
+func Revoke(ctx context.Context, hc *http.Client, baseURL string, c Credential) error {
+	if baseURL == "" {
+		baseURL = "https://gitlab.com"
+	}
+	form := url.Values{"client_id": {c.ClientID}, "token": {c.Token}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(baseURL, "/")+"/oauth/revoke", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("revoke token: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package keystore
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/chazzychouse/g2o/internal/config"
+	"golang.org/x/oauth2"
+)
+
+// Auth is how a profile authenticates: with a fixed token or with an OAuth
+// token source that refreshes itself.
+type Auth struct {
+	Token       string
+	TokenSource oauth2.TokenSource
+	Source      string // where the credential came from, e.g. "keystore (oauth)"
+	Credential  *Credential
+}
+
+// Resolve finds the credential of p. A token source named in the config
+// file wins, then a credential from "auth login", then $GITLAB_TOKEN. hc is
+// used to refresh OAuth tokens.
+func (k *Keystore) Resolve(p config.Profile, hc *http.Client) (Auth, error) {
+	if src := p.TokenSourceName(); src != "" {
+		token, err := p.ReadToken()
+		return Auth{Token: token, Source: src}, err
+	}
+
+	c, err := k.Get(p.Name)
+	switch {
+	case err == nil && c.Kind == KindOAuth:
+		return Auth{TokenSource: k.TokenSource(hc, p.BaseURL, p.Name, c), Source: "keystore (oauth)", Credential: &c}, nil
+	case err == nil:
+		return Auth{Token: c.Token, Source: "keystore (personal access token)", Credential: &c}, nil
+	case !errors.Is(err, ErrNotFound):
+		return Auth{}, err
+	}
+
+	if _, ok := os.LookupEnv("GITLAB_TOKEN"); ok {
+		token, err := p.ReadToken()
+		return Auth{Token: token, Source: "$GITLAB_TOKEN"}, err
+	}
+	return Auth{}, fmt.Errorf("%w: run 'g2o auth login' or set GITLAB_TOKEN", config.ErrNoToken)
+}
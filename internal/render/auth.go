@@ -0,0 +1,71 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/chazzychouse/g2o/internal/styles"
+)
+
+// AuthInfo is the login state of one profile as shown by "auth status".
+type AuthInfo struct {
+	Profile  string    `json:"profile"`
+	Host     string    `json:"host"`
+	Source   string    `json:"source,omitempty"`
+	Kind     string    `json:"kind,omitempty"`
+	Username string    `json:"username,omitempty"`
+	Scopes   []string  `json:"scopes,omitempty"`
+	Expiry   time.Time `json:"expiry,omitzero"`
+	Error    string    `json:"error,omitempty"`
+}
+
+var authView = View[AuthInfo]{
+	Columns: []Column[AuthInfo]{
+		{Header: "profile", Value: func(a AuthInfo) string { return a.Profile }},
+		{Header: "host", Value: func(a AuthInfo) string { return a.Host }},
+		{Header: "username", Value: func(a AuthInfo) string { return a.Username }},
+		{Header: "source", Value: func(a AuthInfo) string { return a.Source }},
+		{Header: "kind", Value: func(a AuthInfo) string { return a.Kind }, Detail: true},
+		{Header: "scopes", Value: func(a AuthInfo) string { return strings.Join(a.Scopes, ",") }, Detail: true},
+		{Header: "expiry", Value: func(a AuthInfo) string { return fmtTime(a.Expiry) }, Detail: true},
+		{Header: "error", Value: func(a AuthInfo) string { return a.Error }},
+	},
+	Plain: func(a AuthInfo) string {
+		head := styles.Title.Render(a.Profile) + " " + styles.Label.Render(a.Host)
+		if a.Error != "" {
+			return head + "\n  " + styles.Error.Render("✗ "+a.Error)
+		}
+		lines := []string{head,
+			"  " + styles.Success.Render("✓ logged in as @"+a.Username) + styles.Label.Render(" via "+a.Source)}
+		if len(a.Scopes) > 0 {
+			lines = append(lines, "  "+styles.Label.Render("scopes: ")+styles.Value.Render(strings.Join(a.Scopes, ", ")))
+		}
+		switch {
+		case a.Kind == "oauth":
+			lines = append(lines, "  "+styles.Label.Render("access token refreshes automatically"))
+		case !a.Expiry.IsZero():
+			lines = append(lines, "  "+styles.Label.Render("expires: ")+styles.Value.Render(a.Expiry.Format(time.DateOnly)))
+		}
+		return strings.Join(lines, "\n")
+	},
+}
+
+// AuthStatus writes the login state of each profile.
+func AuthStatus(w io.Writer, f Format, infos []AuthInfo) error {
+	if f != Plain {
+		return authView.List(w, f, infos)
+	}
+	for i, a := range infos {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w, authView.Plain(a)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/chazzychouse/g2o/internal/store"
+	"github.com/chazzychouse/g2o/internal/styles"
+)
+
+// DashboardSummary is the current user's work as found in the store: the
+// issues assigned to them by state and due date, their merge requests, and
+// what changed since they last looked.
+type DashboardSummary struct {
+	User      store.StoreUser `json:"user"`
+	LastSeen  time.Time       `json:"last_seen,omitzero"` // previous look; zero the first time
+	Today     string          `json:"today"`
+	Open      int64           `json:"open_issues"`
+	Closed    int64           `json:"closed_issues"`
+	DueLater  int64           `json:"due_later"` // open, due after DueSoon
+	NoDueDate int64           `json:"no_due_date"`
+
+	Overdue              []store.StoreIssue        `json:"overdue"`
+	DueSoon              []store.StoreIssue        `json:"due_soon"` // open, due within a week
+	Updated              []store.StoreIssue        `json:"updated_issues"`
+	MergeRequests        MergeRequestCounts        `json:"merge_requests"`
+	UpdatedMergeRequests []store.StoreMergeRequest `json:"updated_merge_requests"`
+	Projects             []ProjectCounts           `json:"projects"`
+	Todos                []TodoCount               `json:"todos"` // pending, by action
+}
+
+// TodoCount is how many pending to-do items share an action, such as
+// "review_requested".
+type TodoCount struct {
+	Action string `json:"action"`
+	Count  int64  `json:"count"`
+}
+
+// MergeRequestCounts counts the current user's open merge requests by
+// their part in them. A merge request can count more than once.
+type MergeRequestCounts struct {
+	Authored  int64 `json:"authored"`
+	Assigned  int64 `json:"assigned"`
+	Reviewing int64 `json:"reviewing"`
+}
+
+// ProjectCounts is how much of the current user's open work is in one
+// project.
+type ProjectCounts struct {
+	ID            int64  `json:"id"`
+	Path          string `json:"path"`
+	OpenIssues    int64  `json:"open_issues"`
+	MergeRequests int64  `json:"merge_requests"`
+}
+
+var dashboardView = View[DashboardSummary]{
+	Columns: []Column[DashboardSummary]{
+		{Header: "user", Value: func(d DashboardSummary) string { return d.User.Username }},
+		{Header: "open", Value: func(d DashboardSummary) string { return itoa(d.Open) }},
+		{Header: "closed", Value: func(d DashboardSummary) string { return itoa(d.Closed) }},
+		{Header: "overdue", Value: func(d DashboardSummary) string { return itoa(int64(len(d.Overdue))) }},
+		{Header: "due_soon", Value: func(d DashboardSummary) string { return itoa(int64(len(d.DueSoon))) }},
+		{Header: "due_later", Value: func(d DashboardSummary) string { return itoa(d.DueLater) }, Detail: true},
+		{Header: "no_due_date", Value: func(d DashboardSummary) string { return itoa(d.NoDueDate) }, Detail: true},
+		{Header: "updated", Value: func(d DashboardSummary) string { return itoa(int64(len(d.Updated) + len(d.UpdatedMergeRequests))) }},
+		{Header: "mrs_authored", Value: func(d DashboardSummary) string { return itoa(d.MergeRequests.Authored) }},
+		{Header: "mrs_reviewing", Value: func(d DashboardSummary) string { return itoa(d.MergeRequests.Reviewing) }},
+		{Header: "todos", Value: func(d DashboardSummary) string { return itoa(pendingTodos(d.Todos)) }},
+		{Header: "mrs_assigned", Value: func(d DashboardSummary) string { return itoa(d.MergeRequests.Assigned) }, Detail: true},
+		{Header: "last_seen", Value: func(d DashboardSummary) string { return fmtTime(d.LastSeen) }, Detail: true},
+	},
+	Plain: plainDashboard,
+}
+
+// Dashboard writes the current user's summary as panels of counts over
+// lists of overdue, soon due and updated items. Table and CSV output are a
+// single row of counts.
+func Dashboard(w io.Writer, f Format, d DashboardSummary) error {
+	for _, l := range []*[]store.StoreIssue{&d.Overdue, &d.DueSoon, &d.Updated} {
+		if *l == nil {
+			*l = []store.StoreIssue{}
+		}
+	}
+	if d.UpdatedMergeRequests == nil {
+		d.UpdatedMergeRequests = []store.StoreMergeRequest{}
+	}
+	if d.Projects == nil {
+		d.Projects = []ProjectCounts{}
+	}
+	if d.Todos == nil {
+		d.Todos = []TodoCount{}
+	}
+	return dashboardView.One(w, f, d)
+}
+
+const (
+	dashboardItems    = 8  // most entries in each list
+	dashboardProjects = 6  // most rows in the projects panel
+	dashboardTitle    = 60 // longest title shown, in runes
+)
+
+func plainDashboard(d DashboardSummary) string {
+	var b strings.Builder
+	b.WriteString(styles.Title.Render("Dashboard") + " " + styles.Value.Render("@"+d.User.Username))
+	if d.User.Name != "" {
+		b.WriteString(styles.Label.Render(" · " + d.User.Name))
+	}
+	if d.LastSeen.IsZero() {
+		b.WriteString(styles.Label.Render("  first look"))
+	} else {
+		b.WriteString(styles.Label.Render("  last look " + d.LastSeen.Local().Format("2006-01-02 15:04")))
+	}
+	b.WriteString("\n")
+
+	panels := []string{styles.Panel.Render(issuePanel(d)), styles.Panel.Render(mergeRequestPanel(d.MergeRequests))}
+	if len(d.Todos) > 0 {
+		panels = append(panels, styles.Panel.Render(todoPanel(d.Todos)))
+	}
+	if len(d.Projects) > 0 {
+		panels = append(panels, styles.Panel.Render(projectPanel(d.Projects)))
+	}
+	spaced := make([]string, 0, 2*len(panels))
+	for i, p := range panels {
+		if i > 0 {
+			spaced = append(spaced, " ")
+		}
+		spaced = append(spaced, p)
+	}
+	// Shorter panels are padded out to the tallest; the padding is trimmed.
+	for _, line := range strings.Split(lipgloss.JoinHorizontal(lipgloss.Top, spaced...), "\n") {
+		b.WriteString(strings.TrimRight(line, " ") + "\n")
+	}
+
+	section := func(title string, lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+		b.WriteString("\n" + styles.Title.Render(title) + "\n")
+		for _, l := range lines {
+			b.WriteString("  " + l + "\n")
+		}
+	}
+	var overdue, soon []string
+	for _, i := range limit(d.Overdue, dashboardItems) {
+		overdue = append(overdue, dashboardIssue(i)+"  "+styles.Error.Render("due "+i.DueDate))
+	}
+	section(fmt.Sprintf("Overdue: %d", len(d.Overdue)), overdue)
+	for _, i := range limit(d.DueSoon, dashboardItems) {
+		soon = append(soon, dashboardIssue(i)+"  "+styles.Match.Render("due "+i.DueDate))
+	}
+	section(fmt.Sprintf("Due this week: %d", len(d.DueSoon)), soon)
+
+	if !d.LastSeen.IsZero() {
+		var updated []string
+		for _, i := range limit(d.Updated, dashboardItems) {
+			updated = append(updated, dashboardIssue(i)+styles.Label.Render("  "+i.State))
+		}
+		for _, m := range limit(d.UpdatedMergeRequests, dashboardItems) {
+			updated = append(updated, styles.Label.Render(projectPath(m.WebURL, m.ProjectID)+"!"+itoa(m.IID))+" "+
+				styles.Value.Render(clip(m.Title, dashboardTitle))+styles.Label.Render("  "+m.State))
+		}
+		n := len(d.Updated) + len(d.UpdatedMergeRequests)
+		if n == 0 {
+			updated = []string{styles.Label.Render("nothing")}
+		}
+		section(fmt.Sprintf("Updated since last look: %d", n), updated)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func issuePanel(d DashboardSummary) string {
+	overdue := itoa(int64(len(d.Overdue)))
+	if len(d.Overdue) > 0 {
+		overdue = styles.Error.Render(overdue)
+	}
+	return panel("Issues", [][2]string{
+		{"open", itoa(d.Open)},
+		{"closed", itoa(d.Closed)},
+		{"overdue", overdue},
+		{"due this week", itoa(int64(len(d.DueSoon)))},
+		{"due later", itoa(d.DueLater)},
+		{"no due date", itoa(d.NoDueDate)},
+	})
+}
+
+func mergeRequestPanel(c MergeRequestCounts) string {
+	return panel("Merge requests", [][2]string{
+		{"authored", itoa(c.Authored)},
+		{"assigned", itoa(c.Assigned)},
+		{"to review", itoa(c.Reviewing)},
+	})
+}
+
+func todoPanel(counts []TodoCount) string {
+	rows := make([][2]string, len(counts))
+	for i, c := range counts {
+		rows[i] = [2]string{todoAction(c.Action), itoa(c.Count)}
+	}
+	return panel(fmt.Sprintf("To-do: %d", pendingTodos(counts)), rows)
+}
+
+func pendingTodos(counts []TodoCount) int64 {
+	var n int64
+	for _, c := range counts {
+		n += c.Count
+	}
+	return n
+}
+
+func projectPanel(projects []ProjectCounts) string {
+	rows := make([][2]string, 0, dashboardProjects+1)
+	for _, p := range limit(projects, dashboardProjects) {
+		var v []string
+		if p.OpenIssues > 0 {
+			v = append(v, plural(p.OpenIssues, "issue"))
+		}
+		if p.MergeRequests > 0 {
+			v = append(v, plural(p.MergeRequests, "MR"))
+		}
+		rows = append(rows, [2]string{p.Path, strings.Join(v, ", ")})
+	}
+	if n := len(projects) - dashboardProjects; n > 0 {
+		rows = append(rows, [2]string{fmt.Sprintf("and %d more", n), ""})
+	}
+	return panel("Projects", rows)
+}
+
+// panel lays out a titled list of label/value rows with the labels
+// padded to a common width.
+func panel(title string, rows [][2]string) string {
+	width := 0
+	for _, r := range rows {
+		width = max(width, lipgloss.Width(r[0]))
+	}
+	lines := []string{styles.Title.Render(title)}
+	for _, r := range rows {
+		pad := strings.Repeat(" ", width-lipgloss.Width(r[0]))
+		lines = append(lines, styles.Label.Render(r[0]+pad)+"  "+styles.Value.Render(r[1]))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func dashboardIssue(i store.StoreIssue) string {
+	return styles.Label.Render(projectPath(i.WebURL, i.ProjectID)+"#"+itoa(i.IID)) + " " +
+		styles.Value.Render(clip(i.Title, dashboardTitle))
+}
+
+func plural(n int64, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return itoa(n) + " " + noun + "s"
+}
+
+// clip shortens s to at most n runes, marking the cut with an ellipsis.
+func clip(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+func limit[T any](items []T, n int) []T {
+	if len(items) > n {
+		return items[:n]
+	}
+	return items
+}
@@ -0,0 +1,36 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/chazzychouse/g2o/internal/store"
+	"github.com/chazzychouse/g2o/internal/styles"
+)
+
+var groupView = View[store.StoreGroup]{
+	Title: "Groups",
+	Columns: []Column[store.StoreGroup]{
+		{Header: "id", Value: func(g store.StoreGroup) string { return itoa(g.ID) }},
+		{Header: "name", Value: func(g store.StoreGroup) string { return g.Name }},
+		{Header: "full_path", Value: func(g store.StoreGroup) string { return g.FullPath }},
+		{Header: "visibility", Value: func(g store.StoreGroup) string { return g.Visibility }},
+		{Header: "parent_id", Value: func(g store.StoreGroup) string { return itoa(g.ParentID) }, Detail: true},
+		{Header: "web_url", Value: func(g store.StoreGroup) string { return g.WebURL }, Detail: true},
+	},
+	Plain: func(g store.StoreGroup) string {
+		return fmt.Sprintf("%s %s\n%s %s\n",
+			styles.Label.Render("name: "),
+			styles.Value.Render(g.Name),
+			styles.Label.Render("gid:  "),
+			styles.Value.Render(strconv.FormatInt(g.ID, 10)))
+	},
+}
+
+// Groups writes a group listing.
+func Groups(w io.Writer, f Format, groups []store.StoreGroup) error {
+	return groupView.List(w, f, groups)
+}
+
+func itoa(n int64) string { return strconv.FormatInt(n, 10) }
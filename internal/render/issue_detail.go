@@ -0,0 +1,160 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/chazzychouse/g2o/internal/store"
+	"github.com/chazzychouse/g2o/internal/styles"
+)
+
+// IssueDetail is an issue together with its discussion and linked issues.
+type IssueDetail struct {
+	store.StoreIssue
+	Links []store.StoreIssueLink `json:"links"`
+	Notes []store.StoreIssueNote `json:"notes"`
+}
+
+// Issue writes a single issue. Plain output is a detail view with the
+// description rendered as Markdown followed by linked issues and notes;
+// JSON output includes the notes and links, and table and CSV output
+// carry only the issue's own fields.
+func Issue(w io.Writer, f Format, d IssueDetail) error {
+	switch f {
+	case JSON, NDJSON:
+		if d.Links == nil {
+			d.Links = []store.StoreIssueLink{}
+		}
+		if d.Notes == nil {
+			d.Notes = []store.StoreIssueNote{}
+		}
+		enc := json.NewEncoder(w)
+		if f == JSON {
+			enc.SetIndent("", "  ")
+		}
+		return enc.Encode(d)
+	case Table, CSV:
+		return issueView.List(w, f, []store.StoreIssue{d.StoreIssue})
+	}
+
+	i := d.StoreIssue
+	fmt.Fprintln(w, styles.Title.Render(i.Title))
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(w, "%s %s\n", styles.Label.Render(fmt.Sprintf("%-13s", name+":")), styles.Value.Render(value))
+		}
+	}
+	field("reference", projectPath(i.WebURL, i.ProjectID)+"#"+itoa(i.IID))
+	state := i.State
+	if i.Confidential {
+		state += ", confidential"
+	}
+	field("state", state)
+	if i.AuthorUsername != "" {
+		field("author", "@"+i.AuthorUsername)
+	}
+	field("assignees", mentions(i.Assignees))
+	field("labels", strings.Join(i.Labels, ", "))
+	field("milestone", i.MilestoneTitle)
+	field("due", i.DueDate)
+	if i.Weight > 0 {
+		field("weight", itoa(i.Weight))
+	}
+	field("time", timeTracking(i.TimeSpent, i.TimeEstimate))
+	field("created", fmtTime(i.CreatedAt))
+	field("updated", fmtTime(i.UpdatedAt))
+	field("closed", fmtTime(i.ClosedAt))
+	field("url", i.WebURL)
+
+	if i.Description != "" {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, Markdown(i.Description, markdownWidth))
+	}
+
+	if len(d.Links) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, styles.Title.Render(fmt.Sprintf("Linked issues: %d", len(d.Links))))
+		for _, l := range d.Links {
+			ref := l.Reference
+			if ref == "" {
+				ref = projectPath(l.WebURL, l.ProjectID) + "#" + itoa(l.IID)
+			}
+			fmt.Fprintf(w, "  %s %s %s\n",
+				styles.Label.Render(fmt.Sprintf("%-14s", strings.ReplaceAll(l.LinkType, "_", " "))),
+				styles.Value.Render(l.Title),
+				styles.Label.Render("("+ref+", "+l.State+")"))
+		}
+	}
+
+	if len(d.Notes) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, styles.Title.Render(fmt.Sprintf("Notes: %d", len(d.Notes))))
+		for _, n := range d.Notes {
+			when := n.CreatedAt.Local().Format("2006-01-02 15:04")
+			if n.System {
+				// System notes ("changed the description", "added ~bug label")
+				// are one-line activity entries.
+				fmt.Fprintln(w, styles.Label.Render(fmt.Sprintf("  · @%s %s  %s", n.AuthorUsername, firstLine(n.Body), when)))
+				continue
+			}
+			header := styles.Value.Render("@"+n.AuthorUsername) + " " + styles.Label.Render(when)
+			if n.Internal {
+				header += " " + styles.Label.Render("(internal)")
+			}
+			fmt.Fprintln(w)
+			fmt.Fprintln(w, header)
+			for _, line := range strings.Split(Markdown(n.Body, markdownWidth-4), "\n") {
+				fmt.Fprintln(w, "    "+line)
+			}
+		}
+	}
+	return nil
+}
+
+// timeTracking summarises spent and estimated time, e.g. "3h 30m of 1d".
+func timeTracking(spent, estimate int64) string {
+	switch {
+	case spent == 0 && estimate == 0:
+		return ""
+	case estimate == 0:
+		return duration(spent) + " spent"
+	default:
+		return duration(spent) + " of " + duration(estimate)
+	}
+}
+
+// duration formats seconds the way GitLab's time tracking does, where a day
+// is 8 hours and a week 5 days.
+func duration(seconds int64) string {
+	d := time.Duration(seconds) * time.Second
+	units := []struct {
+		suffix string
+		size   time.Duration
+	}{
+		{"w", 40 * time.Hour},
+		{"d", 8 * time.Hour},
+		{"h", time.Hour},
+		{"m", time.Minute},
+	}
+	var parts []string
+	for _, u := range units {
+		if n := d / u.size; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d%s", n, u.suffix))
+			d -= n * u.size
+		}
+	}
+	if len(parts) == 0 {
+		return "0m"
+	}
+	return strings.Join(parts, " ")
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
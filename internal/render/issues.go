@@ -0,0 +1,86 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chazzychouse/g2o/internal/store"
+	"github.com/chazzychouse/g2o/internal/styles"
+)
+
+var issueView = View[store.StoreIssue]{
+	Title: "Issues",
+	Columns: []Column[store.StoreIssue]{
+		{Header: "id", Value: func(i store.StoreIssue) string { return itoa(i.ID) }, Detail: true},
+		{Header: "project_id", Value: func(i store.StoreIssue) string { return itoa(i.ProjectID) }},
+		{Header: "iid", Value: func(i store.StoreIssue) string { return itoa(i.IID) }},
+		{Header: "state", Value: func(i store.StoreIssue) string { return i.State }},
+		{Header: "title", Value: func(i store.StoreIssue) string { return i.Title }},
+		{Header: "labels", Value: func(i store.StoreIssue) string { return strings.Join(i.Labels, ",") }},
+		{Header: "assignees", Value: func(i store.StoreIssue) string { return assigneeNames(i.Assignees) }},
+		{Header: "author", Value: func(i store.StoreIssue) string { return i.AuthorUsername }, Detail: true},
+		{Header: "due_date", Value: func(i store.StoreIssue) string { return i.DueDate }},
+		{Header: "weight", Value: func(i store.StoreIssue) string { return itoa(i.Weight) }, Detail: true},
+		{Header: "created_at", Value: func(i store.StoreIssue) string { return fmtTime(i.CreatedAt) }, Detail: true},
+		{Header: "updated_at", Value: func(i store.StoreIssue) string { return fmtTime(i.UpdatedAt) }},
+		{Header: "closed_at", Value: func(i store.StoreIssue) string { return fmtTime(i.ClosedAt) }, Detail: true},
+		{Header: "web_url", Value: func(i store.StoreIssue) string { return i.WebURL }, Detail: true},
+	},
+	Plain: func(i store.StoreIssue) string { return plainIssue(i, nil) },
+}
+
+// Issues writes an issue listing.
+func Issues(w io.Writer, f Format, issues []store.StoreIssue) error {
+	return LabelledIssues(w, f, issues, nil)
+}
+
+// LabelledIssues writes an issue listing whose plain output shows each
+// issue's labels in the colours the catalogue gives them.
+func LabelledIssues(w io.Writer, f Format, issues []store.StoreIssue, catalogue map[string]store.StoreLabel) error {
+	v := issueView
+	v.Plain = func(i store.StoreIssue) string { return plainIssue(i, catalogue) }
+	return v.List(w, f, issues)
+}
+
+func plainIssue(i store.StoreIssue, catalogue map[string]store.StoreLabel) string {
+	line := fmt.Sprintf("%s %s",
+		styles.Value.Render(i.Title),
+		styles.Label.Render("("+strconv.FormatInt(i.IID, 10)+")"))
+	for _, name := range i.Labels {
+		l := catalogue[name]
+		line += " " + styles.IssueLabel(name, l.Color, l.TextColor)
+	}
+	return line
+}
+
+// IssueChanged reports an issue after a write. Plain output is a one-line
+// confirmation such as "✓ closed group/project#12 Title"; other formats
+// write the updated issue.
+func IssueChanged(w io.Writer, f Format, verb string, i store.StoreIssue) error {
+	if f != Plain {
+		return issueView.One(w, f, i)
+	}
+	_, err := fmt.Fprintf(w, "%s %s %s\n",
+		styles.Success.Render("✓ "+verb),
+		styles.Label.Render(projectPath(i.WebURL, i.ProjectID)+"#"+itoa(i.IID)),
+		styles.Value.Render(i.Title))
+	return err
+}
+
+func assigneeNames(as []store.StoreAssignee) string {
+	names := make([]string, len(as))
+	for i, a := range as {
+		names[i] = a.Username
+	}
+	return strings.Join(names, ",")
+}
+
+func fmtTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,34 @@
+package render
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/chazzychouse/g2o/internal/store"
+	"github.com/chazzychouse/g2o/internal/styles"
+)
+
+var labelUsageView = View[store.LabelUsage]{
+	Title: "Labels",
+	Columns: []Column[store.LabelUsage]{
+		{Header: "id", Value: func(l store.LabelUsage) string { return itoa(l.ID) }, Detail: true},
+		{Header: "name", Value: func(l store.LabelUsage) string { return l.Name }},
+		{Header: "scope", Value: func(l store.LabelUsage) string { scope, _, _ := l.Scope(); return scope }},
+		{Header: "color", Value: func(l store.LabelUsage) string { return l.Color }},
+		{Header: "open_issues", Value: func(l store.LabelUsage) string { return itoa(l.OpenIssues) }},
+		{Header: "closed_issues", Value: func(l store.LabelUsage) string { return itoa(l.ClosedIssues) }},
+		{Header: "group_id", Value: func(l store.LabelUsage) string { return itoa(l.GroupID) }, Detail: true},
+		{Header: "project_id", Value: func(l store.LabelUsage) string { return itoa(l.ProjectID) }, Detail: true},
+		{Header: "description", Value: func(l store.LabelUsage) string { return l.Description }, Detail: true},
+	},
+	Plain: func(l store.LabelUsage) string {
+		return fmt.Sprintf("%s %s",
+			styles.IssueLabel(l.Name, l.Color, l.TextColor),
+			styles.Label.Render(fmt.Sprintf("(%d open, %d closed)", l.OpenIssues, l.ClosedIssues)))
+	},
+}
+
+// LabelUsage writes labels with how many stored issues carry them.
+func LabelUsage(w io.Writer, f Format, usage []store.LabelUsage) error {
+	return labelUsageView.List(w, f, usage)
+}
@@ -0,0 +1,281 @@
+package render
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/chazzychouse/g2o/internal/styles"
+)
+
+// markdownWidth is the column at which Markdown paragraphs are wrapped.
+const markdownWidth = 80
+
+var (
+	headingRe = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	bulletRe  = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	taskRe    = regexp.MustCompile(`^\[([ xX])\]\s+(.*)$`)
+	ruleRe    = regexp.MustCompile(`^\s*([-*_])(\s*([-*_])){2,}\s*$`)
+)
+
+// Markdown renders GitLab-flavoured Markdown for a terminal: headings,
+// lists and task lists, block quotes, fenced code, rules and inline code,
+// emphasis and links. Paragraphs are wrapped to width columns; anything it
+// does not understand (tables, HTML) is passed through unchanged.
+func Markdown(src string, width int) string {
+	var out []string
+	var para []string
+	paraPrefix, paraHang := "", ""
+
+	flush := func() {
+		if len(para) > 0 {
+			out = append(out, wrap(inline(strings.Join(para, " ")), width, paraPrefix, paraHang)...)
+			para = nil
+		}
+	}
+	blank := func() {
+		flush()
+		if len(out) > 0 && out[len(out)-1] != "" {
+			out = append(out, "")
+		}
+	}
+
+	var fence string
+	for _, line := range strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+				blank()
+				continue
+			}
+			out = append(out, "    "+styles.Code.Render(strings.ReplaceAll(line, "\t", "    ")))
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			blank()
+			fence = trimmed[:3]
+		case trimmed == "":
+			blank()
+		case headingRe.MatchString(trimmed):
+			blank()
+			m := headingRe.FindStringSubmatch(trimmed)
+			text := m[2]
+			if len(m[1]) <= 2 {
+				text = strings.ToUpper(text)
+			}
+			out = append(out, styles.Title.Render(text))
+		case ruleRe.MatchString(trimmed):
+			blank()
+			out = append(out, styles.Label.Render(strings.Repeat("─", min(width, markdownWidth))))
+			out = append(out, "")
+		case strings.HasPrefix(trimmed, ">"):
+			text := strings.TrimSpace(strings.TrimPrefix(trimmed, ">"))
+			if len(para) == 0 || paraPrefix != quotePrefix {
+				flush()
+				paraPrefix, paraHang = quotePrefix, quotePrefix
+			}
+			if text == "" {
+				flush()
+				continue
+			}
+			para = append(para, text)
+		case strings.HasPrefix(trimmed, "|") || strings.HasPrefix(trimmed, "<"):
+			flush()
+			out = append(out, line)
+		case bulletRe.MatchString(line):
+			flush()
+			m := bulletRe.FindStringSubmatch(line)
+			indent := strings.Repeat("  ", len(strings.ReplaceAll(m[1], "\t", "  "))/2)
+			marker, text := m[2], m[3]
+			if marker == "-" || marker == "*" || marker == "+" {
+				marker = "•"
+			}
+			if t := taskRe.FindStringSubmatch(text); t != nil {
+				marker, text = "☐", t[2]
+				if t[1] != " " {
+					marker = "☑"
+				}
+			}
+			paraPrefix = "  " + indent + marker + " "
+			paraHang = strings.Repeat(" ", utf8.RuneCountInString(paraPrefix))
+			para = append(para, text)
+		default:
+			if len(para) == 0 {
+				paraPrefix, paraHang = "", ""
+			}
+			para = append(para, trimmed)
+		}
+	}
+	flush()
+	for len(out) > 0 && out[len(out)-1] == "" {
+		out = out[:len(out)-1]
+	}
+	return strings.Join(out, "\n")
+}
+
+var quotePrefix = styles.Label.Render("│") + " "
+
+// span is a run of text rendered in one style.
+type span struct {
+	text  string
+	style lipgloss.Style
+	code  bool // kept whole when wrapping
+}
+
+// inline splits a paragraph into styled spans.
+func inline(s string) []span {
+	plain := lipgloss.NewStyle()
+	var spans []span
+	var buf strings.Builder
+	emit := func(text string, style lipgloss.Style, code bool) {
+		if buf.Len() > 0 {
+			spans = append(spans, span{text: buf.String(), style: plain})
+			buf.Reset()
+		}
+		spans = append(spans, span{text: text, style: style, code: code})
+	}
+
+	for i := 0; i < len(s); {
+		rest := s[i:]
+		switch {
+		case rest[0] == '`':
+			if j := strings.IndexByte(rest[1:], '`'); j >= 0 {
+				emit(rest[1:j+1], styles.Code, true)
+				i += j + 2
+				continue
+			}
+		case strings.HasPrefix(rest, "**") || strings.HasPrefix(rest, "__"):
+			if j := strings.Index(rest[2:], rest[:2]); j > 0 {
+				emit(rest[2:j+2], styles.Strong, false)
+				i += j + 4
+				continue
+			}
+		case rest[0] == '*' || rest[0] == '_':
+			// Underscores inside words (snake_case) are not emphasis.
+			boundary := rest[0] == '*' || i == 0 || !isWordByte(s[i-1])
+			if j := strings.IndexByte(rest[1:], rest[0]); boundary && j > 0 && rest[1] != ' ' {
+				emit(rest[1:j+1], styles.Emphasis, false)
+				i += j + 2
+				continue
+			}
+		case strings.HasPrefix(rest, "!["), rest[0] == '[':
+			image := rest[0] == '!'
+			open := rest
+			if image {
+				open = rest[1:]
+			}
+			if text, url, n, ok := parseLink(open); ok {
+				if image {
+					emit("[image: "+text+"]", styles.Label, false)
+					i += n + 1
+				} else {
+					emit(text, styles.Link, false)
+					if url != text {
+						emit(" ("+url+")", styles.Label, false)
+					}
+					i += n
+				}
+				continue
+			}
+		}
+		buf.WriteByte(s[i])
+		i++
+	}
+	if buf.Len() > 0 {
+		spans = append(spans, span{text: buf.String(), style: plain})
+	}
+	return spans
+}
+
+// parseLink parses "[text](url)" at the start of s and reports its length.
+func parseLink(s string) (text, url string, n int, ok bool) {
+	j := strings.Index(s, "](")
+	if j < 1 || strings.ContainsRune(s[1:j], '[') {
+		return "", "", 0, false
+	}
+	k := strings.IndexByte(s[j+2:], ')')
+	if k < 0 {
+		return "", "", 0, false
+	}
+	return s[1:j], s[j+2 : j+2+k], j + 3 + k, true
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b >= utf8.RuneSelf || unicode.IsLetter(rune(b)) || unicode.IsDigit(rune(b))
+}
+
+// wrap lays spans out in lines of at most width visible columns. The first
+// line starts with prefix and the rest with hang.
+func wrap(spans []span, width int, prefix, hang string) []string {
+	type word struct {
+		parts []span
+		width int
+	}
+	var words []word
+	var cur word
+	flushWord := func() {
+		if len(cur.parts) > 0 {
+			words = append(words, cur)
+			cur = word{}
+		}
+	}
+	for _, sp := range spans {
+		if sp.code {
+			cur.parts = append(cur.parts, sp)
+			cur.width += utf8.RuneCountInString(sp.text)
+			continue
+		}
+		// Whitespace separates words; a word may span several styles, as in
+		// "**bold**,".
+		var frag strings.Builder
+		emitFrag := func() {
+			if frag.Len() > 0 {
+				cur.parts = append(cur.parts, span{text: frag.String(), style: sp.style})
+				cur.width += utf8.RuneCountInString(frag.String())
+				frag.Reset()
+			}
+		}
+		for _, r := range sp.text {
+			if unicode.IsSpace(r) {
+				emitFrag()
+				flushWord()
+				continue
+			}
+			frag.WriteRune(r)
+		}
+		emitFrag()
+	}
+	flushWord()
+
+	var lines []string
+	var b strings.Builder
+	col := lipgloss.Width(prefix)
+	b.WriteString(prefix)
+	empty := true
+	for _, w := range words {
+		if !empty && col+1+w.width > width {
+			lines = append(lines, strings.TrimRight(b.String(), " "))
+			b.Reset()
+			b.WriteString(hang)
+			col = lipgloss.Width(hang)
+			empty = true
+		}
+		if !empty {
+			b.WriteByte(' ')
+			col++
+		}
+		for _, p := range w.parts {
+			b.WriteString(p.style.Render(p.text))
+		}
+		col += w.width
+		empty = false
+	}
+	lines = append(lines, b.String())
+	return lines
+}
@@ -0,0 +1,146 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/chazzychouse/g2o/internal/store"
+	"github.com/chazzychouse/g2o/internal/styles"
+)
+
+var mergeRequestView = View[store.StoreMergeRequest]{
+	Title: "Merge requests",
+	Columns: []Column[store.StoreMergeRequest]{
+		{Header: "id", Value: func(m store.StoreMergeRequest) string { return itoa(m.ID) }, Detail: true},
+		{Header: "project", Value: func(m store.StoreMergeRequest) string { return projectPath(m.WebURL, m.ProjectID) }},
+		{Header: "iid", Value: func(m store.StoreMergeRequest) string { return itoa(m.IID) }},
+		{Header: "state", Value: func(m store.StoreMergeRequest) string { return m.State }},
+		{Header: "draft", Value: func(m store.StoreMergeRequest) string { return strconv.FormatBool(m.Draft) }},
+		{Header: "title", Value: func(m store.StoreMergeRequest) string { return m.Title }},
+		{Header: "source_branch", Value: func(m store.StoreMergeRequest) string { return m.SourceBranch }},
+		{Header: "target_branch", Value: func(m store.StoreMergeRequest) string { return m.TargetBranch }},
+		{Header: "pipeline", Value: func(m store.StoreMergeRequest) string { return m.PipelineStatus }},
+		{Header: "approvals", Value: approvalSummary},
+		{Header: "reviewers", Value: func(m store.StoreMergeRequest) string { return assigneeNames(m.Reviewers) }},
+		{Header: "author", Value: func(m store.StoreMergeRequest) string { return m.AuthorUsername }, Detail: true},
+		{Header: "merge_status", Value: func(m store.StoreMergeRequest) string { return m.DetailedMergeStatus }, Detail: true},
+		{Header: "updated_at", Value: func(m store.StoreMergeRequest) string { return fmtTime(m.UpdatedAt) }, Detail: true},
+		{Header: "web_url", Value: func(m store.StoreMergeRequest) string { return m.WebURL }, Detail: true},
+	},
+	Plain: func(m store.StoreMergeRequest) string {
+		title := styles.Value.Render(m.Title)
+		if m.Draft {
+			title = styles.Label.Render("Draft: ") + title
+		}
+		line := fmt.Sprintf("%s %s\n    %s",
+			title,
+			styles.Label.Render("("+projectPath(m.WebURL, m.ProjectID)+"!"+itoa(m.IID)+", "+m.State+")"),
+			styles.Label.Render(m.SourceBranch+" → "+m.TargetBranch))
+		if m.PipelineStatus != "" {
+			line += "  " + pipelineStatus(m.PipelineStatus)
+		}
+		if a := approvalSummary(m); a != "" {
+			line += styles.Label.Render("  approvals " + a)
+		}
+		if len(m.Reviewers) > 0 {
+			line += styles.Label.Render("  reviewers " + mentions(m.Reviewers))
+		}
+		return line
+	},
+}
+
+// MergeRequests writes a merge request listing.
+func MergeRequests(w io.Writer, f Format, mrs []store.StoreMergeRequest) error {
+	return mergeRequestView.List(w, f, mrs)
+}
+
+// MergeRequest writes a single merge request. Plain output is a detail view
+// including the description.
+func MergeRequest(w io.Writer, f Format, m store.StoreMergeRequest) error {
+	if f != Plain {
+		return mergeRequestView.One(w, f, m)
+	}
+	title := m.Title
+	if m.Draft {
+		title = "Draft: " + title
+	}
+	fmt.Fprintln(w, styles.Title.Render(title))
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(w, "%s %s\n", styles.Label.Render(fmt.Sprintf("%-13s", name+":")), styles.Value.Render(value))
+		}
+	}
+	field("reference", projectPath(m.WebURL, m.ProjectID)+"!"+itoa(m.IID))
+	field("state", m.State)
+	field("branches", m.SourceBranch+" → "+m.TargetBranch)
+	if m.AuthorUsername != "" {
+		field("author", "@"+m.AuthorUsername)
+	}
+	field("assignees", mentions(m.Assignees))
+	field("reviewers", mentions(m.Reviewers))
+	field("approvals", approvalSummary(m))
+	field("approved by", mentions(m.ApprovedBy))
+	if m.PipelineStatus != "" {
+		fmt.Fprintf(w, "%s %s\n", styles.Label.Render(fmt.Sprintf("%-13s", "pipeline:")), pipelineStatus(m.PipelineStatus))
+	}
+	field("merge", m.DetailedMergeStatus)
+	if m.HasConflicts {
+		field("conflicts", "yes")
+	}
+	field("labels", strings.Join(m.Labels, ", "))
+	field("updated", fmtTime(m.UpdatedAt))
+	field("url", m.WebURL)
+	if m.Description != "" {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, m.Description)
+	}
+	return nil
+}
+
+// approvalSummary reports "given/required", or nothing when approval data
+// has not been fetched.
+func approvalSummary(m store.StoreMergeRequest) string {
+	if m.DetailSyncedAt.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d", len(m.ApprovedBy), len(m.ApprovedBy)+int(m.ApprovalsLeft))
+}
+
+func pipelineStatus(status string) string {
+	switch status {
+	case "":
+		return ""
+	case "success":
+		return styles.Success.Render("✓ " + status)
+	case "failed", "canceled":
+		return styles.Error.Render("✗ " + status)
+	default:
+		return styles.Value.Render("● " + status)
+	}
+}
+
+func mentions(users []store.StoreAssignee) string {
+	names := make([]string, len(users))
+	for i, u := range users {
+		names[i] = "@" + u.Username
+	}
+	return strings.Join(names, ", ")
+}
+
+// projectPath recovers "group/project" from a GitLab web URL such as
+// https://gitlab.example.com/group/project/-/merge_requests/1, falling back
+// to the numeric project ID.
+func projectPath(webURL string, projectID int64) string {
+	if i := strings.Index(webURL, "/-/"); i > 0 {
+		u := webURL[:i]
+		if j := strings.Index(u, "://"); j >= 0 {
+			u = u[j+3:]
+		}
+		if k := strings.Index(u, "/"); k >= 0 {
+			return u[k+1:]
+		}
+	}
+	return itoa(projectID)
+}
@@ -0,0 +1,151 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/chazzychouse/g2o/internal/styles"
+)
+
+// MilestoneReport is the progress of a milestone or iteration, computed
+// from the issues stored for it.
+type MilestoneReport struct {
+	Kind            string          `json:"kind"` // "milestone" or "iteration"
+	ID              int64           `json:"id"`
+	Title           string          `json:"title"`
+	State           string          `json:"state"`
+	StartDate       string          `json:"start_date"`
+	DueDate         string          `json:"due_date"`
+	WebURL          string          `json:"web_url"`
+	Open            int64           `json:"open"`
+	Closed          int64           `json:"closed"`
+	TotalWeight     int64           `json:"total_weight"`
+	RemainingWeight int64           `json:"remaining_weight"`
+	Unit            string          `json:"unit"` // what Burndown counts: "weight" or "issues"
+	Burndown        []BurndownPoint `json:"burndown"`
+	Days            int             `json:"days"` // from start to due date, including both
+}
+
+// BurndownPoint is what was left of a milestone at the end of a day.
+type BurndownPoint struct {
+	Date      string `json:"date"`
+	Remaining int64  `json:"remaining"`
+}
+
+var milestoneReportView = View[MilestoneReport]{
+	Columns: []Column[MilestoneReport]{
+		{Header: "kind", Value: func(r MilestoneReport) string { return r.Kind }},
+		{Header: "id", Value: func(r MilestoneReport) string { return itoa(r.ID) }, Detail: true},
+		{Header: "title", Value: func(r MilestoneReport) string { return r.Title }},
+		{Header: "state", Value: func(r MilestoneReport) string { return r.State }},
+		{Header: "start_date", Value: func(r MilestoneReport) string { return r.StartDate }},
+		{Header: "due_date", Value: func(r MilestoneReport) string { return r.DueDate }},
+		{Header: "open", Value: func(r MilestoneReport) string { return itoa(r.Open) }},
+		{Header: "closed", Value: func(r MilestoneReport) string { return itoa(r.Closed) }},
+		{Header: "total_weight", Value: func(r MilestoneReport) string { return itoa(r.TotalWeight) }},
+		{Header: "remaining_weight", Value: func(r MilestoneReport) string { return itoa(r.RemainingWeight) }},
+		{Header: "web_url", Value: func(r MilestoneReport) string { return r.WebURL }, Detail: true},
+	},
+	Plain: plainMilestoneReport,
+}
+
+// Milestone writes the progress of a milestone or iteration, with a
+// burndown chart in plain output.
+func Milestone(w io.Writer, f Format, r MilestoneReport) error {
+	return milestoneReportView.One(w, f, r)
+}
+
+func plainMilestoneReport(r MilestoneReport) string {
+	var b strings.Builder
+	b.WriteString(styles.Title.Render(r.Title))
+	b.WriteString(" " + styles.Label.Render("("+r.Kind+", "+r.State+")") + "\n")
+
+	field := func(label, value string) {
+		fmt.Fprintf(&b, "%s %s\n", styles.Label.Render(fmt.Sprintf("%-10s", label)), styles.Value.Render(value))
+	}
+	if r.StartDate != "" || r.DueDate != "" {
+		field("dates", r.StartDate+" → "+r.DueDate)
+	}
+	total := r.Open + r.Closed
+	field("issues", fmt.Sprintf("%d open, %d closed (%s done)", r.Open, r.Closed, percent(r.Closed, total)))
+	field("weight", fmt.Sprintf("%d remaining of %d (%s done)", r.RemainingWeight, r.TotalWeight,
+		percent(r.TotalWeight-r.RemainingWeight, r.TotalWeight)))
+	if r.WebURL != "" {
+		field("url", r.WebURL)
+	}
+	if len(r.Burndown) > 0 {
+		b.WriteString("\n" + styles.Label.Render("Remaining "+r.Unit) + "\n")
+		b.WriteString(burndownChart(r, burndownHeight, burndownWidth))
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func percent(part, whole int64) string {
+	if whole == 0 {
+		return "0%"
+	}
+	return fmt.Sprintf("%d%%", part*100/whole)
+}
+
+const (
+	burndownHeight = 10
+	burndownWidth  = 60 // most columns; longer milestones show every nth day
+)
+
+// burndownChart draws the remaining work per day as bars, over a dotted
+// line from the starting total down to zero on the due date. Days after the
+// last point have no bar yet.
+func burndownChart(r MilestoneReport, height, width int) string {
+	days := max(r.Days, len(r.Burndown))
+	step := (days + width - 1) / width
+	cols := (days + step - 1) / step
+	// The chart starts from all the work, before anything closed on day one.
+	start := r.TotalWeight
+	if r.Unit == "issues" {
+		start = r.Open + r.Closed
+	}
+	top := max(start, 1)
+
+	label := len(itoa(top))
+	var b strings.Builder
+	for row := height; row >= 1; row-- {
+		lo := float64(top) * float64(row-1) / float64(height)
+		hi := float64(top) * float64(row) / float64(height)
+		axis := strings.Repeat(" ", label) + " │"
+		switch row {
+		case height:
+			axis = fmt.Sprintf("%*d ┤", label, top)
+		case 1:
+			axis = fmt.Sprintf("%*d ┤", label, 0)
+		}
+		b.WriteString(styles.Label.Render(axis))
+		var line strings.Builder
+		for c := range cols {
+			day := c * step
+			ideal := float64(start)
+			if days > 1 {
+				ideal = float64(start) * (1 - float64(day)/float64(days-1))
+			}
+			switch {
+			case day < len(r.Burndown) && float64(r.Burndown[day].Remaining) > lo:
+				line.WriteString(styles.Value.Render("█"))
+			case ideal > lo && ideal <= hi || row == 1 && ideal == 0:
+				line.WriteString(styles.Label.Render("·"))
+			default:
+				line.WriteByte(' ')
+			}
+		}
+		b.WriteString(strings.TrimRight(line.String(), " ") + "\n")
+	}
+
+	pad := strings.Repeat(" ", label+1)
+	b.WriteString(styles.Label.Render(pad+"└"+strings.Repeat("─", cols)) + "\n")
+	first, last := r.Burndown[0].Date, r.DueDate
+	if last == "" {
+		last = r.Burndown[len(r.Burndown)-1].Date
+	}
+	gap := max(1, cols+1-len(first)-len(last))
+	b.WriteString(styles.Label.Render(pad+" "+first+strings.Repeat(" ", gap)+last) + "\n")
+	return b.String()
+}
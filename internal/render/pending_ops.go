@@ -0,0 +1,109 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/chazzychouse/g2o/internal/store"
+	"github.com/chazzychouse/g2o/internal/styles"
+)
+
+var pendingOpView = View[store.StorePendingOp]{
+	Title: "Queued writes",
+	Columns: []Column[store.StorePendingOp]{
+		{Header: "id", Value: func(o store.StorePendingOp) string { return itoa(o.ID) }},
+		{Header: "kind", Value: func(o store.StorePendingOp) string { return o.Kind }},
+		{Header: "issue", Value: opReference},
+		{Header: "title", Value: func(o store.StorePendingOp) string { return o.Title }},
+		{Header: "status", Value: func(o store.StorePendingOp) string { return o.Status }},
+		{Header: "attempts", Value: func(o store.StorePendingOp) string { return itoa(o.Attempts) }},
+		{Header: "created_at", Value: func(o store.StorePendingOp) string { return fmtTime(o.CreatedAt) }},
+		{Header: "last_error", Value: func(o store.StorePendingOp) string { return o.LastError }},
+		{Header: "payload", Value: func(o store.StorePendingOp) string { return string(o.Payload) }, Detail: true},
+	},
+	Plain: func(o store.StorePendingOp) string {
+		status := styles.Label.Render(o.Status)
+		if o.Status != store.OpPending {
+			status = styles.Error.Render(o.Status)
+		}
+		line := fmt.Sprintf("%s %s %s %s %s",
+			styles.Label.Render(fmt.Sprintf("%4d", o.ID)),
+			styles.Value.Render(fmt.Sprintf("%-8s", o.Kind)),
+			styles.Value.Render(o.Title),
+			styles.Label.Render("("+opReference(o)+")"),
+			status)
+		if o.LastError != "" {
+			line += "\n     " + styles.Label.Render(o.LastError)
+		}
+		return line
+	},
+}
+
+// PendingOps writes the offline write queue.
+func PendingOps(w io.Writer, f Format, ops []store.StorePendingOp) error {
+	return pendingOpView.List(w, f, ops)
+}
+
+// PendingOpDropped reports a write removed from the queue.
+func PendingOpDropped(w io.Writer, f Format, op store.StorePendingOp) error {
+	if f != Plain {
+		return pendingOpView.One(w, f, op)
+	}
+	_, err := fmt.Fprintf(w, "%s %s %s\n",
+		styles.Success.Render("✓ dropped"),
+		styles.Value.Render(op.Kind),
+		styles.Label.Render(opReference(op)))
+	return err
+}
+
+// IssueQueued reports an issue write that was queued because GitLab could
+// not be reached. The issue shown already includes the change.
+func IssueQueued(w io.Writer, f Format, verb string, i store.StoreIssue) error {
+	if f != Plain {
+		return issueView.One(w, f, i)
+	}
+	_, err := fmt.Fprintf(w, "%s %s %s\n%s\n",
+		styles.Match.Render("⧗ "+verb),
+		styles.Label.Render(projectPath(i.WebURL, i.ProjectID)+"#"+itoa(i.IID)),
+		styles.Value.Render(i.Title),
+		styles.Label.Render("  GitLab is unreachable; queued for the next sync (see 'queue')"))
+	return err
+}
+
+// Replay summarises a replay of the write queue.
+func Replay(w io.Writer, f Format, applied, conflicts, failed, remaining int) error {
+	if f != Plain {
+		enc := json.NewEncoder(w)
+		if f == JSON {
+			enc.SetIndent("", "  ")
+		}
+		return enc.Encode(map[string]int{
+			"applied":   applied,
+			"conflicts": conflicts,
+			"failed":    failed,
+			"remaining": remaining,
+		})
+	}
+	_, err := fmt.Fprintln(w, ReplaySummary(applied, conflicts, failed, remaining))
+	return err
+}
+
+// ReplaySummary is the one-line plain summary of a replay.
+func ReplaySummary(applied, conflicts, failed, remaining int) string {
+	s := styles.Success.Render(fmt.Sprintf("%d applied", applied))
+	if conflicts > 0 {
+		s += ", " + styles.Error.Render(fmt.Sprintf("%d conflicts", conflicts))
+	}
+	if failed > 0 {
+		s += ", " + styles.Error.Render(fmt.Sprintf("%d failed", failed))
+	}
+	if remaining > 0 {
+		s += ", " + styles.Label.Render(fmt.Sprintf("%d still queued", remaining))
+	}
+	return s
+}
+
+func opReference(o store.StorePendingOp) string {
+	return projectPath(o.WebURL, o.ProjectID) + "#" + itoa(o.IID)
+}
@@ -0,0 +1,174 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/chazzychouse/g2o/internal/store"
+	"github.com/chazzychouse/g2o/internal/styles"
+)
+
+// PipelineDetail is a pipeline together with its jobs.
+type PipelineDetail struct {
+	store.StorePipeline
+	Jobs []store.StoreJob `json:"jobs"`
+}
+
+var pipelineView = View[store.StorePipeline]{
+	Title: "Pipelines",
+	Columns: []Column[store.StorePipeline]{
+		{Header: "id", Value: func(p store.StorePipeline) string { return itoa(p.ID) }},
+		{Header: "status", Value: func(p store.StorePipeline) string { return p.Status }},
+		{Header: "ref", Value: func(p store.StorePipeline) string { return p.Ref }},
+		{Header: "sha", Value: func(p store.StorePipeline) string { return shortSHA(p.SHA) }, Detail: true},
+		{Header: "source", Value: func(p store.StorePipeline) string { return p.Source }, Detail: true},
+		{Header: "duration", Value: func(p store.StorePipeline) string { return ciDuration(p.Duration) }},
+		{Header: "author", Value: func(p store.StorePipeline) string { return p.AuthorUsername }},
+		{Header: "created_at", Value: func(p store.StorePipeline) string { return fmtTime(p.CreatedAt) }},
+		{Header: "web_url", Value: func(p store.StorePipeline) string { return p.WebURL }, Detail: true},
+	},
+	Plain: func(p store.StorePipeline) string {
+		line := fmt.Sprintf("%s %s %s",
+			styles.Value.Render(fmt.Sprintf("#%-8d", p.ID)),
+			pipelineStatus(p.Status),
+			styles.Value.Render(p.Ref))
+		var meta []string
+		if p.Duration > 0 {
+			meta = append(meta, ciDuration(p.Duration))
+		}
+		if p.AuthorUsername != "" {
+			meta = append(meta, "@"+p.AuthorUsername)
+		}
+		if !p.CreatedAt.IsZero() {
+			meta = append(meta, p.CreatedAt.Local().Format("2006-01-02 15:04"))
+		}
+		for _, m := range meta {
+			line += styles.Label.Render("  " + m)
+		}
+		return line
+	},
+}
+
+var jobView = View[store.StoreJob]{
+	Title: "Jobs",
+	Columns: []Column[store.StoreJob]{
+		{Header: "id", Value: func(j store.StoreJob) string { return itoa(j.ID) }},
+		{Header: "pipeline_id", Value: func(j store.StoreJob) string { return itoa(j.PipelineID) }, Detail: true},
+		{Header: "stage", Value: func(j store.StoreJob) string { return j.Stage }},
+		{Header: "name", Value: func(j store.StoreJob) string { return j.Name }},
+		{Header: "status", Value: func(j store.StoreJob) string { return j.Status }},
+		{Header: "allow_failure", Value: func(j store.StoreJob) string { return strconv.FormatBool(j.AllowFailure) }, Detail: true},
+		{Header: "failure_reason", Value: func(j store.StoreJob) string { return j.FailureReason }, Detail: true},
+		{Header: "duration", Value: func(j store.StoreJob) string { return ciDuration(j.Duration) }},
+		{Header: "web_url", Value: func(j store.StoreJob) string { return j.WebURL }, Detail: true},
+	},
+	Plain: plainJob,
+}
+
+func plainJob(j store.StoreJob) string {
+	line := fmt.Sprintf("%s %s %s", pipelineStatus(j.Status), styles.Value.Render(j.Name), styles.Label.Render("#"+itoa(j.ID)))
+	if j.Duration > 0 {
+		line += styles.Label.Render("  " + ciDuration(j.Duration))
+	}
+	if j.AllowFailure && j.Status == "failed" {
+		line += styles.Label.Render("  (allowed to fail)")
+	} else if j.FailureReason != "" {
+		line += styles.Label.Render("  (" + j.FailureReason + ")")
+	}
+	return line
+}
+
+// Pipelines writes a pipeline listing.
+func Pipelines(w io.Writer, f Format, pipelines []store.StorePipeline) error {
+	return pipelineView.List(w, f, pipelines)
+}
+
+// Pipeline writes a single pipeline. Plain output lists its jobs stage by
+// stage; JSON output includes the jobs, and table and CSV output list the
+// jobs alone.
+func Pipeline(w io.Writer, f Format, d PipelineDetail) error {
+	switch f {
+	case JSON, NDJSON:
+		if d.Jobs == nil {
+			d.Jobs = []store.StoreJob{}
+		}
+		enc := json.NewEncoder(w)
+		if f == JSON {
+			enc.SetIndent("", "  ")
+		}
+		return enc.Encode(d)
+	case Table, CSV:
+		return jobView.List(w, f, d.Jobs)
+	}
+
+	p := d.StorePipeline
+	fmt.Fprintf(w, "%s %s\n", styles.Title.Render("Pipeline #"+itoa(p.ID)), pipelineStatus(p.Status))
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(w, "%s %s\n", styles.Label.Render(fmt.Sprintf("%-10s", name+":")), styles.Value.Render(value))
+		}
+	}
+	field("project", projectPath(p.WebURL, p.ProjectID))
+	field("ref", p.Ref)
+	field("commit", shortSHA(p.SHA))
+	field("source", p.Source)
+	if p.AuthorUsername != "" {
+		field("author", "@"+p.AuthorUsername)
+	}
+	if p.Duration > 0 {
+		field("duration", ciDuration(p.Duration))
+	}
+	field("created", fmtTime(p.CreatedAt))
+	field("finished", fmtTime(p.FinishedAt))
+	field("url", p.WebURL)
+
+	stage := ""
+	for _, j := range d.Jobs {
+		if j.Stage != stage || stage == "" {
+			stage = j.Stage
+			fmt.Fprintln(w)
+			fmt.Fprintln(w, styles.Title.Render(stage))
+		}
+		fmt.Fprintln(w, "  "+plainJob(j))
+	}
+	return nil
+}
+
+// PipelineChanged reports a pipeline after a retry or cancel. Plain output
+// is a one-line confirmation such as "✓ retried pipeline #123 (main)";
+// other formats write the updated pipeline.
+func PipelineChanged(w io.Writer, f Format, verb string, p store.StorePipeline) error {
+	if f != Plain {
+		return pipelineView.One(w, f, p)
+	}
+	_, err := fmt.Fprintf(w, "%s %s %s %s\n",
+		styles.Success.Render("✓ "+verb),
+		styles.Label.Render("pipeline #"+itoa(p.ID)),
+		styles.Value.Render(p.Ref),
+		pipelineStatus(p.Status))
+	return err
+}
+
+// ciDuration formats a job or pipeline run time in seconds, e.g. "45s",
+// "3m 05s" or "1h 02m".
+func ciDuration(seconds int64) string {
+	switch {
+	case seconds <= 0:
+		return ""
+	case seconds < 60:
+		return fmt.Sprintf("%ds", seconds)
+	case seconds < 3600:
+		return fmt.Sprintf("%dm %02ds", seconds/60, seconds%60)
+	default:
+		return fmt.Sprintf("%dh %02dm", seconds/3600, seconds%3600/60)
+	}
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
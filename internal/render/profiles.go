@@ -0,0 +1,40 @@
+package render
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/chazzychouse/g2o/internal/styles"
+)
+
+// ProfileInfo is one configured GitLab instance as listed by "profile".
+type ProfileInfo struct {
+	Name    string `json:"name"`
+	BaseURL string `json:"base_url"`
+	Store   string `json:"store"`
+	Active  bool   `json:"active"`
+}
+
+var profileView = View[ProfileInfo]{
+	Title: "Profiles",
+	Columns: []Column[ProfileInfo]{
+		{Header: "name", Value: func(p ProfileInfo) string { return p.Name }},
+		{Header: "base_url", Value: func(p ProfileInfo) string { return p.BaseURL }},
+		{Header: "active", Value: func(p ProfileInfo) string { return fmt.Sprint(p.Active) }},
+		{Header: "store", Value: func(p ProfileInfo) string { return p.Store }, Detail: true},
+	},
+	Plain: func(p ProfileInfo) string {
+		marker := "  "
+		name := styles.Value.Render(p.Name)
+		if p.Active {
+			marker = styles.Success.Render("● ")
+			name = styles.Strong.Render(p.Name)
+		}
+		return fmt.Sprintf("%s%s %s", marker, name, styles.Label.Render(p.BaseURL))
+	},
+}
+
+// Profiles writes the configured profiles, marking the active one.
+func Profiles(w io.Writer, f Format, profiles []ProfileInfo) error {
+	return profileView.List(w, f, profiles)
+}
@@ -0,0 +1,138 @@
+package render
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/chazzychouse/g2o/internal/store"
+	"github.com/chazzychouse/g2o/internal/styles"
+)
+
+// Project writes one project with its details.
+func Project(w io.Writer, f Format, p store.StoreProject) error {
+	if f != Plain {
+		return projectView.One(w, f, p)
+	}
+	rows := [][2]string{
+		{"id", itoa(p.ID)},
+		{"path", p.PathWithNamespace},
+		{"branch", p.DefaultBranch},
+		{"visibility", p.Visibility},
+		{"open issues", itoa(p.OpenIssuesCount)},
+		{"activity", fmtTime(p.LastActivityAt)},
+		{"url", p.WebURL},
+	}
+	title := p.NameWithNamespace
+	if title == "" {
+		title = p.PathWithNamespace
+	}
+	if _, err := fmt.Fprintln(w, styles.Title.Render(title)); err != nil {
+		return err
+	}
+	for _, r := range rows {
+		if r[1] == "" {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s %s\n", styles.Label.Render(fmt.Sprintf("%-12s", r[0])), styles.Value.Render(r[1])); err != nil {
+			return err
+		}
+	}
+	if p.Description != "" {
+		_, err := fmt.Fprintln(w, "\n"+p.Description)
+		return err
+	}
+	return nil
+}
+
+var milestoneView = View[store.StoreMilestone]{
+	Title: "Milestones",
+	Columns: []Column[store.StoreMilestone]{
+		{Header: "id", Value: func(m store.StoreMilestone) string { return itoa(m.ID) }, Detail: true},
+		{Header: "iid", Value: func(m store.StoreMilestone) string { return itoa(m.IID) }},
+		{Header: "title", Value: func(m store.StoreMilestone) string { return m.Title }},
+		{Header: "state", Value: func(m store.StoreMilestone) string { return m.State }},
+		{Header: "start_date", Value: func(m store.StoreMilestone) string { return m.StartDate }},
+		{Header: "due_date", Value: func(m store.StoreMilestone) string { return m.DueDate }},
+		{Header: "web_url", Value: func(m store.StoreMilestone) string { return m.WebURL }, Detail: true},
+	},
+	Plain: func(m store.StoreMilestone) string {
+		line := styles.Value.Render(m.Title)
+		if m.DueDate != "" {
+			line += " " + styles.Label.Render("due "+m.DueDate)
+		}
+		if m.State != "active" {
+			line += " " + styles.Label.Render("("+m.State+")")
+		}
+		return line
+	},
+}
+
+// Milestones writes a milestone listing.
+func Milestones(w io.Writer, f Format, ms []store.StoreMilestone) error {
+	return milestoneView.List(w, f, ms)
+}
+
+// Label is a project or group label as GitLab lists it, with how many
+// issues and merge requests carry it.
+type Label struct {
+	Name              string `json:"name"`
+	Color             string `json:"color"`
+	TextColor         string `json:"text_color"`
+	Description       string `json:"description"`
+	OpenIssues        int64  `json:"open_issues"`
+	ClosedIssues      int64  `json:"closed_issues"`
+	OpenMergeRequests int64  `json:"open_merge_requests"`
+}
+
+var labelView = View[Label]{
+	Title: "Labels",
+	Columns: []Column[Label]{
+		{Header: "name", Value: func(l Label) string { return l.Name }},
+		{Header: "color", Value: func(l Label) string { return l.Color }},
+		{Header: "open_issues", Value: func(l Label) string { return itoa(l.OpenIssues) }},
+		{Header: "closed_issues", Value: func(l Label) string { return itoa(l.ClosedIssues) }, Detail: true},
+		{Header: "open_merge_requests", Value: func(l Label) string { return itoa(l.OpenMergeRequests) }},
+		{Header: "description", Value: func(l Label) string { return l.Description }, Detail: true},
+	},
+	Plain: func(l Label) string {
+		return fmt.Sprintf("%s %s",
+			styles.IssueLabel(l.Name, l.Color, l.TextColor),
+			styles.Label.Render(fmt.Sprintf("(%d open issues, %d open MRs)", l.OpenIssues, l.OpenMergeRequests)))
+	},
+}
+
+// Labels writes a label listing.
+func Labels(w io.Writer, f Format, labels []Label) error {
+	return labelView.List(w, f, labels)
+}
+
+// Member is a user with access to a project or group.
+type Member struct {
+	ID          int64  `json:"id"`
+	Username    string `json:"username"`
+	Name        string `json:"name"`
+	State       string `json:"state"`
+	AccessLevel string `json:"access_level"` // e.g. "developer"
+}
+
+var memberView = View[Member]{
+	Title: "Members",
+	Columns: []Column[Member]{
+		{Header: "id", Value: func(m Member) string { return itoa(m.ID) }, Detail: true},
+		{Header: "username", Value: func(m Member) string { return m.Username }},
+		{Header: "name", Value: func(m Member) string { return m.Name }},
+		{Header: "access_level", Value: func(m Member) string { return m.AccessLevel }},
+		{Header: "state", Value: func(m Member) string { return m.State }, Detail: true},
+	},
+	Plain: func(m Member) string {
+		return fmt.Sprintf("%s %s %s",
+			styles.Value.Render(m.Name),
+			styles.Label.Render("@"+m.Username),
+			styles.Label.Render("("+m.AccessLevel+")"))
+	},
+}
+
+// Members writes a member listing.
+func Members(w io.Writer, f Format, members []Member) error {
+	return memberView.List(w, f, members)
+}
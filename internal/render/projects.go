@@ -0,0 +1,32 @@
+package render
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/chazzychouse/g2o/internal/store"
+	"github.com/chazzychouse/g2o/internal/styles"
+)
+
+var projectView = View[store.StoreProject]{
+	Title: "Projects",
+	Columns: []Column[store.StoreProject]{
+		{Header: "id", Value: func(p store.StoreProject) string { return itoa(p.ID) }},
+		{Header: "name", Value: func(p store.StoreProject) string { return p.Name }},
+		{Header: "path_with_namespace", Value: func(p store.StoreProject) string { return p.PathWithNamespace }},
+		{Header: "default_branch", Value: func(p store.StoreProject) string { return p.DefaultBranch }},
+		{Header: "open_issues_count", Value: func(p store.StoreProject) string { return itoa(p.OpenIssuesCount) }},
+		{Header: "visibility", Value: func(p store.StoreProject) string { return p.Visibility }, Detail: true},
+		{Header: "web_url", Value: func(p store.StoreProject) string { return p.WebURL }, Detail: true},
+	},
+	Plain: func(p store.StoreProject) string {
+		return fmt.Sprintf("%s %s",
+			styles.Value.Render(p.Name),
+			styles.Label.Render("("+p.PathWithNamespace+")"))
+	},
+}
+
+// Projects writes a project listing.
+func Projects(w io.Writer, f Format, projects []store.StoreProject) error {
+	return projectView.List(w, f, projects)
+}
@@ -0,0 +1,158 @@
+// Package render writes store records to an io.Writer in one of several
+// output formats, from styled terminal listings to JSON and CSV for scripts.
+package render
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/chazzychouse/g2o/internal/styles"
+)
+
+// Format selects how records are written.
+type Format string
+
+const (
+	Plain  Format = "plain"  // styled, human-oriented listing (default)
+	Table  Format = "table"  // aligned columns with a header row
+	JSON   Format = "json"   // a single JSON document
+	NDJSON Format = "ndjson" // one JSON object per line
+	CSV    Format = "csv"    // comma-separated values with a header row
+)
+
+// Formats lists every supported format in the order shown to users.
+var Formats = []Format{Plain, Table, JSON, NDJSON, CSV}
+
+// ParseFormat validates a user-supplied format name. An empty string selects
+// Plain.
+func ParseFormat(s string) (Format, error) {
+	if s == "" {
+		return Plain, nil
+	}
+	for _, f := range Formats {
+		if string(f) == strings.ToLower(s) {
+			return f, nil
+		}
+	}
+	names := make([]string, len(Formats))
+	for i, f := range Formats {
+		names[i] = string(f)
+	}
+	return "", fmt.Errorf("unknown output format %q (want one of: %s)", s, strings.Join(names, ", "))
+}
+
+// Column extracts one flat field of a record for table and CSV output.
+type Column[T any] struct {
+	Header string
+	Value  func(T) string
+	Detail bool // included in CSV but omitted from table output
+}
+
+// View describes how one kind of record is rendered in every format.
+type View[T any] struct {
+	Title   string         // heading for plain listings, e.g. "Issues"
+	Columns []Column[T]    // fields for table and CSV output
+	Plain   func(T) string // one styled entry for plain output
+}
+
+// List writes items in format f.
+func (v View[T]) List(w io.Writer, f Format, items []T) error {
+	switch f {
+	case JSON:
+		if items == nil {
+			items = []T{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(items)
+	case NDJSON:
+		enc := json.NewEncoder(w)
+		for _, item := range items {
+			if err := enc.Encode(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	case CSV:
+		return v.csv(w, items)
+	case Table:
+		return v.table(w, items)
+	default:
+		if v.Title != "" {
+			if _, err := fmt.Fprintln(w, styles.Title.Render(fmt.Sprintf("%s: %d", v.Title, len(items)))); err != nil {
+				return err
+			}
+		}
+		for _, item := range items {
+			if _, err := fmt.Fprintln(w, v.Plain(item)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// One writes a single record in format f. JSON output is an object rather
+// than a one-element array, and plain output has no heading.
+func (v View[T]) One(w io.Writer, f Format, item T) error {
+	switch f {
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(item)
+	case Plain, "":
+		_, err := fmt.Fprintln(w, v.Plain(item))
+		return err
+	default:
+		return v.List(w, f, []T{item})
+	}
+}
+
+func (v View[T]) csv(w io.Writer, items []T) error {
+	cw := csv.NewWriter(w)
+	header := make([]string, len(v.Columns))
+	for i, c := range v.Columns {
+		header[i] = c.Header
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	row := make([]string, len(v.Columns))
+	for _, item := range items {
+		for i, c := range v.Columns {
+			row[i] = c.Value(item)
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (v View[T]) table(w io.Writer, items []T) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	var cols []Column[T]
+	for _, c := range v.Columns {
+		if !c.Detail {
+			cols = append(cols, c)
+		}
+	}
+	cells := make([]string, len(cols))
+	for i, c := range cols {
+		cells[i] = strings.ToUpper(c.Header)
+	}
+	fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	for _, item := range items {
+		for i, c := range cols {
+			// Tabs and newlines would break column alignment.
+			cells[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(c.Value(item))
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
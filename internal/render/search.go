@@ -0,0 +1,66 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/chazzychouse/g2o/internal/store"
+	"github.com/chazzychouse/g2o/internal/styles"
+)
+
+var searchView = View[store.IssueHit]{
+	Title: "Matches",
+	Columns: []Column[store.IssueHit]{
+		{Header: "rank", Value: func(h store.IssueHit) string { return strconv.FormatFloat(h.Rank, 'f', 3, 64) }, Detail: true},
+		{Header: "project_id", Value: func(h store.IssueHit) string { return itoa(h.ProjectID) }},
+		{Header: "iid", Value: func(h store.IssueHit) string { return itoa(h.IID) }},
+		{Header: "state", Value: func(h store.IssueHit) string { return h.State }},
+		{Header: "title", Value: func(h store.IssueHit) string { return h.Title }},
+		{Header: "snippet", Value: func(h store.IssueHit) string { return h.Snippet }},
+		{Header: "web_url", Value: func(h store.IssueHit) string { return h.WebURL }, Detail: true},
+	},
+	Plain: func(h store.IssueHit) string {
+		return fmt.Sprintf("%s %s\n    %s",
+			styles.Value.Render(h.Title),
+			styles.Label.Render("("+strconv.FormatInt(h.IID, 10)+", "+h.State+")"),
+			highlight(h.Snippet))
+	},
+}
+
+// SearchHits writes full-text search results. Plain output highlights the
+// matched words; other formats drop the highlight markers.
+func SearchHits(w io.Writer, f Format, hits []store.IssueHit) error {
+	if f != Plain {
+		stripped := make([]store.IssueHit, len(hits))
+		for i, h := range hits {
+			h.Snippet = strings.NewReplacer(store.MatchStart, "", store.MatchEnd, "").Replace(h.Snippet)
+			stripped[i] = h
+		}
+		hits = stripped
+	}
+	return searchView.List(w, f, hits)
+}
+
+// highlight styles the marked matches in a snippet and flattens it to a
+// single line.
+func highlight(snippet string) string {
+	snippet = strings.Join(strings.Fields(snippet), " ")
+	var b strings.Builder
+	for {
+		i := strings.Index(snippet, store.MatchStart)
+		if i < 0 {
+			break
+		}
+		j := strings.Index(snippet[i:], store.MatchEnd)
+		if j < 0 {
+			break
+		}
+		b.WriteString(styles.Label.Render(snippet[:i]))
+		b.WriteString(styles.Match.Render(snippet[i+len(store.MatchStart) : i+j]))
+		snippet = snippet[i+j+len(store.MatchEnd):]
+	}
+	b.WriteString(styles.Label.Render(snippet))
+	return b.String()
+}
@@ -0,0 +1,88 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/chazzychouse/g2o/internal/store"
+	"github.com/chazzychouse/g2o/internal/styles"
+)
+
+// SyncedResource is when one resource type was last synced.
+type SyncedResource struct {
+	Resource   string    `json:"resource"`
+	LastSynced time.Time `json:"last_synced,omitzero"`
+	Issues     int       `json:"issues,omitempty"` // stored issues, for an issue scope
+}
+
+// SyncState is the state of the store as shown by "sync status".
+type SyncState struct {
+	Resources    []SyncedResource `json:"resources"`
+	Drift        []store.Drift    `json:"drift"`
+	QueuedWrites int              `json:"queued_writes"`
+	Interrupted  []string         `json:"interrupted,omitempty"` // resources with an unfinished full sync
+	LastFullSync time.Time        `json:"last_full_sync,omitzero"`
+}
+
+// staleFullSync is how old the last full sync may be before "sync status"
+// suggests another.
+const staleFullSync = 7 * 24 * time.Hour
+
+// SyncStatus writes when each resource was last synced and how far the
+// store had drifted from GitLab.
+func SyncStatus(w io.Writer, f Format, st SyncState) error {
+	if f != Plain {
+		enc := json.NewEncoder(w)
+		if f == JSON {
+			enc.SetIndent("", "  ")
+		}
+		return enc.Encode(st)
+	}
+	const stamp = "2006-01-02 15:04:05"
+	row := func(name, value string) string {
+		return "  " + styles.Label.Render(fmt.Sprintf("%-14s", name)) + " " + styles.Value.Render(value)
+	}
+	lines := []string{styles.Title.Render("Sync Status")}
+	for _, r := range st.Resources {
+		ts := "never"
+		if !r.LastSynced.IsZero() {
+			ts = r.LastSynced.Local().Format(stamp)
+		}
+		if strings.HasPrefix(r.Resource, "issues:") {
+			ts += fmt.Sprintf(" (%d issues)", r.Issues)
+		}
+		lines = append(lines, row(r.Resource, ts))
+	}
+
+	lines = append(lines, styles.Title.Render("Drift"))
+	missing := 0
+	for _, d := range st.Drift {
+		line := "never reconciled"
+		if !d.ReconciledAt.IsZero() {
+			line = fmt.Sprintf("%d removed, %d missing at %s", d.Removed, d.Missing, d.ReconciledAt.Local().Format(stamp))
+		}
+		missing += d.Missing
+		if d.Deleted > 0 {
+			line += fmt.Sprintf(" (%d soft-deleted)", d.Deleted)
+		}
+		lines = append(lines, row(d.Resource, line))
+	}
+
+	if st.QueuedWrites > 0 {
+		lines = append(lines, row("queued writes", fmt.Sprint(st.QueuedWrites)))
+	}
+	if len(st.Interrupted) > 0 {
+		lines = append(lines, styles.Label.Render("  interrupted full sync of "+strings.Join(st.Interrupted, ", ")+" — 'sync full' resumes it"))
+	}
+	if missing > 0 {
+		lines = append(lines, styles.Label.Render("  records missing from the store — 'sync' or 'sync full' fetches them"))
+	}
+	if !st.LastFullSync.IsZero() && time.Since(st.LastFullSync) > staleFullSync {
+		lines = append(lines, styles.Error.Render("  hint: last full sync > 7 days ago — consider running 'sync full'"))
+	}
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
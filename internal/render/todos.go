@@ -0,0 +1,85 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/chazzychouse/g2o/internal/store"
+	"github.com/chazzychouse/g2o/internal/styles"
+)
+
+var todoView = View[store.StoreTodo]{
+	Title: "To-do",
+	Columns: []Column[store.StoreTodo]{
+		{Header: "id", Value: func(t store.StoreTodo) string { return itoa(t.ID) }},
+		{Header: "action", Value: func(t store.StoreTodo) string { return t.ActionName }},
+		{Header: "target_type", Value: func(t store.StoreTodo) string { return t.TargetType }},
+		{Header: "target", Value: todoReference},
+		{Header: "title", Value: func(t store.StoreTodo) string { return t.TargetTitle }},
+		{Header: "author", Value: func(t store.StoreTodo) string { return t.AuthorUsername }},
+		{Header: "state", Value: func(t store.StoreTodo) string { return t.State }, Detail: true},
+		{Header: "target_state", Value: func(t store.StoreTodo) string { return t.TargetState }, Detail: true},
+		{Header: "created_at", Value: func(t store.StoreTodo) string { return fmtTime(t.CreatedAt) }, Detail: true},
+		{Header: "target_url", Value: func(t store.StoreTodo) string { return t.TargetURL }, Detail: true},
+	},
+	Plain: func(t store.StoreTodo) string {
+		line := fmt.Sprintf("%s %s %s %s",
+			styles.Label.Render(fmt.Sprintf("%-8s", itoa(t.ID))),
+			styles.Match.Render(todoAction(t.ActionName)),
+			styles.Label.Render(todoReference(t)),
+			styles.Value.Render(t.TargetTitle))
+		if t.AuthorUsername != "" {
+			line += styles.Label.Render("  @" + t.AuthorUsername)
+		}
+		return line
+	},
+}
+
+// Todos writes a to-do list.
+func Todos(w io.Writer, f Format, todos []store.StoreTodo) error {
+	return todoView.List(w, f, todos)
+}
+
+// TodosDone reports to-do items after marking them done. Plain output is a
+// one-line confirmation; other formats list the items.
+func TodosDone(w io.Writer, f Format, todos []store.StoreTodo) error {
+	if f != Plain {
+		return todoView.List(w, f, todos)
+	}
+	var err error
+	switch len(todos) {
+	case 0:
+		_, err = fmt.Fprintln(w, styles.Success.Render("✓ marked all to-do items done"))
+	case 1:
+		t := todos[0]
+		ref := "to-do item " + itoa(t.ID)
+		if t.TargetType != "" {
+			ref = todoReference(t)
+		}
+		_, err = fmt.Fprintf(w, "%s %s %s\n", styles.Success.Render("✓ done"), styles.Label.Render(ref), styles.Value.Render(t.TargetTitle))
+	default:
+		_, err = fmt.Fprintln(w, styles.Success.Render(fmt.Sprintf("✓ marked %d to-do items done", len(todos))))
+	}
+	return err
+}
+
+// todoReference names what a to-do item is about, e.g. "g/p#12" for an
+// issue or "g/p!3" for a merge request.
+func todoReference(t store.StoreTodo) string {
+	switch t.TargetType {
+	case "Issue", "WorkItem":
+		return t.ProjectPath + "#" + itoa(t.TargetIID)
+	case "MergeRequest":
+		return t.ProjectPath + "!" + itoa(t.TargetIID)
+	case "":
+		return t.ProjectPath
+	default:
+		return strings.TrimSpace(t.ProjectPath + " " + t.TargetType)
+	}
+}
+
+// todoAction turns an action name such as "review_requested" into words.
+func todoAction(action string) string {
+	return strings.ReplaceAll(action, "_", " ")
+}
@@ -0,0 +1,30 @@
+package render
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/chazzychouse/g2o/internal/store"
+	"github.com/chazzychouse/g2o/internal/styles"
+)
+
+var userView = View[store.StoreUser]{
+	Columns: []Column[store.StoreUser]{
+		{Header: "id", Value: func(u store.StoreUser) string { return itoa(u.ID) }},
+		{Header: "name", Value: func(u store.StoreUser) string { return u.Name }},
+		{Header: "username", Value: func(u store.StoreUser) string { return u.Username }},
+		{Header: "email", Value: func(u store.StoreUser) string { return u.Email }},
+		{Header: "web_url", Value: func(u store.StoreUser) string { return u.WebURL }, Detail: true},
+	},
+	Plain: func(u store.StoreUser) string {
+		return fmt.Sprintf("%s %s\n%s %s\n%s %s",
+			styles.Label.Render("name:    "), styles.Value.Render(u.Name),
+			styles.Label.Render("username:"), styles.Value.Render(u.Username),
+			styles.Label.Render("email:   "), styles.Value.Render(u.Email))
+	},
+}
+
+// User writes a single user record.
+func User(w io.Writer, f Format, u store.StoreUser) error {
+	return userView.One(w, f, u)
+}
@@ -20,7 +20,8 @@ func (s *Store) UpsertGroups(groups []StoreGroup) error {
 			name=excluded.name, path=excluded.path, full_name=excluded.full_name,
 			full_path=excluded.full_path, description=excluded.description,
 			visibility=excluded.visibility, web_url=excluded.web_url,
-			parent_id=excluded.parent_id, created_at=excluded.created_at, synced_at=excluded.synced_at`)
+			parent_id=excluded.parent_id, created_at=excluded.created_at, synced_at=excluded.synced_at,
+			deleted_at=''`)
 	if err != nil {
 		return err
 	}
@@ -42,7 +43,7 @@ func (s *Store) UpsertGroups(groups []StoreGroup) error {
 }
 
 func (s *Store) ListGroups() ([]StoreGroup, error) {
-	rows, err := s.db.Query("SELECT id, name, path, full_name, full_path, description, visibility, web_url, parent_id FROM groups ORDER BY name")
+	rows, err := s.db.Query("SELECT id, name, path, full_name, full_path, description, visibility, web_url, parent_id FROM groups WHERE deleted_at = '' ORDER BY name")
 	if err != nil {
 		return nil, err
 	}
@@ -63,7 +64,7 @@ func (s *Store) ListGroups() ([]StoreGroup, error) {
 func (s *Store) GetGroup(id int64) (StoreGroup, error) {
 	var g StoreGroup
 	err := s.db.QueryRow(
-		"SELECT id, name, path, full_name, full_path, description, visibility, web_url, parent_id FROM groups WHERE id = ?", id,
+		"SELECT id, name, path, full_name, full_path, description, visibility, web_url, parent_id FROM groups WHERE id = ? AND deleted_at = ''", id,
 	).Scan(&g.ID, &g.Name, &g.Path, &g.FullName, &g.FullPath,
 		&g.Description, &g.Visibility, &g.WebURL, &g.ParentID)
 	if errors.Is(err, sql.ErrNoRows) {
@@ -72,39 +73,33 @@ func (s *Store) GetGroup(id int64) (StoreGroup, error) {
 	return g, err
 }
 
-// DeleteStaleGroups removes groups whose IDs are not in the given set.
-func (s *Store) DeleteStaleGroups(activeIDs []int64) error {
-	if len(activeIDs) == 0 {
-		_, err := s.db.Exec("DELETE FROM groups")
-		return err
-	}
-	tx, err := s.db.Begin()
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
+// DeleteStaleGroups removes groups not stored since syncedBefore, the start
+// of a full sync that stored every group that still exists.
+func (s *Store) DeleteStaleGroups(syncedBefore time.Time) error {
+	_, err := s.db.Exec("DELETE FROM groups WHERE synced_at < ?", fmtTime(syncedBefore))
+	return err
+}
 
-	// Build temp table of active IDs.
-	if _, err := tx.Exec("CREATE TEMP TABLE _active_groups (id INTEGER PRIMARY KEY)"); err != nil {
-		return err
-	}
-	stmt, err := tx.Prepare("INSERT INTO _active_groups (id) VALUES (?)")
+// ListGroupPaths returns the ID and full_path of every stored group, for
+// resolving and completing names.
+func (s *Store) ListGroupPaths() ([]PathRef, error) {
+	return s.listPaths("SELECT id, full_path FROM groups WHERE deleted_at = '' ORDER BY full_path")
+}
+
+func (s *Store) listPaths(query string) ([]PathRef, error) {
+	rows, err := s.db.Query(query)
 	if err != nil {
-		return err
-	}
-	for _, id := range activeIDs {
-		if _, err := stmt.Exec(id); err != nil {
-			stmt.Close()
-			return err
-		}
+		return nil, err
 	}
-	stmt.Close()
+	defer rows.Close()
 
-	if _, err := tx.Exec("DELETE FROM groups WHERE id NOT IN (SELECT id FROM _active_groups)"); err != nil {
-		return err
-	}
-	if _, err := tx.Exec("DROP TABLE _active_groups"); err != nil {
-		return err
+	var refs []PathRef
+	for rows.Next() {
+		var r PathRef
+		if err := rows.Scan(&r.ID, &r.Path); err != nil {
+			return nil, err
+		}
+		refs = append(refs, r)
 	}
-	return tx.Commit()
+	return refs, rows.Err()
 }
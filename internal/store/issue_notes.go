@@ -0,0 +1,133 @@
+package store
+
+import "time"
+
+// ReplaceIssueDiscussion stores the complete set of notes and links for an
+// issue, replacing what was stored before, and records when it was fetched.
+// Local notes (negative IDs, for comments still in the outbox) are kept.
+func (s *Store) ReplaceIssueDiscussion(issueID int64, notes []StoreIssueNote, links []StoreIssueLink) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM issue_notes WHERE issue_id = ? AND id > 0", issueID); err != nil {
+		return err
+	}
+	noteStmt, err := tx.Prepare(`INSERT OR REPLACE INTO issue_notes (id, issue_id, body,
+		author_id, author_name, author_username, system, internal, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer noteStmt.Close()
+	for _, n := range notes {
+		if _, err := noteStmt.Exec(n.ID, issueID, n.Body, n.AuthorID, n.AuthorName, n.AuthorUsername,
+			boolToInt(n.System), boolToInt(n.Internal), fmtTime(n.CreatedAt), fmtTime(n.UpdatedAt)); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec("DELETE FROM issue_links WHERE issue_id = ?", issueID); err != nil {
+		return err
+	}
+	linkStmt, err := tx.Prepare(`INSERT OR REPLACE INTO issue_links (issue_id, linked_issue_id,
+		link_type, project_id, iid, title, state, reference, web_url)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer linkStmt.Close()
+	for _, l := range links {
+		if _, err := linkStmt.Exec(issueID, l.LinkedIssueID, l.LinkType, l.ProjectID, l.IID,
+			l.Title, l.State, l.Reference, l.WebURL); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec("UPDATE issues SET notes_synced_at = ? WHERE id = ?",
+		time.Now().UTC().Format(time.RFC3339), issueID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// AddIssueNote stores a single note, such as the local copy of a queued
+// comment.
+func (s *Store) AddIssueNote(n StoreIssueNote) error {
+	_, err := s.db.Exec(`INSERT OR REPLACE INTO issue_notes (id, issue_id, body,
+		author_id, author_name, author_username, system, internal, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		n.ID, n.IssueID, n.Body, n.AuthorID, n.AuthorName, n.AuthorUsername,
+		boolToInt(n.System), boolToInt(n.Internal), fmtTime(n.CreatedAt), fmtTime(n.UpdatedAt))
+	return err
+}
+
+func (s *Store) DeleteIssueNote(id int64) error {
+	_, err := s.db.Exec("DELETE FROM issue_notes WHERE id = ?", id)
+	return err
+}
+
+// ListIssueNotes returns an issue's notes, oldest first.
+func (s *Store) ListIssueNotes(issueID int64) ([]StoreIssueNote, error) {
+	rows, err := s.db.Query(`SELECT id, issue_id, body, author_id, author_name, author_username,
+		system, internal, created_at, updated_at
+		FROM issue_notes WHERE issue_id = ? ORDER BY created_at, id`, issueID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notes []StoreIssueNote
+	for rows.Next() {
+		var n StoreIssueNote
+		var system, internal int
+		var createdAt, updatedAt string
+		if err := rows.Scan(&n.ID, &n.IssueID, &n.Body, &n.AuthorID, &n.AuthorName, &n.AuthorUsername,
+			&system, &internal, &createdAt, &updatedAt); err != nil {
+			return nil, err
+		}
+		n.System = system != 0
+		n.Internal = internal != 0
+		n.CreatedAt = parseTime(createdAt)
+		n.UpdatedAt = parseTime(updatedAt)
+		notes = append(notes, n)
+	}
+	return notes, rows.Err()
+}
+
+// ListIssueLinks returns the issues linked to an issue.
+func (s *Store) ListIssueLinks(issueID int64) ([]StoreIssueLink, error) {
+	rows, err := s.db.Query(`SELECT issue_id, linked_issue_id, link_type, project_id, iid,
+		title, state, reference, web_url
+		FROM issue_links WHERE issue_id = ? ORDER BY link_type, reference`, issueID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var links []StoreIssueLink
+	for rows.Next() {
+		var l StoreIssueLink
+		if err := rows.Scan(&l.IssueID, &l.LinkedIssueID, &l.LinkType, &l.ProjectID, &l.IID,
+			&l.Title, &l.State, &l.Reference, &l.WebURL); err != nil {
+			return nil, err
+		}
+		links = append(links, l)
+	}
+	return links, rows.Err()
+}
+
+// ListIssuesNeedingNotes returns issues whose notes have never been fetched
+// or were fetched before the issue last changed.
+func (s *Store) ListIssuesNeedingNotes() ([]StoreIssue, error) {
+	rows, err := s.db.Query(`SELECT ` + issueColumns + ` FROM issues
+		WHERE (notes_synced_at = '' OR notes_synced_at < updated_at) AND deleted_at = ''
+		ORDER BY updated_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanIssues(rows)
+}
@@ -0,0 +1,131 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+)
+
+// LinkScopeIssues records that the issues were listed by the sync scope
+// named scope, as written in the profile (e.g. "group:platform").
+func (s *Store) LinkScopeIssues(scope string, issueIDs []int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO issue_scopes (scope, issue_id, synced_at) VALUES (?, ?, ?)
+		ON CONFLICT(scope, issue_id) DO UPDATE SET synced_at = excluded.synced_at`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, id := range issueIDs {
+		if _, err := stmt.Exec(scope, id, now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// DeleteStaleScopeIssues drops the issues of scope not listed since
+// syncedBefore. An issue goes, with its notes and links, once neither a
+// scope nor a group lists it; the other scopes keep theirs.
+func (s *Store) DeleteStaleScopeIssues(scope string, syncedBefore time.Time) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM issue_scopes WHERE scope = ? AND synced_at < ?", scope, fmtTime(syncedBefore)); err != nil {
+		return err
+	}
+	if err := deleteUnlistedIssues(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ReconcileScopeIssues drops the issues of scope that are not in ids, the
+// issues GitLab currently lists for it.
+func (s *Store) ReconcileScopeIssues(scope string, ids []int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fillIDs(tx, "_reconcile_ids", ids); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM issue_scopes
+		WHERE scope = ? AND issue_id NOT IN (SELECT id FROM _reconcile_ids)`, scope); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DROP TABLE _reconcile_ids"); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ListIssueScopes returns the scopes that list any stored issue, including
+// those synced on request rather than configured.
+func (s *Store) ListIssueScopes() ([]string, error) {
+	rows, err := s.db.Query("SELECT DISTINCT scope FROM issue_scopes ORDER BY scope")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var scopes []string
+	for rows.Next() {
+		var scope string
+		if err := rows.Scan(&scope); err != nil {
+			return nil, err
+		}
+		scopes = append(scopes, scope)
+	}
+	return scopes, rows.Err()
+}
+
+// CountScopeIssues returns how many stored issues each scope lists.
+func (s *Store) CountScopeIssues() (map[string]int, error) {
+	rows, err := s.db.Query(`SELECT scope, COUNT(*) FROM issue_scopes
+		JOIN issues ON issues.id = issue_scopes.issue_id
+		WHERE issues.deleted_at = '' GROUP BY scope`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var scope string
+		var n int
+		if err := rows.Scan(&scope, &n); err != nil {
+			return nil, err
+		}
+		counts[scope] = n
+	}
+	return counts, rows.Err()
+}
+
+// deleteUnlistedIssues removes the issues no scope or group lists, and
+// whatever hangs off them.
+func deleteUnlistedIssues(tx *sql.Tx) error {
+	if _, err := tx.Exec(`DELETE FROM issues
+		WHERE id NOT IN (SELECT issue_id FROM issue_scopes)
+		AND id NOT IN (SELECT issue_id FROM group_issues)`); err != nil {
+		return err
+	}
+	for _, table := range []string{"issue_scopes", "group_issues", "issue_notes", "issue_links"} {
+		if _, err := tx.Exec("DELETE FROM " + table + " WHERE issue_id NOT IN (SELECT id FROM issues)"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package store
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"time"
 )
 
@@ -19,8 +20,10 @@ func (s *Store) UpsertIssues(issues []StoreIssue) error {
 	stmt, err := tx.Prepare(`
 		INSERT INTO issues (id, iid, project_id, title, state, description, web_url,
 			author_id, author_name, author_username, labels, assignees,
-			created_at, updated_at, closed_at, due_date, weight, confidential, synced_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			created_at, updated_at, closed_at, due_date, weight, confidential,
+			milestone_title, milestone_id, iteration_id, time_estimate, time_spent,
+			user_notes_count, synced_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			iid=excluded.iid, project_id=excluded.project_id, title=excluded.title,
 			state=excluded.state, description=excluded.description, web_url=excluded.web_url,
@@ -29,7 +32,11 @@ func (s *Store) UpsertIssues(issues []StoreIssue) error {
 			assignees=excluded.assignees, created_at=excluded.created_at,
 			updated_at=excluded.updated_at, closed_at=excluded.closed_at,
 			due_date=excluded.due_date, weight=excluded.weight,
-			confidential=excluded.confidential, synced_at=excluded.synced_at`)
+			confidential=excluded.confidential, milestone_title=excluded.milestone_title,
+			milestone_id=excluded.milestone_id, iteration_id=excluded.iteration_id,
+			time_estimate=excluded.time_estimate, time_spent=excluded.time_spent,
+			user_notes_count=excluded.user_notes_count, synced_at=excluded.synced_at,
+			deleted_at=''`)
 	if err != nil {
 		return err
 	}
@@ -44,7 +51,8 @@ func (s *Store) UpsertIssues(issues []StoreIssue) error {
 			issue.Description, issue.WebURL, issue.AuthorID, issue.AuthorName,
 			issue.AuthorUsername, string(labelsJSON), string(assigneesJSON),
 			fmtTime(issue.CreatedAt), fmtTime(issue.UpdatedAt), fmtTime(issue.ClosedAt),
-			issue.DueDate, issue.Weight, boolToInt(issue.Confidential), now,
+			issue.DueDate, issue.Weight, boolToInt(issue.Confidential),
+			issue.MilestoneTitle, issue.MilestoneID, issue.IterationID, issue.TimeEstimate, issue.TimeSpent, issue.UserNotesCount, now,
 		)
 		if err != nil {
 			return err
@@ -53,11 +61,15 @@ func (s *Store) UpsertIssues(issues []StoreIssue) error {
 	return tx.Commit()
 }
 
+// issueColumns is the column list read by scanIssue.
+const issueColumns = `id, iid, project_id, title, state, description, web_url,
+	author_id, author_name, author_username, labels, assignees,
+	created_at, updated_at, closed_at, due_date, weight, confidential,
+	milestone_title, milestone_id, iteration_id, time_estimate, time_spent,
+	user_notes_count, notes_synced_at`
+
 func (s *Store) ListIssues() ([]StoreIssue, error) {
-	rows, err := s.db.Query(`SELECT id, iid, project_id, title, state, description, web_url,
-		author_id, author_name, author_username, labels, assignees,
-		created_at, updated_at, closed_at, due_date, weight, confidential
-		FROM issues ORDER BY updated_at DESC`)
+	rows, err := s.db.Query(`SELECT ` + issueColumns + ` FROM issues WHERE deleted_at = '' ORDER BY updated_at DESC`)
 	if err != nil {
 		return nil, err
 	}
@@ -65,14 +77,19 @@ func (s *Store) ListIssues() ([]StoreIssue, error) {
 	return scanIssues(rows)
 }
 
+func (s *Store) GetIssue(projectID, iid int64) (StoreIssue, error) {
+	issue, err := scanIssue(s.db.QueryRow(`SELECT `+issueColumns+` FROM issues
+		WHERE project_id = ? AND iid = ? AND deleted_at = ''`, projectID, iid))
+	if errors.Is(err, sql.ErrNoRows) {
+		return issue, ErrRecordNotFound
+	}
+	return issue, err
+}
+
 func (s *Store) ListIssuesByGroup(groupID int64) ([]StoreIssue, error) {
-	rows, err := s.db.Query(`SELECT i.id, i.iid, i.project_id, i.title, i.state, i.description, i.web_url,
-		i.author_id, i.author_name, i.author_username, i.labels, i.assignees,
-		i.created_at, i.updated_at, i.closed_at, i.due_date, i.weight, i.confidential
-		FROM issues i
-		JOIN group_issues gi ON gi.issue_id = i.id
-		WHERE gi.group_id = ?
-		ORDER BY i.updated_at DESC`, groupID)
+	rows, err := s.db.Query(`SELECT `+issueColumns+` FROM issues
+		WHERE id IN (SELECT issue_id FROM group_issues WHERE group_id = ?) AND deleted_at = ''
+		ORDER BY updated_at DESC`, groupID)
 	if err != nil {
 		return nil, err
 	}
@@ -106,63 +123,43 @@ func (s *Store) LinkGroupIssues(groupID int64, issueIDs []int64) error {
 	return tx.Commit()
 }
 
-func (s *Store) DeleteStaleIssues(activeIDs []int64) error {
-	if len(activeIDs) == 0 {
-		_, err := s.db.Exec("DELETE FROM issues")
-		return err
-	}
-	tx, err := s.db.Begin()
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
-
-	if _, err := tx.Exec("CREATE TEMP TABLE _active_issues (id INTEGER PRIMARY KEY)"); err != nil {
-		return err
-	}
-	stmt, err := tx.Prepare("INSERT INTO _active_issues (id) VALUES (?)")
-	if err != nil {
-		return err
-	}
-	for _, id := range activeIDs {
-		if _, err := stmt.Exec(id); err != nil {
-			stmt.Close()
-			return err
-		}
-	}
-	stmt.Close()
-
-	if _, err := tx.Exec("DELETE FROM issues WHERE id NOT IN (SELECT id FROM _active_issues)"); err != nil {
-		return err
-	}
-	if _, err := tx.Exec("DELETE FROM group_issues WHERE issue_id NOT IN (SELECT id FROM _active_issues)"); err != nil {
-		return err
-	}
-	if _, err := tx.Exec("DROP TABLE _active_issues"); err != nil {
-		return err
-	}
-	return tx.Commit()
-}
-
 func scanIssues(rows *sql.Rows) ([]StoreIssue, error) {
 	var issues []StoreIssue
 	for rows.Next() {
-		var issue StoreIssue
-		var labelsJSON, assigneesJSON string
-		var confidential int
-		if err := rows.Scan(
-			&issue.ID, &issue.IID, &issue.ProjectID, &issue.Title, &issue.State,
-			&issue.Description, &issue.WebURL, &issue.AuthorID, &issue.AuthorName,
-			&issue.AuthorUsername, &labelsJSON, &assigneesJSON,
-			&issue.CreatedAt, &issue.UpdatedAt, &issue.ClosedAt,
-			&issue.DueDate, &issue.Weight, &confidential,
-		); err != nil {
+		issue, err := scanIssue(rows)
+		if err != nil {
 			return nil, err
 		}
-		issue.Confidential = confidential != 0
-		_ = json.Unmarshal([]byte(labelsJSON), &issue.Labels)
-		_ = json.Unmarshal([]byte(assigneesJSON), &issue.Assignees)
 		issues = append(issues, issue)
 	}
 	return issues, rows.Err()
 }
+
+// scanIssue scans issueColumns, followed by any extra
+// destinations for columns selected after it.
+func scanIssue(row interface{ Scan(...any) error }, extra ...any) (StoreIssue, error) {
+	var issue StoreIssue
+	var labelsJSON, assigneesJSON string
+	var createdAt, updatedAt, closedAt, notesSyncedAt string
+	var confidential int
+	dest := []any{
+		&issue.ID, &issue.IID, &issue.ProjectID, &issue.Title, &issue.State,
+		&issue.Description, &issue.WebURL, &issue.AuthorID, &issue.AuthorName,
+		&issue.AuthorUsername, &labelsJSON, &assigneesJSON,
+		&createdAt, &updatedAt, &closedAt,
+		&issue.DueDate, &issue.Weight, &confidential,
+		&issue.MilestoneTitle, &issue.MilestoneID, &issue.IterationID, &issue.TimeEstimate, &issue.TimeSpent, &issue.UserNotesCount,
+		&notesSyncedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return issue, err
+	}
+	issue.Confidential = confidential != 0
+	issue.CreatedAt = parseTime(createdAt)
+	issue.UpdatedAt = parseTime(updatedAt)
+	issue.ClosedAt = parseTime(closedAt)
+	issue.NotesSyncedAt = parseTime(notesSyncedAt)
+	_ = json.Unmarshal([]byte(labelsJSON), &issue.Labels)
+	_ = json.Unmarshal([]byte(assigneesJSON), &issue.Assignees)
+	return issue, nil
+}
@@ -0,0 +1,139 @@
+package store
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+)
+
+func (s *Store) UpsertLabels(labels []StoreLabel) error {
+	if len(labels) == 0 {
+		return nil
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO labels (id, name, color, text_color, description, group_id, project_id, synced_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			name=excluded.name, color=excluded.color, text_color=excluded.text_color,
+			description=excluded.description, group_id=excluded.group_id,
+			project_id=excluded.project_id, synced_at=excluded.synced_at`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, l := range labels {
+		if _, err := stmt.Exec(l.ID, l.Name, l.Color, l.TextColor, l.Description, l.GroupID, l.ProjectID, now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// DeleteStaleLabels removes labels not stored since syncedBefore, the start
+// of a sync that stored every label that still exists.
+func (s *Store) DeleteStaleLabels(syncedBefore time.Time) error {
+	_, err := s.db.Exec("DELETE FROM labels WHERE synced_at < ?", fmtTime(syncedBefore))
+	return err
+}
+
+// LabelCatalogue returns the stored labels by name. Where groups and
+// projects define a name differently, a project's definition wins.
+func (s *Store) LabelCatalogue() (map[string]StoreLabel, error) {
+	return s.labelCatalogue("")
+}
+
+// projectLabelCatalogue is LabelCatalogue for the labels a project can use:
+// its own and those of the groups above it.
+func (s *Store) projectLabelCatalogue(projectID int64) (map[string]StoreLabel, error) {
+	return s.labelCatalogue(`WHERE project_id = ? OR group_id IN (
+		SELECT g.id FROM groups g, projects p
+		WHERE p.id = ? AND substr(p.path_with_namespace, 1, length(g.full_path) + 1) = g.full_path || '/')`, projectID, projectID)
+}
+
+func (s *Store) labelCatalogue(where string, args ...any) (map[string]StoreLabel, error) {
+	rows, err := s.db.Query(`SELECT id, name, color, text_color, description, group_id, project_id
+		FROM labels `+where+` ORDER BY project_id != 0, id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	labels := map[string]StoreLabel{}
+	for rows.Next() {
+		var l StoreLabel
+		if err := rows.Scan(&l.ID, &l.Name, &l.Color, &l.TextColor, &l.Description, &l.GroupID, &l.ProjectID); err != nil {
+			return nil, err
+		}
+		labels[l.Name] = l
+	}
+	return labels, rows.Err()
+}
+
+// ListLabelUsage returns every label in the catalogue or on a stored issue
+// with its issue counts, the most used first.
+func (s *Store) ListLabelUsage() ([]LabelUsage, error) {
+	catalogue, err := s.LabelCatalogue()
+	if err != nil {
+		return nil, err
+	}
+	return s.labelUsage(catalogue, "")
+}
+
+// ListProjectLabelUsage is ListLabelUsage for one project: the labels it and
+// its groups define and those on its stored issues, counted over its issues
+// alone.
+func (s *Store) ListProjectLabelUsage(projectID int64) ([]LabelUsage, error) {
+	catalogue, err := s.projectLabelCatalogue(projectID)
+	if err != nil {
+		return nil, err
+	}
+	return s.labelUsage(catalogue, "AND i.project_id = ?", projectID)
+}
+
+// labelUsage counts the stored issues, narrowed by where, that carry each
+// label, and adds the labels of catalogue no issue carries.
+func (s *Store) labelUsage(catalogue map[string]StoreLabel, where string, args ...any) ([]LabelUsage, error) {
+	rows, err := s.db.Query(`SELECT j.value, SUM(i.state = 'opened'), SUM(i.state = 'closed')
+		FROM issues i, json_each(i.labels) j
+		WHERE i.deleted_at = '' `+where+`
+		GROUP BY j.value`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var usage []LabelUsage
+	for rows.Next() {
+		var u LabelUsage
+		if err := rows.Scan(&u.Name, &u.OpenIssues, &u.ClosedIssues); err != nil {
+			return nil, err
+		}
+		if l, ok := catalogue[u.Name]; ok {
+			u.StoreLabel = l
+			delete(catalogue, u.Name)
+		}
+		usage = append(usage, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for _, l := range catalogue {
+		usage = append(usage, LabelUsage{StoreLabel: l})
+	}
+	slices.SortFunc(usage, func(a, b LabelUsage) int {
+		if c := cmp.Compare(b.OpenIssues+b.ClosedIssues, a.OpenIssues+a.ClosedIssues); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return usage, nil
+}
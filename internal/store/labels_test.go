@@ -0,0 +1,93 @@
+package store
+
+import (
+	"slices"
+	"testing"
+)
+
+func seedLabels(t *testing.T, s *Store) {
+	t.Helper()
+	if err := s.UpsertGroups([]StoreGroup{
+		{ID: 1, FullPath: "acme"},
+		{ID: 2, FullPath: "acme/plat"},
+		{ID: 3, FullPath: "acme/pl"}, // a prefix of acme/plat, but not a parent
+		{ID: 4, FullPath: "other"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpsertProjects([]StoreProject{
+		{ID: 10, PathWithNamespace: "acme/plat/api"},
+		{ID: 20, PathWithNamespace: "other/web"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpsertLabels([]StoreLabel{
+		{ID: 1, Name: "bug", Color: "#ff0000", GroupID: 1},
+		{ID: 2, Name: "unused", GroupID: 1},
+		{ID: 3, Name: "team", GroupID: 2},
+		{ID: 4, Name: "pl-only", GroupID: 3},
+		{ID: 5, Name: "other-only", GroupID: 4},
+		{ID: 6, Name: "bug", Color: "#0000ff", ProjectID: 10},
+		{ID: 7, Name: "web-only", ProjectID: 20},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	none := []StoreAssignee{}
+	if err := s.UpsertIssues([]StoreIssue{
+		{ID: 1, IID: 1, ProjectID: 10, State: "opened", Labels: []string{"bug"}, Assignees: none},
+		{ID: 2, IID: 2, ProjectID: 10, State: "closed", Labels: []string{"bug", "team"}, Assignees: none},
+		{ID: 3, IID: 3, ProjectID: 10, State: "opened", Labels: []string{"stray"}, Assignees: none},
+		{ID: 4, IID: 1, ProjectID: 20, State: "opened", Labels: []string{"bug", "web-only"}, Assignees: none},
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func usageNames(usage []LabelUsage) []string {
+	names := make([]string, len(usage))
+	for i, u := range usage {
+		names[i] = u.Name
+	}
+	return names
+}
+
+func TestListLabelUsage(t *testing.T) {
+	s := openTestStore(t)
+	seedLabels(t, s)
+	usage, err := s.ListLabelUsage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"bug", "stray", "team", "web-only", "other-only", "pl-only", "unused"}
+	if got := usageNames(usage); !slices.Equal(got, want) {
+		t.Fatalf("got labels %v, want %v", got, want)
+	}
+	if bug := usage[0]; bug.OpenIssues != 2 || bug.ClosedIssues != 1 {
+		t.Errorf("bug: got %d open, %d closed; want 2 and 1", bug.OpenIssues, bug.ClosedIssues)
+	}
+}
+
+func TestListProjectLabelUsage(t *testing.T) {
+	s := openTestStore(t)
+	seedLabels(t, s)
+	usage, err := s.ListProjectLabelUsage(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only the project's issues count, and only its own and its groups'
+	// labels are listed.
+	want := []string{"bug", "stray", "team", "unused"}
+	if got := usageNames(usage); !slices.Equal(got, want) {
+		t.Fatalf("got labels %v, want %v", got, want)
+	}
+	bug := usage[0]
+	if bug.OpenIssues != 1 || bug.ClosedIssues != 1 {
+		t.Errorf("bug: got %d open, %d closed; want 1 and 1", bug.OpenIssues, bug.ClosedIssues)
+	}
+	if bug.Color != "#0000ff" {
+		t.Errorf("bug has colour %s, want the project's own #0000ff", bug.Color)
+	}
+	if team := usage[2]; team.ClosedIssues != 1 || team.GroupID != 2 {
+		t.Errorf("team: got %+v, want one closed issue and group 2's label", team)
+	}
+}
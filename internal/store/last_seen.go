@@ -0,0 +1,30 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// GetLastSeen returns when a view such as "dashboard" was last looked at,
+// or the zero time if never.
+func (s *Store) GetLastSeen(view string) (time.Time, error) {
+	var ts string
+	err := s.db.QueryRow("SELECT seen_at FROM last_seen WHERE view = ?", view).Scan(&ts)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return parseTime(ts), nil
+}
+
+// SetLastSeen records that a view was looked at at t.
+func (s *Store) SetLastSeen(view string, t time.Time) error {
+	_, err := s.db.Exec(`
+		INSERT INTO last_seen (view, seen_at) VALUES (?, ?)
+		ON CONFLICT(view) DO UPDATE SET seen_at = excluded.seen_at`,
+		view, fmtTime(t))
+	return err
+}
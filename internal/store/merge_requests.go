@@ -0,0 +1,178 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// UpsertMergeRequests inserts or updates merge requests. Detail columns
+// (pipeline and approvals) are only overwritten by records that carry a
+// DetailSyncedAt, so a list sync never erases what a detail fetch stored.
+func (s *Store) UpsertMergeRequests(mrs []StoreMergeRequest) error {
+	if len(mrs) == 0 {
+		return nil
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO merge_requests (id, iid, project_id, title, state, description, web_url,
+			source_branch, target_branch, draft, author_id, author_name, author_username,
+			labels, assignees, reviewers, detailed_merge_status, has_conflicts,
+			created_at, updated_at, merged_at, closed_at,
+			pipeline_id, pipeline_status, approvals_required, approvals_left, approved_by,
+			detail_synced_at, synced_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			iid=excluded.iid, project_id=excluded.project_id, title=excluded.title,
+			state=excluded.state, description=excluded.description, web_url=excluded.web_url,
+			source_branch=excluded.source_branch, target_branch=excluded.target_branch,
+			draft=excluded.draft, author_id=excluded.author_id, author_name=excluded.author_name,
+			author_username=excluded.author_username, labels=excluded.labels,
+			assignees=excluded.assignees, reviewers=excluded.reviewers,
+			detailed_merge_status=excluded.detailed_merge_status,
+			has_conflicts=excluded.has_conflicts, created_at=excluded.created_at,
+			updated_at=excluded.updated_at, merged_at=excluded.merged_at,
+			closed_at=excluded.closed_at,
+			pipeline_id=CASE WHEN excluded.detail_synced_at = '' THEN pipeline_id ELSE excluded.pipeline_id END,
+			pipeline_status=CASE WHEN excluded.detail_synced_at = '' THEN pipeline_status ELSE excluded.pipeline_status END,
+			approvals_required=CASE WHEN excluded.detail_synced_at = '' THEN approvals_required ELSE excluded.approvals_required END,
+			approvals_left=CASE WHEN excluded.detail_synced_at = '' THEN approvals_left ELSE excluded.approvals_left END,
+			approved_by=CASE WHEN excluded.detail_synced_at = '' THEN approved_by ELSE excluded.approved_by END,
+			detail_synced_at=CASE WHEN excluded.detail_synced_at = '' THEN detail_synced_at ELSE excluded.detail_synced_at END,
+			synced_at=excluded.synced_at, deleted_at=''`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, mr := range mrs {
+		labelsJSON, _ := json.Marshal(nonNil(mr.Labels))
+		assigneesJSON, _ := json.Marshal(nonNil(mr.Assignees))
+		reviewersJSON, _ := json.Marshal(nonNil(mr.Reviewers))
+		approvedByJSON, _ := json.Marshal(nonNil(mr.ApprovedBy))
+		_, err := stmt.Exec(
+			mr.ID, mr.IID, mr.ProjectID, mr.Title, mr.State, mr.Description, mr.WebURL,
+			mr.SourceBranch, mr.TargetBranch, boolToInt(mr.Draft),
+			mr.AuthorID, mr.AuthorName, mr.AuthorUsername,
+			string(labelsJSON), string(assigneesJSON), string(reviewersJSON),
+			mr.DetailedMergeStatus, boolToInt(mr.HasConflicts),
+			fmtTime(mr.CreatedAt), fmtTime(mr.UpdatedAt), fmtTime(mr.MergedAt), fmtTime(mr.ClosedAt),
+			mr.PipelineID, mr.PipelineStatus, mr.ApprovalsRequired, mr.ApprovalsLeft,
+			string(approvedByJSON), fmtTime(mr.DetailSyncedAt), now,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+const mergeRequestColumns = `id, iid, project_id, title, state, description, web_url,
+	source_branch, target_branch, draft, author_id, author_name, author_username,
+	labels, assignees, reviewers, detailed_merge_status, has_conflicts,
+	created_at, updated_at, merged_at, closed_at,
+	pipeline_id, pipeline_status, approvals_required, approvals_left, approved_by,
+	detail_synced_at`
+
+// ListMergeRequests returns merge requests, most recently updated first. A
+// non-empty state ("opened", "merged", "closed") narrows the listing.
+func (s *Store) ListMergeRequests(state string) ([]StoreMergeRequest, error) {
+	rows, err := s.db.Query(`SELECT `+mergeRequestColumns+`
+		FROM merge_requests WHERE (? = '' OR state = ?) AND deleted_at = '' ORDER BY updated_at DESC`, state, state)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mrs []StoreMergeRequest
+	for rows.Next() {
+		mr, err := scanMergeRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		mrs = append(mrs, mr)
+	}
+	return mrs, rows.Err()
+}
+
+// ListProjectMergeRequests returns the merge requests of one project, most
+// recently updated first, narrowed by state like ListMergeRequests.
+func (s *Store) ListProjectMergeRequests(projectID int64, state string) ([]StoreMergeRequest, error) {
+	rows, err := s.db.Query(`SELECT `+mergeRequestColumns+`
+		FROM merge_requests WHERE project_id = ? AND (? = '' OR state = ?) AND deleted_at = ''
+		ORDER BY updated_at DESC`, projectID, state, state)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mrs []StoreMergeRequest
+	for rows.Next() {
+		mr, err := scanMergeRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		mrs = append(mrs, mr)
+	}
+	return mrs, rows.Err()
+}
+
+func (s *Store) GetMergeRequest(projectID, iid int64) (StoreMergeRequest, error) {
+	mr, err := scanMergeRequest(s.db.QueryRow(`SELECT `+mergeRequestColumns+`
+		FROM merge_requests WHERE project_id = ? AND iid = ? AND deleted_at = ''`, projectID, iid))
+	if errors.Is(err, sql.ErrNoRows) {
+		return mr, ErrRecordNotFound
+	}
+	return mr, err
+}
+
+// DeleteStaleMergeRequests removes merge requests not stored since
+// syncedBefore.
+func (s *Store) DeleteStaleMergeRequests(syncedBefore time.Time) error {
+	_, err := s.db.Exec("DELETE FROM merge_requests WHERE synced_at < ?", fmtTime(syncedBefore))
+	return err
+}
+
+func scanMergeRequest(row interface{ Scan(...any) error }) (StoreMergeRequest, error) {
+	var mr StoreMergeRequest
+	var draft, hasConflicts int
+	var labelsJSON, assigneesJSON, reviewersJSON, approvedByJSON string
+	var createdAt, updatedAt, mergedAt, closedAt, detailSyncedAt string
+	if err := row.Scan(
+		&mr.ID, &mr.IID, &mr.ProjectID, &mr.Title, &mr.State, &mr.Description, &mr.WebURL,
+		&mr.SourceBranch, &mr.TargetBranch, &draft, &mr.AuthorID, &mr.AuthorName, &mr.AuthorUsername,
+		&labelsJSON, &assigneesJSON, &reviewersJSON, &mr.DetailedMergeStatus, &hasConflicts,
+		&createdAt, &updatedAt, &mergedAt, &closedAt,
+		&mr.PipelineID, &mr.PipelineStatus, &mr.ApprovalsRequired, &mr.ApprovalsLeft, &approvedByJSON,
+		&detailSyncedAt,
+	); err != nil {
+		return mr, err
+	}
+	mr.Draft = draft != 0
+	mr.HasConflicts = hasConflicts != 0
+	mr.CreatedAt = parseTime(createdAt)
+	mr.UpdatedAt = parseTime(updatedAt)
+	mr.MergedAt = parseTime(mergedAt)
+	mr.ClosedAt = parseTime(closedAt)
+	mr.DetailSyncedAt = parseTime(detailSyncedAt)
+	_ = json.Unmarshal([]byte(labelsJSON), &mr.Labels)
+	_ = json.Unmarshal([]byte(assigneesJSON), &mr.Assignees)
+	_ = json.Unmarshal([]byte(reviewersJSON), &mr.Reviewers)
+	_ = json.Unmarshal([]byte(approvedByJSON), &mr.ApprovedBy)
+	return mr, nil
+}
+
+// nonNil makes nil slices encode as [] rather than null.
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
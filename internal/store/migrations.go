@@ -93,6 +93,279 @@ var migrations = []string{
 		version INTEGER PRIMARY KEY,
 		applied_at TEXT NOT NULL
 	);`,
+
+	// v2: full-text index over issue titles and descriptions, kept in sync
+	// with the issues table by triggers.
+	`CREATE VIRTUAL TABLE IF NOT EXISTS issues_fts USING fts5(
+		title, description,
+		content='issues', content_rowid='id',
+		tokenize='unicode61 remove_diacritics 2',
+		prefix='2 3'
+	);
+
+	CREATE TRIGGER IF NOT EXISTS issues_fts_ai AFTER INSERT ON issues BEGIN
+		INSERT INTO issues_fts (rowid, title, description) VALUES (new.id, new.title, new.description);
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS issues_fts_ad AFTER DELETE ON issues BEGIN
+		INSERT INTO issues_fts (issues_fts, rowid, title, description) VALUES ('delete', old.id, old.title, old.description);
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS issues_fts_au AFTER UPDATE OF title, description ON issues BEGIN
+		INSERT INTO issues_fts (issues_fts, rowid, title, description) VALUES ('delete', old.id, old.title, old.description);
+		INSERT INTO issues_fts (rowid, title, description) VALUES (new.id, new.title, new.description);
+	END;
+
+	INSERT INTO issues_fts (issues_fts) VALUES ('rebuild');`,
+
+	// v3: merge requests
+	`CREATE TABLE IF NOT EXISTS merge_requests (
+		id INTEGER PRIMARY KEY,
+		iid INTEGER NOT NULL DEFAULT 0,
+		project_id INTEGER NOT NULL DEFAULT 0,
+		title TEXT NOT NULL DEFAULT '',
+		state TEXT NOT NULL DEFAULT '',
+		description TEXT NOT NULL DEFAULT '',
+		web_url TEXT NOT NULL DEFAULT '',
+		source_branch TEXT NOT NULL DEFAULT '',
+		target_branch TEXT NOT NULL DEFAULT '',
+		draft INTEGER NOT NULL DEFAULT 0,
+		author_id INTEGER NOT NULL DEFAULT 0,
+		author_name TEXT NOT NULL DEFAULT '',
+		author_username TEXT NOT NULL DEFAULT '',
+		labels TEXT NOT NULL DEFAULT '[]',
+		assignees TEXT NOT NULL DEFAULT '[]',
+		reviewers TEXT NOT NULL DEFAULT '[]',
+		detailed_merge_status TEXT NOT NULL DEFAULT '',
+		has_conflicts INTEGER NOT NULL DEFAULT 0,
+		created_at TEXT NOT NULL DEFAULT '',
+		updated_at TEXT NOT NULL DEFAULT '',
+		merged_at TEXT NOT NULL DEFAULT '',
+		closed_at TEXT NOT NULL DEFAULT '',
+		pipeline_id INTEGER NOT NULL DEFAULT 0,
+		pipeline_status TEXT NOT NULL DEFAULT '',
+		approvals_required INTEGER NOT NULL DEFAULT 0,
+		approvals_left INTEGER NOT NULL DEFAULT 0,
+		approved_by TEXT NOT NULL DEFAULT '[]',
+		detail_synced_at TEXT NOT NULL DEFAULT '',
+		synced_at TEXT NOT NULL DEFAULT '',
+		UNIQUE(project_id, iid)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_merge_requests_state ON merge_requests(state);
+	CREATE INDEX IF NOT EXISTS idx_merge_requests_updated_at ON merge_requests(updated_at);`,
+
+	// v4: issue detail metadata, discussion notes and linked issues
+	`ALTER TABLE issues ADD COLUMN milestone_title TEXT NOT NULL DEFAULT '';
+	ALTER TABLE issues ADD COLUMN time_estimate INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE issues ADD COLUMN time_spent INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE issues ADD COLUMN user_notes_count INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE issues ADD COLUMN notes_synced_at TEXT NOT NULL DEFAULT '';
+
+	CREATE TABLE IF NOT EXISTS issue_notes (
+		id INTEGER PRIMARY KEY,
+		issue_id INTEGER NOT NULL,
+		body TEXT NOT NULL DEFAULT '',
+		author_id INTEGER NOT NULL DEFAULT 0,
+		author_name TEXT NOT NULL DEFAULT '',
+		author_username TEXT NOT NULL DEFAULT '',
+		system INTEGER NOT NULL DEFAULT 0,
+		internal INTEGER NOT NULL DEFAULT 0,
+		created_at TEXT NOT NULL DEFAULT '',
+		updated_at TEXT NOT NULL DEFAULT ''
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_issue_notes_issue_id ON issue_notes(issue_id);
+
+	CREATE TABLE IF NOT EXISTS issue_links (
+		issue_id INTEGER NOT NULL,
+		linked_issue_id INTEGER NOT NULL,
+		link_type TEXT NOT NULL DEFAULT '',
+		project_id INTEGER NOT NULL DEFAULT 0,
+		iid INTEGER NOT NULL DEFAULT 0,
+		title TEXT NOT NULL DEFAULT '',
+		state TEXT NOT NULL DEFAULT '',
+		reference TEXT NOT NULL DEFAULT '',
+		web_url TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY (issue_id, linked_issue_id)
+	);`,
+
+	// v5: outbox of issue writes made while offline
+	`CREATE TABLE IF NOT EXISTS pending_ops (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		kind TEXT NOT NULL,
+		project_id INTEGER NOT NULL,
+		issue_id INTEGER NOT NULL,
+		iid INTEGER NOT NULL,
+		payload TEXT NOT NULL DEFAULT '{}',
+		base_updated_at TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL DEFAULT 'pending',
+		attempts INTEGER NOT NULL DEFAULT 0,
+		last_error TEXT NOT NULL DEFAULT '',
+		created_at TEXT NOT NULL DEFAULT ''
+	);`,
+
+	// v6: where an interrupted full sync of each resource stopped
+	`ALTER TABLE sync_meta ADD COLUMN cursor_page INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE sync_meta ADD COLUMN cursor_started_at TEXT NOT NULL DEFAULT '';`,
+
+	// v7: soft deletion found by reconciling, and what the last one found
+	`ALTER TABLE groups ADD COLUMN deleted_at TEXT NOT NULL DEFAULT '';
+	ALTER TABLE projects ADD COLUMN deleted_at TEXT NOT NULL DEFAULT '';
+	ALTER TABLE issues ADD COLUMN deleted_at TEXT NOT NULL DEFAULT '';
+	ALTER TABLE merge_requests ADD COLUMN deleted_at TEXT NOT NULL DEFAULT '';
+
+	ALTER TABLE sync_meta ADD COLUMN reconciled_at TEXT NOT NULL DEFAULT '';
+	ALTER TABLE sync_meta ADD COLUMN drift_removed INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE sync_meta ADD COLUMN drift_missing INTEGER NOT NULL DEFAULT 0;`,
+
+	// v8: newest server-side change stored per resource
+	`ALTER TABLE sync_meta ADD COLUMN watermark TEXT NOT NULL DEFAULT '';`,
+
+	// v9: which issue sync scopes each issue belongs to. Issues stored so
+	// far came from groups or from the only scope there was, and the state of
+	// its syncs moves to the scope's own key.
+	`CREATE TABLE IF NOT EXISTS issue_scopes (
+		scope TEXT NOT NULL,
+		issue_id INTEGER NOT NULL,
+		synced_at TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY (scope, issue_id)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_issue_scopes_issue_id ON issue_scopes(issue_id);
+
+	INSERT INTO issue_scopes (scope, issue_id, synced_at)
+		SELECT 'assigned', id, synced_at FROM issues
+		WHERE id NOT IN (SELECT issue_id FROM group_issues);
+
+	INSERT INTO sync_meta (resource_type, last_synced_at, full_sync, watermark)
+		SELECT 'issues:assigned', last_synced_at, full_sync, watermark FROM sync_meta
+		WHERE resource_type = 'issues';`,
+
+	// v10: group and project milestones, group iterations, and the ones
+	// each issue belongs to. Issues synced before are linked by title once
+	// milestones are synced.
+	`CREATE TABLE IF NOT EXISTS milestones (
+		id INTEGER PRIMARY KEY,
+		iid INTEGER NOT NULL DEFAULT 0,
+		group_id INTEGER NOT NULL DEFAULT 0,
+		project_id INTEGER NOT NULL DEFAULT 0,
+		title TEXT NOT NULL DEFAULT '',
+		description TEXT NOT NULL DEFAULT '',
+		state TEXT NOT NULL DEFAULT '',
+		start_date TEXT NOT NULL DEFAULT '',
+		due_date TEXT NOT NULL DEFAULT '',
+		web_url TEXT NOT NULL DEFAULT '',
+		created_at TEXT NOT NULL DEFAULT '',
+		updated_at TEXT NOT NULL DEFAULT '',
+		synced_at TEXT NOT NULL DEFAULT ''
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_milestones_title ON milestones(title);
+
+	CREATE TABLE IF NOT EXISTS iterations (
+		id INTEGER PRIMARY KEY,
+		iid INTEGER NOT NULL DEFAULT 0,
+		sequence INTEGER NOT NULL DEFAULT 0,
+		group_id INTEGER NOT NULL DEFAULT 0,
+		title TEXT NOT NULL DEFAULT '',
+		description TEXT NOT NULL DEFAULT '',
+		state TEXT NOT NULL DEFAULT '',
+		start_date TEXT NOT NULL DEFAULT '',
+		due_date TEXT NOT NULL DEFAULT '',
+		web_url TEXT NOT NULL DEFAULT '',
+		created_at TEXT NOT NULL DEFAULT '',
+		updated_at TEXT NOT NULL DEFAULT '',
+		synced_at TEXT NOT NULL DEFAULT ''
+	);
+
+	ALTER TABLE issues ADD COLUMN milestone_id INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE issues ADD COLUMN iteration_id INTEGER NOT NULL DEFAULT 0;
+
+	CREATE INDEX IF NOT EXISTS idx_issues_milestone_id ON issues(milestone_id);
+	CREATE INDEX IF NOT EXISTS idx_issues_iteration_id ON issues(iteration_id);`,
+
+	// v11: the label catalogue of groups and projects, with colours
+	`CREATE TABLE IF NOT EXISTS labels (
+		id INTEGER PRIMARY KEY,
+		name TEXT NOT NULL DEFAULT '',
+		color TEXT NOT NULL DEFAULT '',
+		text_color TEXT NOT NULL DEFAULT '',
+		description TEXT NOT NULL DEFAULT '',
+		group_id INTEGER NOT NULL DEFAULT 0,
+		project_id INTEGER NOT NULL DEFAULT 0,
+		synced_at TEXT NOT NULL DEFAULT ''
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_labels_name ON labels(name);`,
+
+	// v12: recent pipelines of projects and their jobs
+	`CREATE TABLE IF NOT EXISTS pipelines (
+		id INTEGER PRIMARY KEY,
+		iid INTEGER NOT NULL DEFAULT 0,
+		project_id INTEGER NOT NULL DEFAULT 0,
+		status TEXT NOT NULL DEFAULT '',
+		source TEXT NOT NULL DEFAULT '',
+		ref TEXT NOT NULL DEFAULT '',
+		sha TEXT NOT NULL DEFAULT '',
+		web_url TEXT NOT NULL DEFAULT '',
+		author_username TEXT NOT NULL DEFAULT '',
+		author_name TEXT NOT NULL DEFAULT '',
+		duration INTEGER NOT NULL DEFAULT 0,
+		created_at TEXT NOT NULL DEFAULT '',
+		updated_at TEXT NOT NULL DEFAULT '',
+		started_at TEXT NOT NULL DEFAULT '',
+		finished_at TEXT NOT NULL DEFAULT '',
+		synced_at TEXT NOT NULL DEFAULT ''
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_pipelines_project_created ON pipelines(project_id, created_at);
+
+	CREATE TABLE IF NOT EXISTS jobs (
+		id INTEGER PRIMARY KEY,
+		project_id INTEGER NOT NULL DEFAULT 0,
+		pipeline_id INTEGER NOT NULL DEFAULT 0,
+		name TEXT NOT NULL DEFAULT '',
+		stage TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL DEFAULT '',
+		ref TEXT NOT NULL DEFAULT '',
+		allow_failure INTEGER NOT NULL DEFAULT 0,
+		failure_reason TEXT NOT NULL DEFAULT '',
+		duration INTEGER NOT NULL DEFAULT 0,
+		web_url TEXT NOT NULL DEFAULT '',
+		created_at TEXT NOT NULL DEFAULT '',
+		started_at TEXT NOT NULL DEFAULT '',
+		finished_at TEXT NOT NULL DEFAULT '',
+		synced_at TEXT NOT NULL DEFAULT ''
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_jobs_pipeline_id ON jobs(pipeline_id);`,
+
+	// v13: when each summary view was last looked at
+	`CREATE TABLE IF NOT EXISTS last_seen (
+		view TEXT PRIMARY KEY,
+		seen_at TEXT NOT NULL DEFAULT ''
+	);`,
+
+	// v14: the current user's to-do items
+	`CREATE TABLE IF NOT EXISTS todos (
+		id INTEGER PRIMARY KEY,
+		project_id INTEGER NOT NULL DEFAULT 0,
+		project_path TEXT NOT NULL DEFAULT '',
+		author_username TEXT NOT NULL DEFAULT '',
+		action_name TEXT NOT NULL DEFAULT '',
+		target_type TEXT NOT NULL DEFAULT '',
+		target_iid INTEGER NOT NULL DEFAULT 0,
+		target_title TEXT NOT NULL DEFAULT '',
+		target_state TEXT NOT NULL DEFAULT '',
+		target_url TEXT NOT NULL DEFAULT '',
+		body TEXT NOT NULL DEFAULT '',
+		state TEXT NOT NULL DEFAULT '',
+		created_at TEXT NOT NULL DEFAULT '',
+		synced_at TEXT NOT NULL DEFAULT ''
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_todos_state ON todos(state);`,
 }
 
 func (s *Store) migrate() error {
@@ -0,0 +1,209 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+func (s *Store) UpsertMilestones(milestones []StoreMilestone) error {
+	if len(milestones) == 0 {
+		return nil
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO milestones (id, iid, group_id, project_id, title, description, state,
+			start_date, due_date, web_url, created_at, updated_at, synced_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			iid=excluded.iid, group_id=excluded.group_id, project_id=excluded.project_id,
+			title=excluded.title, description=excluded.description, state=excluded.state,
+			start_date=excluded.start_date, due_date=excluded.due_date,
+			web_url=excluded.web_url, created_at=excluded.created_at,
+			updated_at=excluded.updated_at, synced_at=excluded.synced_at`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, m := range milestones {
+		_, err := stmt.Exec(m.ID, m.IID, m.GroupID, m.ProjectID, m.Title, m.Description, m.State,
+			m.StartDate, m.DueDate, m.WebURL, fmtTime(m.CreatedAt), fmtTime(m.UpdatedAt), now)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *Store) UpsertIterations(iterations []StoreIteration) error {
+	if len(iterations) == 0 {
+		return nil
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO iterations (id, iid, sequence, group_id, title, description, state,
+			start_date, due_date, web_url, created_at, updated_at, synced_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			iid=excluded.iid, sequence=excluded.sequence, group_id=excluded.group_id,
+			title=excluded.title, description=excluded.description, state=excluded.state,
+			start_date=excluded.start_date, due_date=excluded.due_date,
+			web_url=excluded.web_url, created_at=excluded.created_at,
+			updated_at=excluded.updated_at, synced_at=excluded.synced_at`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, it := range iterations {
+		_, err := stmt.Exec(it.ID, it.IID, it.Sequence, it.GroupID, it.Title, it.Description, it.State,
+			it.StartDate, it.DueDate, it.WebURL, fmtTime(it.CreatedAt), fmtTime(it.UpdatedAt), now)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// DeleteStaleMilestones removes milestones not stored since syncedBefore,
+// the start of a sync that stored every milestone that still exists.
+func (s *Store) DeleteStaleMilestones(syncedBefore time.Time) error {
+	_, err := s.db.Exec("DELETE FROM milestones WHERE synced_at < ?", fmtTime(syncedBefore))
+	return err
+}
+
+// DeleteStaleIterations is DeleteStaleMilestones for iterations.
+func (s *Store) DeleteStaleIterations(syncedBefore time.Time) error {
+	_, err := s.db.Exec("DELETE FROM iterations WHERE synced_at < ?", fmtTime(syncedBefore))
+	return err
+}
+
+// LinkMilestoneTitles links issues stored before milestones were synced to
+// the milestone of their project, or of the project's group, with the
+// title they carry.
+func (s *Store) LinkMilestoneTitles() error {
+	_, err := s.db.Exec(`UPDATE issues SET milestone_id = COALESCE((
+			SELECT m.id FROM milestones m
+			WHERE m.title = issues.milestone_title
+				AND (m.project_id = issues.project_id
+					OR m.group_id = (SELECT namespace_id FROM projects WHERE id = issues.project_id))
+			ORDER BY m.project_id DESC LIMIT 1), 0)
+		WHERE milestone_id = 0 AND milestone_title != ''`)
+	return err
+}
+
+const milestoneColumns = `id, iid, group_id, project_id, title, description, state,
+	start_date, due_date, web_url, created_at, updated_at`
+
+func (s *Store) GetMilestone(id int64) (StoreMilestone, error) {
+	ms, err := s.queryMilestones(`SELECT `+milestoneColumns+` FROM milestones WHERE id = ?`, id)
+	if err != nil {
+		return StoreMilestone{}, err
+	}
+	if len(ms) == 0 {
+		return StoreMilestone{}, ErrRecordNotFound
+	}
+	return ms[0], nil
+}
+
+// FindMilestones returns the milestones titled title, ignoring case, the
+// open ones first.
+func (s *Store) FindMilestones(title string) ([]StoreMilestone, error) {
+	return s.queryMilestones(`SELECT `+milestoneColumns+` FROM milestones
+		WHERE title = ? COLLATE NOCASE ORDER BY state = 'closed', due_date DESC`, title)
+}
+
+// ListProjectMilestones returns the milestones of a project and of the
+// group it belongs to.
+func (s *Store) ListProjectMilestones(projectID int64) ([]StoreMilestone, error) {
+	return s.queryMilestones(`SELECT `+milestoneColumns+` FROM milestones
+		WHERE project_id = ? OR group_id = (SELECT namespace_id FROM projects WHERE id = ?)
+		ORDER BY state = 'closed', due_date = '', due_date, title`, projectID, projectID)
+}
+
+func (s *Store) queryMilestones(query string, args ...any) ([]StoreMilestone, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var milestones []StoreMilestone
+	for rows.Next() {
+		var m StoreMilestone
+		var createdAt, updatedAt string
+		if err := rows.Scan(&m.ID, &m.IID, &m.GroupID, &m.ProjectID, &m.Title, &m.Description, &m.State,
+			&m.StartDate, &m.DueDate, &m.WebURL, &createdAt, &updatedAt); err != nil {
+			return nil, err
+		}
+		m.CreatedAt = parseTime(createdAt)
+		m.UpdatedAt = parseTime(updatedAt)
+		milestones = append(milestones, m)
+	}
+	return milestones, rows.Err()
+}
+
+const iterationColumns = `id, iid, sequence, group_id, title, description, state,
+	start_date, due_date, web_url, created_at, updated_at`
+
+func (s *Store) GetIteration(id int64) (StoreIteration, error) {
+	var it StoreIteration
+	var createdAt, updatedAt string
+	err := s.db.QueryRow(`SELECT `+iterationColumns+` FROM iterations WHERE id = ?`, id).Scan(
+		&it.ID, &it.IID, &it.Sequence, &it.GroupID, &it.Title, &it.Description, &it.State,
+		&it.StartDate, &it.DueDate, &it.WebURL, &createdAt, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return it, ErrRecordNotFound
+	}
+	it.CreatedAt = parseTime(createdAt)
+	it.UpdatedAt = parseTime(updatedAt)
+	return it, err
+}
+
+// FindIteration returns the most recent iteration titled title, ignoring
+// case.
+func (s *Store) FindIteration(title string) (StoreIteration, error) {
+	var id int64
+	err := s.db.QueryRow(`SELECT id FROM iterations WHERE title = ? COLLATE NOCASE
+		ORDER BY start_date DESC LIMIT 1`, title).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return StoreIteration{}, ErrRecordNotFound
+	}
+	if err != nil {
+		return StoreIteration{}, err
+	}
+	return s.GetIteration(id)
+}
+
+// ListMilestoneIssues returns the issues of a milestone.
+func (s *Store) ListMilestoneIssues(milestoneID int64) ([]StoreIssue, error) {
+	return s.listIssuesWhere("milestone_id = ?", milestoneID)
+}
+
+// ListIterationIssues returns the issues of an iteration.
+func (s *Store) ListIterationIssues(iterationID int64) ([]StoreIssue, error) {
+	return s.listIssuesWhere("iteration_id = ?", iterationID)
+}
+
+func (s *Store) listIssuesWhere(cond string, args ...any) ([]StoreIssue, error) {
+	rows, err := s.db.Query(`SELECT `+issueColumns+` FROM issues
+		WHERE `+cond+` AND deleted_at = '' ORDER BY state, closed_at`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanIssues(rows)
+}
@@ -1,38 +1,168 @@
 package store
 
-import "time"
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
 
 type StoreGroup struct {
-	ID          int64
-	Name        string
-	Path        string
-	FullName    string
-	FullPath    string
-	Description string
-	Visibility  string
-	WebURL      string
-	ParentID    int64
-	CreatedAt   time.Time
-	SyncedAt    time.Time
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	Path        string    `json:"path"`
+	FullName    string    `json:"full_name"`
+	FullPath    string    `json:"full_path"`
+	Description string    `json:"description"`
+	Visibility  string    `json:"visibility"`
+	WebURL      string    `json:"web_url"`
+	ParentID    int64     `json:"parent_id"`
+	CreatedAt   time.Time `json:"created_at,omitzero"`
+	SyncedAt    time.Time `json:"synced_at,omitzero"`
 }
 
 type StoreProject struct {
-	ID                int64
-	Name              string
-	Path              string
-	PathWithNamespace string
-	NameWithNamespace string
-	Description       string
-	DefaultBranch     string
-	Visibility        string
-	WebURL            string
-	NamespaceID       int64
-	CreatedAt         time.Time
-	UpdatedAt         time.Time
-	LastActivityAt    time.Time
-	Archived          bool
-	OpenIssuesCount   int64
-	SyncedAt          time.Time
+	ID                int64     `json:"id"`
+	Name              string    `json:"name"`
+	Path              string    `json:"path"`
+	PathWithNamespace string    `json:"path_with_namespace"`
+	NameWithNamespace string    `json:"name_with_namespace"`
+	Description       string    `json:"description"`
+	DefaultBranch     string    `json:"default_branch"`
+	Visibility        string    `json:"visibility"`
+	WebURL            string    `json:"web_url"`
+	NamespaceID       int64     `json:"namespace_id"`
+	CreatedAt         time.Time `json:"created_at,omitzero"`
+	UpdatedAt         time.Time `json:"updated_at,omitzero"`
+	LastActivityAt    time.Time `json:"last_activity_at,omitzero"`
+	Archived          bool      `json:"archived"`
+	OpenIssuesCount   int64     `json:"open_issues_count"`
+	SyncedAt          time.Time `json:"synced_at,omitzero"`
+}
+
+// StoreMilestone is a group or project milestone. Exactly one of GroupID
+// and ProjectID is set.
+type StoreMilestone struct {
+	ID          int64     `json:"id"`
+	IID         int64     `json:"iid"`
+	GroupID     int64     `json:"group_id"`
+	ProjectID   int64     `json:"project_id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	State       string    `json:"state"`
+	StartDate   string    `json:"start_date"`
+	DueDate     string    `json:"due_date"`
+	WebURL      string    `json:"web_url"`
+	CreatedAt   time.Time `json:"created_at,omitzero"`
+	UpdatedAt   time.Time `json:"updated_at,omitzero"`
+}
+
+// StoreIteration is a group iteration (sprint). State is "upcoming",
+// "current" or "closed".
+type StoreIteration struct {
+	ID          int64     `json:"id"`
+	IID         int64     `json:"iid"`
+	Sequence    int64     `json:"sequence"`
+	GroupID     int64     `json:"group_id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	State       string    `json:"state"`
+	StartDate   string    `json:"start_date"`
+	DueDate     string    `json:"due_date"`
+	WebURL      string    `json:"web_url"`
+	CreatedAt   time.Time `json:"created_at,omitzero"`
+	UpdatedAt   time.Time `json:"updated_at,omitzero"`
+}
+
+// StoreLabel is a group or project label. Exactly one of GroupID and
+// ProjectID is set.
+type StoreLabel struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Color       string `json:"color"`      // e.g. "#428bca"
+	TextColor   string `json:"text_color"` // what GitLab writes the name in
+	Description string `json:"description"`
+	GroupID     int64  `json:"group_id"`
+	ProjectID   int64  `json:"project_id"`
+}
+
+// Scope splits a scoped label such as "priority::high" into its scope and
+// value. Nested scopes stay in the scope: "a::b::c" is scope "a::b".
+func (l StoreLabel) Scope() (scope, value string, ok bool) {
+	i := strings.LastIndex(l.Name, "::")
+	if i < 0 {
+		return "", l.Name, false
+	}
+	return l.Name[:i], l.Name[i+2:], true
+}
+
+// LabelUsage is a label with how many stored issues carry it. Labels found
+// on issues but not in the catalogue have no colour.
+type LabelUsage struct {
+	StoreLabel
+	OpenIssues   int64 `json:"open_issues"`
+	ClosedIssues int64 `json:"closed_issues"`
+}
+
+// PathRef is the ID and full path of a group or project.
+type PathRef struct {
+	ID   int64  `json:"id"`
+	Path string `json:"path"`
+}
+
+// StoreTodo is an item on the current user's GitLab To-Do list. State is
+// "pending" or "done"; TargetType is e.g. "Issue" or "MergeRequest".
+type StoreTodo struct {
+	ID             int64     `json:"id"`
+	ProjectID      int64     `json:"project_id"`
+	ProjectPath    string    `json:"project_path"`
+	AuthorUsername string    `json:"author_username"`
+	ActionName     string    `json:"action_name"`
+	TargetType     string    `json:"target_type"`
+	TargetIID      int64     `json:"target_iid"`
+	TargetTitle    string    `json:"target_title"`
+	TargetState    string    `json:"target_state"`
+	TargetURL      string    `json:"target_url"`
+	Body           string    `json:"body"`
+	State          string    `json:"state"`
+	CreatedAt      time.Time `json:"created_at,omitzero"`
+}
+
+// StorePipeline is a CI pipeline of a project. Duration is in seconds and
+// the author is whoever triggered it.
+type StorePipeline struct {
+	ID             int64     `json:"id"`
+	IID            int64     `json:"iid"`
+	ProjectID      int64     `json:"project_id"`
+	Status         string    `json:"status"`
+	Source         string    `json:"source"`
+	Ref            string    `json:"ref"`
+	SHA            string    `json:"sha"`
+	WebURL         string    `json:"web_url"`
+	AuthorUsername string    `json:"author_username"`
+	AuthorName     string    `json:"author_name"`
+	Duration       int64     `json:"duration"`
+	CreatedAt      time.Time `json:"created_at,omitzero"`
+	UpdatedAt      time.Time `json:"updated_at,omitzero"`
+	StartedAt      time.Time `json:"started_at,omitzero"`
+	FinishedAt     time.Time `json:"finished_at,omitzero"`
+}
+
+// StoreJob is one job of a pipeline. Duration is in whole seconds.
+type StoreJob struct {
+	ID            int64     `json:"id"`
+	ProjectID     int64     `json:"project_id"`
+	PipelineID    int64     `json:"pipeline_id"`
+	Name          string    `json:"name"`
+	Stage         string    `json:"stage"`
+	Status        string    `json:"status"`
+	Ref           string    `json:"ref"`
+	AllowFailure  bool      `json:"allow_failure"`
+	FailureReason string    `json:"failure_reason"`
+	Duration      int64     `json:"duration"`
+	WebURL        string    `json:"web_url"`
+	CreatedAt     time.Time `json:"created_at,omitzero"`
+	StartedAt     time.Time `json:"started_at,omitzero"`
+	FinishedAt    time.Time `json:"finished_at,omitzero"`
 }
 
 type StoreAssignee struct {
@@ -42,32 +172,121 @@ type StoreAssignee struct {
 }
 
 type StoreIssue struct {
-	ID             int64
-	IID            int64
-	ProjectID      int64
-	Title          string
-	State          string
-	Description    string
-	WebURL         string
-	AuthorID       int64
-	AuthorName     string
-	AuthorUsername string
-	Labels         []string
-	Assignees      []StoreAssignee
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
-	ClosedAt       time.Time
-	DueDate        string
-	Weight         int64
-	Confidential   bool
-	SyncedAt       time.Time
+	ID             int64           `json:"id"`
+	IID            int64           `json:"iid"`
+	ProjectID      int64           `json:"project_id"`
+	Title          string          `json:"title"`
+	State          string          `json:"state"`
+	Description    string          `json:"description"`
+	WebURL         string          `json:"web_url"`
+	AuthorID       int64           `json:"author_id"`
+	AuthorName     string          `json:"author_name"`
+	AuthorUsername string          `json:"author_username"`
+	Labels         []string        `json:"labels"`
+	Assignees      []StoreAssignee `json:"assignees"`
+	CreatedAt      time.Time       `json:"created_at,omitzero"`
+	UpdatedAt      time.Time       `json:"updated_at,omitzero"`
+	ClosedAt       time.Time       `json:"closed_at,omitzero"`
+	DueDate        string          `json:"due_date"`
+	Weight         int64           `json:"weight"`
+	Confidential   bool            `json:"confidential"`
+	MilestoneTitle string          `json:"milestone_title"`
+	MilestoneID    int64           `json:"milestone_id"`
+	IterationID    int64           `json:"iteration_id"`
+	TimeEstimate   int64           `json:"time_estimate"` // seconds
+	TimeSpent      int64           `json:"time_spent"`    // seconds
+	UserNotesCount int64           `json:"user_notes_count"`
+	NotesSyncedAt  time.Time       `json:"notes_synced_at,omitzero"`
+	SyncedAt       time.Time       `json:"synced_at,omitzero"`
+}
+
+type StoreIssueNote struct {
+	ID             int64     `json:"id"`
+	IssueID        int64     `json:"issue_id"`
+	Body           string    `json:"body"`
+	AuthorID       int64     `json:"author_id"`
+	AuthorName     string    `json:"author_name"`
+	AuthorUsername string    `json:"author_username"`
+	System         bool      `json:"system"`
+	Internal       bool      `json:"internal"`
+	CreatedAt      time.Time `json:"created_at,omitzero"`
+	UpdatedAt      time.Time `json:"updated_at,omitzero"`
+}
+
+// StoreIssueLink is an issue related to another issue ("relates_to",
+// "blocks" or "is_blocked_by").
+type StoreIssueLink struct {
+	IssueID       int64  `json:"issue_id"`
+	LinkedIssueID int64  `json:"linked_issue_id"`
+	LinkType      string `json:"link_type"`
+	ProjectID     int64  `json:"project_id"`
+	IID           int64  `json:"iid"`
+	Title         string `json:"title"`
+	State         string `json:"state"`
+	Reference     string `json:"reference"`
+	WebURL        string `json:"web_url"`
 }
 
 type StoreUser struct {
-	ID       int64
-	Name     string
-	Username string
-	Email    string
-	WebURL   string
-	SyncedAt time.Time
+	ID       int64     `json:"id"`
+	Name     string    `json:"name"`
+	Username string    `json:"username"`
+	Email    string    `json:"email"`
+	WebURL   string    `json:"web_url"`
+	SyncedAt time.Time `json:"synced_at,omitzero"`
+}
+
+type StoreMergeRequest struct {
+	ID                  int64           `json:"id"`
+	IID                 int64           `json:"iid"`
+	ProjectID           int64           `json:"project_id"`
+	Title               string          `json:"title"`
+	State               string          `json:"state"`
+	Description         string          `json:"description"`
+	WebURL              string          `json:"web_url"`
+	SourceBranch        string          `json:"source_branch"`
+	TargetBranch        string          `json:"target_branch"`
+	Draft               bool            `json:"draft"`
+	AuthorID            int64           `json:"author_id"`
+	AuthorName          string          `json:"author_name"`
+	AuthorUsername      string          `json:"author_username"`
+	Labels              []string        `json:"labels"`
+	Assignees           []StoreAssignee `json:"assignees"`
+	Reviewers           []StoreAssignee `json:"reviewers"`
+	DetailedMergeStatus string          `json:"detailed_merge_status"`
+	HasConflicts        bool            `json:"has_conflicts"`
+	CreatedAt           time.Time       `json:"created_at,omitzero"`
+	UpdatedAt           time.Time       `json:"updated_at,omitzero"`
+	MergedAt            time.Time       `json:"merged_at,omitzero"`
+	ClosedAt            time.Time       `json:"closed_at,omitzero"`
+
+	// Detail fields come from the single-MR and approvals endpoints and are
+	// only meaningful when DetailSyncedAt is set.
+	PipelineID        int64           `json:"pipeline_id"`
+	PipelineStatus    string          `json:"pipeline_status"`
+	ApprovalsRequired int64           `json:"approvals_required"`
+	ApprovalsLeft     int64           `json:"approvals_left"`
+	ApprovedBy        []StoreAssignee `json:"approved_by"`
+	DetailSyncedAt    time.Time       `json:"detail_synced_at,omitzero"`
+	SyncedAt          time.Time       `json:"synced_at,omitzero"`
+}
+
+// StorePendingOp is an issue write made while GitLab was unreachable,
+// waiting in the outbox to be replayed.
+type StorePendingOp struct {
+	ID            int64           `json:"id"`
+	Kind          string          `json:"kind"`
+	ProjectID     int64           `json:"project_id"`
+	IssueID       int64           `json:"issue_id"`
+	IID           int64           `json:"iid"`
+	Payload       json.RawMessage `json:"payload"`                  // interpreted by glclient
+	BaseUpdatedAt time.Time       `json:"base_updated_at,omitzero"` // issue updated_at the write was based on
+	Status        string          `json:"status"`
+	Attempts      int64           `json:"attempts"`
+	LastError     string          `json:"last_error"`
+	CreatedAt     time.Time       `json:"created_at,omitzero"`
+
+	// Title and WebURL are read from the stored issue for display.
+	Title  string `json:"title"`
+	WebURL string `json:"web_url"`
 }
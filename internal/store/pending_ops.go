@@ -0,0 +1,119 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Pending operation states. Only OpPending operations are replayed; the
+// others wait for a retry or drop.
+const (
+	OpPending  = "pending"
+	OpConflict = "conflict" // the issue changed on the server after the write was queued
+	OpFailed   = "failed"   // the server rejected the write
+)
+
+// EnqueueOp appends op to the outbox and returns its ID.
+func (s *Store) EnqueueOp(op StorePendingOp) (int64, error) {
+	payload := string(op.Payload)
+	if payload == "" {
+		payload = "{}"
+	}
+	res, err := s.db.Exec(`INSERT INTO pending_ops (kind, project_id, issue_id, iid, payload,
+		base_updated_at, status, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		op.Kind, op.ProjectID, op.IssueID, op.IID, payload, fmtTime(op.BaseUpdatedAt),
+		OpPending, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+const pendingOpColumns = `o.id, o.kind, o.project_id, o.issue_id, o.iid, o.payload,
+	o.base_updated_at, o.status, o.attempts, o.last_error, o.created_at,
+	COALESCE(i.title, ''), COALESCE(i.web_url, '')`
+
+const pendingOpFrom = ` FROM pending_ops o LEFT JOIN issues i ON i.id = o.issue_id`
+
+// ListPendingOps returns the whole outbox in the order it was written.
+func (s *Store) ListPendingOps() ([]StorePendingOp, error) {
+	rows, err := s.db.Query(`SELECT ` + pendingOpColumns + pendingOpFrom + ` ORDER BY o.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ops []StorePendingOp
+	for rows.Next() {
+		op, err := scanPendingOp(rows)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, op)
+	}
+	return ops, rows.Err()
+}
+
+func (s *Store) GetPendingOp(id int64) (StorePendingOp, error) {
+	op, err := scanPendingOp(s.db.QueryRow(`SELECT `+pendingOpColumns+pendingOpFrom+` WHERE o.id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return op, ErrRecordNotFound
+	}
+	return op, err
+}
+
+// CountPendingOps reports how many operations in the outbox have the given
+// status.
+func (s *Store) CountPendingOps(status string) (int, error) {
+	var n int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM pending_ops WHERE status = ?", status).Scan(&n)
+	return n, err
+}
+
+// MarkPendingOp records a failed replay attempt.
+func (s *Store) MarkPendingOp(id int64, status, lastError string) error {
+	_, err := s.db.Exec(`UPDATE pending_ops SET status = ?, last_error = ?, attempts = attempts + 1
+		WHERE id = ?`, status, lastError, id)
+	return err
+}
+
+// RetryPendingOp puts an operation back in the pending state. Its base
+// updated_at is cleared, so the next replay applies it over any server-side
+// change instead of reporting a conflict again.
+func (s *Store) RetryPendingOp(id int64) error {
+	res, err := s.db.Exec(`UPDATE pending_ops SET status = ?, last_error = '', base_updated_at = ''
+		WHERE id = ?`, OpPending, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+func (s *Store) DeletePendingOp(id int64) error {
+	res, err := s.db.Exec("DELETE FROM pending_ops WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+func scanPendingOp(row interface{ Scan(...any) error }) (StorePendingOp, error) {
+	var op StorePendingOp
+	var payload, baseUpdatedAt, createdAt string
+	if err := row.Scan(&op.ID, &op.Kind, &op.ProjectID, &op.IssueID, &op.IID, &payload,
+		&baseUpdatedAt, &op.Status, &op.Attempts, &op.LastError, &createdAt,
+		&op.Title, &op.WebURL); err != nil {
+		return op, err
+	}
+	op.Payload = []byte(payload)
+	op.BaseUpdatedAt = parseTime(baseUpdatedAt)
+	op.CreatedAt = parseTime(createdAt)
+	return op, nil
+}
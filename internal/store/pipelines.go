@@ -0,0 +1,200 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+func (s *Store) UpsertPipelines(pipelines []StorePipeline) error {
+	if len(pipelines) == 0 {
+		return nil
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO pipelines (id, iid, project_id, status, source, ref, sha, web_url,
+			author_username, author_name, duration,
+			created_at, updated_at, started_at, finished_at, synced_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			iid=excluded.iid, project_id=excluded.project_id, status=excluded.status,
+			source=excluded.source, ref=excluded.ref, sha=excluded.sha, web_url=excluded.web_url,
+			author_username=excluded.author_username, author_name=excluded.author_name,
+			duration=excluded.duration, created_at=excluded.created_at,
+			updated_at=excluded.updated_at, started_at=excluded.started_at,
+			finished_at=excluded.finished_at, synced_at=excluded.synced_at`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, p := range pipelines {
+		_, err := stmt.Exec(p.ID, p.IID, p.ProjectID, p.Status, p.Source, p.Ref, p.SHA, p.WebURL,
+			p.AuthorUsername, p.AuthorName, p.Duration,
+			fmtTime(p.CreatedAt), fmtTime(p.UpdatedAt), fmtTime(p.StartedAt), fmtTime(p.FinishedAt), now)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *Store) UpsertJobs(jobs []StoreJob) error {
+	if len(jobs) == 0 {
+		return nil
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO jobs (id, project_id, pipeline_id, name, stage, status, ref,
+			allow_failure, failure_reason, duration, web_url,
+			created_at, started_at, finished_at, synced_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			project_id=excluded.project_id, pipeline_id=excluded.pipeline_id,
+			name=excluded.name, stage=excluded.stage, status=excluded.status, ref=excluded.ref,
+			allow_failure=excluded.allow_failure, failure_reason=excluded.failure_reason,
+			duration=excluded.duration, web_url=excluded.web_url,
+			created_at=excluded.created_at, started_at=excluded.started_at,
+			finished_at=excluded.finished_at, synced_at=excluded.synced_at`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, j := range jobs {
+		_, err := stmt.Exec(j.ID, j.ProjectID, j.PipelineID, j.Name, j.Stage, j.Status, j.Ref,
+			boolToInt(j.AllowFailure), j.FailureReason, j.Duration, j.WebURL,
+			fmtTime(j.CreatedAt), fmtTime(j.StartedAt), fmtTime(j.FinishedAt), now)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// PruneProjectPipelines keeps the keep most recent pipelines of a project,
+// deleting older ones together with their jobs.
+func (s *Store) PruneProjectPipelines(projectID int64, keep int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	const old = `SELECT id FROM pipelines WHERE project_id = ?
+		ORDER BY created_at DESC, id DESC LIMIT -1 OFFSET ?`
+	if _, err := tx.Exec(`DELETE FROM jobs WHERE pipeline_id IN (`+old+`)`, projectID, keep); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM pipelines WHERE id IN (`+old+`)`, projectID, keep); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+const pipelineColumns = `id, iid, project_id, status, source, ref, sha, web_url,
+	author_username, author_name, duration, created_at, updated_at, started_at, finished_at`
+
+// ListProjectPipelines returns the limit most recent pipelines of a
+// project, newest first.
+func (s *Store) ListProjectPipelines(projectID int64, limit int) ([]StorePipeline, error) {
+	rows, err := s.db.Query(`SELECT `+pipelineColumns+` FROM pipelines WHERE project_id = ?
+		ORDER BY created_at DESC, id DESC LIMIT ?`, projectID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pipelines []StorePipeline
+	for rows.Next() {
+		p, err := scanPipeline(rows)
+		if err != nil {
+			return nil, err
+		}
+		pipelines = append(pipelines, p)
+	}
+	return pipelines, rows.Err()
+}
+
+func (s *Store) GetPipeline(id int64) (StorePipeline, error) {
+	p, err := scanPipeline(s.db.QueryRow(`SELECT `+pipelineColumns+` FROM pipelines WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return p, ErrRecordNotFound
+	}
+	return p, err
+}
+
+func scanPipeline(row interface{ Scan(...any) error }) (StorePipeline, error) {
+	var p StorePipeline
+	var createdAt, updatedAt, startedAt, finishedAt string
+	if err := row.Scan(&p.ID, &p.IID, &p.ProjectID, &p.Status, &p.Source, &p.Ref, &p.SHA, &p.WebURL,
+		&p.AuthorUsername, &p.AuthorName, &p.Duration,
+		&createdAt, &updatedAt, &startedAt, &finishedAt); err != nil {
+		return p, err
+	}
+	p.CreatedAt = parseTime(createdAt)
+	p.UpdatedAt = parseTime(updatedAt)
+	p.StartedAt = parseTime(startedAt)
+	p.FinishedAt = parseTime(finishedAt)
+	return p, nil
+}
+
+const jobColumns = `id, project_id, pipeline_id, name, stage, status, ref,
+	allow_failure, failure_reason, duration, web_url, created_at, started_at, finished_at`
+
+// ListPipelineJobs returns the jobs of a pipeline in the order they were
+// created, which is stage order.
+func (s *Store) ListPipelineJobs(pipelineID int64) ([]StoreJob, error) {
+	rows, err := s.db.Query(`SELECT `+jobColumns+` FROM jobs WHERE pipeline_id = ?
+		ORDER BY created_at, id`, pipelineID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []StoreJob
+	for rows.Next() {
+		j, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, rows.Err()
+}
+
+func (s *Store) GetJob(id int64) (StoreJob, error) {
+	j, err := scanJob(s.db.QueryRow(`SELECT `+jobColumns+` FROM jobs WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return j, ErrRecordNotFound
+	}
+	return j, err
+}
+
+func scanJob(row interface{ Scan(...any) error }) (StoreJob, error) {
+	var j StoreJob
+	var allowFailure int
+	var createdAt, startedAt, finishedAt string
+	if err := row.Scan(&j.ID, &j.ProjectID, &j.PipelineID, &j.Name, &j.Stage, &j.Status, &j.Ref,
+		&allowFailure, &j.FailureReason, &j.Duration, &j.WebURL,
+		&createdAt, &startedAt, &finishedAt); err != nil {
+		return j, err
+	}
+	j.AllowFailure = allowFailure != 0
+	j.CreatedAt = parseTime(createdAt)
+	j.StartedAt = parseTime(startedAt)
+	j.FinishedAt = parseTime(finishedAt)
+	return j, nil
+}
@@ -27,7 +27,7 @@ func (s *Store) UpsertProjects(projects []StoreProject) error {
 			namespace_id=excluded.namespace_id, created_at=excluded.created_at,
 			updated_at=excluded.updated_at, last_activity_at=excluded.last_activity_at,
 			archived=excluded.archived, open_issues_count=excluded.open_issues_count,
-			synced_at=excluded.synced_at`)
+			synced_at=excluded.synced_at, deleted_at=''`)
 	if err != nil {
 		return err
 	}
@@ -49,7 +49,7 @@ func (s *Store) UpsertProjects(projects []StoreProject) error {
 func (s *Store) ListProjects() ([]StoreProject, error) {
 	rows, err := s.db.Query(`SELECT id, name, path, path_with_namespace, name_with_namespace,
 		description, default_branch, visibility, web_url, namespace_id, archived, open_issues_count
-		FROM projects WHERE archived = 0 ORDER BY name`)
+		FROM projects WHERE archived = 0 AND deleted_at = '' ORDER BY name`)
 	if err != nil {
 		return nil, err
 	}
@@ -75,7 +75,7 @@ func (s *Store) GetProject(id int64) (StoreProject, error) {
 	var archived int
 	err := s.db.QueryRow(`SELECT id, name, path, path_with_namespace, name_with_namespace,
 		description, default_branch, visibility, web_url, namespace_id, archived, open_issues_count
-		FROM projects WHERE id = ?`, id,
+		FROM projects WHERE id = ? AND deleted_at = ''`, id,
 	).Scan(&p.ID, &p.Name, &p.Path, &p.PathWithNamespace, &p.NameWithNamespace,
 		&p.Description, &p.DefaultBranch, &p.Visibility, &p.WebURL, &p.NamespaceID,
 		&archived, &p.OpenIssuesCount)
@@ -86,39 +86,28 @@ func (s *Store) GetProject(id int64) (StoreProject, error) {
 	return p, err
 }
 
-func (s *Store) DeleteStaleProjects(activeIDs []int64) error {
-	if len(activeIDs) == 0 {
-		_, err := s.db.Exec("DELETE FROM projects")
-		return err
-	}
-	tx, err := s.db.Begin()
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
-
-	if _, err := tx.Exec("CREATE TEMP TABLE _active_projects (id INTEGER PRIMARY KEY)"); err != nil {
-		return err
-	}
-	stmt, err := tx.Prepare("INSERT INTO _active_projects (id) VALUES (?)")
-	if err != nil {
-		return err
-	}
-	for _, id := range activeIDs {
-		if _, err := stmt.Exec(id); err != nil {
-			stmt.Close()
-			return err
-		}
+// GetProjectByPath looks a project up by its path_with_namespace, ignoring
+// case.
+func (s *Store) GetProjectByPath(path string) (StoreProject, error) {
+	var p StoreProject
+	var archived int
+	err := s.db.QueryRow(`SELECT id, name, path, path_with_namespace, name_with_namespace,
+		description, default_branch, visibility, web_url, namespace_id, archived, open_issues_count
+		FROM projects WHERE path_with_namespace = ? COLLATE NOCASE AND deleted_at = ''`, path,
+	).Scan(&p.ID, &p.Name, &p.Path, &p.PathWithNamespace, &p.NameWithNamespace,
+		&p.Description, &p.DefaultBranch, &p.Visibility, &p.WebURL, &p.NamespaceID,
+		&archived, &p.OpenIssuesCount)
+	if errors.Is(err, sql.ErrNoRows) {
+		return p, ErrRecordNotFound
 	}
-	stmt.Close()
+	p.Archived = archived != 0
+	return p, err
+}
 
-	if _, err := tx.Exec("DELETE FROM projects WHERE id NOT IN (SELECT id FROM _active_projects)"); err != nil {
-		return err
-	}
-	if _, err := tx.Exec("DROP TABLE _active_projects"); err != nil {
-		return err
-	}
-	return tx.Commit()
+// DeleteStaleProjects removes projects not stored since syncedBefore.
+func (s *Store) DeleteStaleProjects(syncedBefore time.Time) error {
+	_, err := s.db.Exec("DELETE FROM projects WHERE synced_at < ?", fmtTime(syncedBefore))
+	return err
 }
 
 func fmtTime(t time.Time) string {
@@ -128,9 +117,25 @@ func fmtTime(t time.Time) string {
 	return t.UTC().Format(time.RFC3339)
 }
 
+// parseTime is the inverse of fmtTime; empty or malformed values yield the
+// zero time.
+func parseTime(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
 func boolToInt(b bool) int {
 	if b {
 		return 1
 	}
 	return 0
 }
+
+// ListProjectPaths returns the ID and path_with_namespace of every stored
+// project, archived ones included, for resolving and completing names.
+func (s *Store) ListProjectPaths() ([]PathRef, error) {
+	return s.listPaths("SELECT id, path_with_namespace FROM projects WHERE deleted_at = '' ORDER BY path_with_namespace")
+}
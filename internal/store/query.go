@@ -0,0 +1,391 @@
+package store
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IssueQuery is a compiled issue filter expression. Build one with
+// ParseIssueQuery and run it with Store.QueryIssues.
+type IssueQuery struct {
+	where []string
+	args  []any
+	order []string
+	limit int
+}
+
+// meUsername is substituted for the "@me" shorthand.
+const meUsername = "(SELECT username FROM current_user LIMIT 1)"
+
+// ParseIssueQuery compiles filter terms such as
+//
+//	state:opened label:bug assignee:@me project:foo/bar due<2026-11-01 sort:-weight
+//
+// into parameterized SQL over the issues table. Supported keys:
+//
+//	state:opened|closed|all      label:<name>|none|any   assignee:<user>|@me|none|any
+//	author:<user>|@me            project:<id|path>       group:<id|full_path>
+//	milestone:<title>|none|any   iid:<n>                 weight<op><n>
+//	confidential:true|false      sort:[-]<field>         limit:<n>
+//	due|created|updated|closed<op><date>
+//
+// <op> is one of : = < > <= >=. Dates are YYYY-MM-DD, today, yesterday,
+// tomorrow or a relative offset such as -7d or +2w; "none" matches unset
+// dates. Label, assignee, author and milestone values ending in * match by prefix. A
+// leading - negates a filter, and bare words match title or description.
+func ParseIssueQuery(terms []string) (IssueQuery, error) {
+	var q IssueQuery
+	for _, term := range terms {
+		if term == "" {
+			continue
+		}
+		negate := false
+		if len(term) > 1 && term[0] == '-' {
+			negate = true
+			term = term[1:]
+		}
+
+		key, op, value, ok := splitTerm(term)
+		if !ok {
+			q.addText(term, negate)
+			continue
+		}
+		if value == "" {
+			return q, fmt.Errorf("missing value in %q", term)
+		}
+
+		var err error
+		switch key {
+		case "sort", "order":
+			err = q.addSort(value)
+		case "limit":
+			q.limit, err = strconv.Atoi(value)
+			if err == nil && q.limit <= 0 {
+				err = fmt.Errorf("limit must be positive")
+			}
+		default:
+			var cond string
+			var args []any
+			cond, args, err = compileFilter(key, op, value)
+			if err == nil {
+				if negate {
+					cond = "NOT (" + cond + ")"
+				}
+				q.where = append(q.where, cond)
+				q.args = append(q.args, args...)
+			}
+		}
+		if err != nil {
+			return q, fmt.Errorf("%s: %w", term, err)
+		}
+	}
+	return q, nil
+}
+
+// SQL returns the query text and its bind arguments.
+func (q IssueQuery) SQL() (string, []any) {
+	var b strings.Builder
+	b.WriteString(`SELECT ` + issueColumns + ` FROM issues WHERE deleted_at = ''`)
+	for _, cond := range q.where {
+		b.WriteString(" AND ")
+		b.WriteString(cond)
+	}
+	order := q.order
+	if len(order) == 0 {
+		order = []string{"updated_at DESC"}
+	}
+	b.WriteString(" ORDER BY ")
+	b.WriteString(strings.Join(order, ", "))
+	args := q.args
+	if q.limit > 0 {
+		b.WriteString(" LIMIT ?")
+		args = append(args[:len(args):len(args)], q.limit)
+	}
+	return b.String(), args
+}
+
+// QueryIssues returns the issues matching q.
+func (s *Store) QueryIssues(q IssueQuery) ([]StoreIssue, error) {
+	query, args := q.SQL()
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanIssues(rows)
+}
+
+// splitTerm splits "key<op>value". It reports false for bare words.
+func splitTerm(term string) (key, op, value string, ok bool) {
+	i := strings.IndexAny(term, ":=<>")
+	if i <= 0 {
+		return "", "", "", false
+	}
+	key = strings.ToLower(term[:i])
+	rest := term[i:]
+	for _, o := range []string{"<=", ">=", ":", "=", "<", ">"} {
+		if strings.HasPrefix(rest, o) {
+			op = o
+			break
+		}
+	}
+	value = rest[len(op):]
+	// Allow "due:<2026-11-01" as well as "due<2026-11-01".
+	if op == ":" {
+		for _, o := range []string{"<=", ">=", "<", ">", "="} {
+			if strings.HasPrefix(value, o) {
+				op, value = o, value[len(o):]
+				break
+			}
+		}
+	}
+	if op == ":" {
+		op = "="
+	}
+	return key, op, value, true
+}
+
+func (q *IssueQuery) addText(word string, negate bool) {
+	cond := "(title LIKE ? ESCAPE '\\' OR description LIKE ? ESCAPE '\\')"
+	if negate {
+		cond = "NOT " + cond
+	}
+	pattern := "%" + escapeLike(word) + "%"
+	q.where = append(q.where, cond)
+	q.args = append(q.args, pattern, pattern)
+}
+
+var sortColumns = map[string]string{
+	"updated": "updated_at",
+	"created": "created_at",
+	"closed":  "closed_at",
+	"due":     "due_date",
+	"weight":  "weight",
+	"title":   "title COLLATE NOCASE",
+	"iid":     "iid",
+	"state":   "state",
+}
+
+func (q *IssueQuery) addSort(value string) error {
+	dir := "ASC"
+	if strings.HasPrefix(value, "-") {
+		dir = "DESC"
+		value = value[1:]
+	}
+	col, ok := sortColumns[strings.TrimSuffix(value, "_at")]
+	if !ok {
+		return fmt.Errorf("unknown sort field %q", value)
+	}
+	if col == "due_date" || col == "closed_at" {
+		// Unset dates sort last regardless of direction.
+		q.order = append(q.order, col+" = ''")
+	}
+	q.order = append(q.order, col+" "+dir)
+	return nil
+}
+
+func compileFilter(key, op, value string) (string, []any, error) {
+	switch key {
+	case "state":
+		if err := equalityOnly(op); err != nil {
+			return "", nil, err
+		}
+		switch value {
+		case "all":
+			return "1 = 1", nil, nil
+		case "open":
+			value = "opened"
+		}
+		return "state = ?", []any{value}, nil
+
+	case "label", "labels":
+		if err := equalityOnly(op); err != nil {
+			return "", nil, err
+		}
+		switch value {
+		case "none":
+			return "labels = '[]'", nil, nil
+		case "any":
+			return "labels != '[]'", nil, nil
+		}
+		cond, arg := matchValue("value", value)
+		return "EXISTS (SELECT 1 FROM json_each(issues.labels) WHERE " + cond + ")", []any{arg}, nil
+
+	case "assignee", "assignees":
+		if err := equalityOnly(op); err != nil {
+			return "", nil, err
+		}
+		switch value {
+		case "none":
+			return "assignees = '[]'", nil, nil
+		case "any":
+			return "assignees != '[]'", nil, nil
+		case "@me":
+			return "EXISTS (SELECT 1 FROM json_each(issues.assignees) WHERE json_extract(value, '$.username') = " + meUsername + ")", nil, nil
+		}
+		cond, arg := matchValue("json_extract(value, '$.username')", strings.TrimPrefix(value, "@"))
+		return "EXISTS (SELECT 1 FROM json_each(issues.assignees) WHERE " + cond + ")", []any{arg}, nil
+
+	case "author":
+		if err := equalityOnly(op); err != nil {
+			return "", nil, err
+		}
+		if value == "@me" {
+			return "author_username = " + meUsername, nil, nil
+		}
+		cond, arg := matchValue("author_username", strings.TrimPrefix(value, "@"))
+		return cond, []any{arg}, nil
+
+	case "project":
+		if err := equalityOnly(op); err != nil {
+			return "", nil, err
+		}
+		if id, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return "project_id = ?", []any{id}, nil
+		}
+		return "project_id IN (SELECT id FROM projects WHERE path_with_namespace = ? COLLATE NOCASE)", []any{value}, nil
+
+	case "group":
+		if err := equalityOnly(op); err != nil {
+			return "", nil, err
+		}
+		if id, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return `(id IN (SELECT issue_id FROM group_issues WHERE group_id = ?)
+				OR project_id IN (SELECT p.id FROM projects p JOIN groups g ON p.path_with_namespace LIKE g.full_path || '/%' WHERE g.id = ?))`,
+				[]any{id, id}, nil
+		}
+		return "project_id IN (SELECT id FROM projects WHERE path_with_namespace LIKE ? ESCAPE '\\')",
+			[]any{escapeLike(value) + "/%"}, nil
+
+	case "milestone":
+		if err := equalityOnly(op); err != nil {
+			return "", nil, err
+		}
+		switch value {
+		case "none":
+			return "milestone_title = ''", nil, nil
+		case "any":
+			return "milestone_title != ''", nil, nil
+		}
+		cond, arg := matchValue("milestone_title", value)
+		return cond, []any{arg}, nil
+
+	case "iid":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return "", nil, fmt.Errorf("iid must be a number")
+		}
+		return "iid " + op + " ?", []any{n}, nil
+
+	case "weight":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return "", nil, fmt.Errorf("weight must be a number")
+		}
+		return "weight " + op + " ?", []any{n}, nil
+
+	case "confidential":
+		if err := equalityOnly(op); err != nil {
+			return "", nil, err
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", nil, fmt.Errorf("confidential must be true or false")
+		}
+		return "confidential = ?", []any{boolToInt(b)}, nil
+
+	case "due", "created", "updated", "closed":
+		return compileDate(key, op, value)
+	}
+	return "", nil, fmt.Errorf("unknown filter %q", key)
+}
+
+func compileDate(key, op, value string) (string, []any, error) {
+	col := key + "_at"
+	if key == "due" {
+		col = "due_date"
+	}
+	if value == "none" {
+		if err := equalityOnly(op); err != nil {
+			return "", nil, err
+		}
+		return col + " = ''", nil, nil
+	}
+	day, err := parseQueryDate(value)
+	if err != nil {
+		return "", nil, err
+	}
+	if key == "due" {
+		return col + " != '' AND " + col + " " + op + " ?", []any{day.Format(time.DateOnly)}, nil
+	}
+	// Timestamps are stored as UTC RFC 3339, so the local day is compared
+	// as the UTC instants it starts and ends at.
+	start, end := fmtTime(day), fmtTime(day.AddDate(0, 0, 1))
+	switch op {
+	case "<":
+		return col + " != '' AND " + col + " < ?", []any{start}, nil
+	case "<=":
+		return col + " != '' AND " + col + " < ?", []any{end}, nil
+	case ">":
+		return col + " >= ?", []any{end}, nil
+	case ">=":
+		return col + " >= ?", []any{start}, nil
+	}
+	return col + " >= ? AND " + col + " < ?", []any{start, end}, nil
+}
+
+// parseQueryDate resolves absolute and relative dates to the start of that
+// day. Days are local, as due dates are.
+func parseQueryDate(value string) (time.Time, error) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	switch value {
+	case "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return t, nil
+	}
+	if len(value) >= 3 && (value[0] == '-' || value[0] == '+') {
+		n, err := strconv.Atoi(value[1 : len(value)-1])
+		if err == nil {
+			if value[0] == '-' {
+				n = -n
+			}
+			switch value[len(value)-1] {
+			case 'd':
+				return today.AddDate(0, 0, n), nil
+			case 'w':
+				return today.AddDate(0, 0, 7*n), nil
+			case 'm':
+				return today.AddDate(0, n, 0), nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q (want YYYY-MM-DD, today or an offset like -7d)", value)
+}
+
+func equalityOnly(op string) error {
+	if op != "=" {
+		return fmt.Errorf("operator %q not supported here", op)
+	}
+	return nil
+}
+
+// matchValue compares expr to value, by prefix when value ends in "*".
+func matchValue(expr, value string) (string, any) {
+	if strings.HasSuffix(value, "*") {
+		return expr + " LIKE ? ESCAPE '\\'", escapeLike(strings.TrimSuffix(value, "*")) + "%"
+	}
+	return expr + " = ?", value
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
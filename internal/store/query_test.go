@@ -0,0 +1,283 @@
+package store
+
+import (
+	"database/sql"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// openTestStore opens a migrated in-memory store. The pool is held to one
+// connection because every SQLite connection to :memory: is its own
+// database.
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	s := &Store{db: db, path: ":memory:"}
+	t.Cleanup(func() { s.Close() })
+	if err := s.migrate(); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func day(offset int) string {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day()+offset, 0, 0, 0, 0, time.Local).Format(time.DateOnly)
+}
+
+func seedQueryIssues(t *testing.T, s *Store) {
+	t.Helper()
+	if err := s.UpsertUser(StoreUser{ID: 1, Username: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpsertProjects([]StoreProject{
+		{ID: 10, Path: "api", PathWithNamespace: "acme/api"},
+		{ID: 20, Path: "web", PathWithNamespace: "acme/web"},
+		{ID: 30, Path: "api", PathWithNamespace: "other/api"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	alice := []StoreAssignee{{ID: 1, Username: "alice"}}
+	bob := []StoreAssignee{{ID: 2, Username: "bob"}}
+	updated := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	if err := s.UpsertIssues([]StoreIssue{
+		{ID: 1, IID: 1, ProjectID: 10, Title: "Crash on login", State: "opened", AuthorUsername: "alice",
+			Labels: []string{"bug", "backend"}, Assignees: alice, DueDate: day(-1), Weight: 3,
+			MilestoneTitle: "v1.0", UpdatedAt: updated},
+		{ID: 2, IID: 2, ProjectID: 10, Title: "Docs for 100% coverage", State: "closed", AuthorUsername: "bob",
+			Labels: []string{"docs"}, Assignees: []StoreAssignee{}, Weight: 1,
+			UpdatedAt: updated.AddDate(0, 0, 1), ClosedAt: updated.AddDate(0, 0, 1)},
+		{ID: 3, IID: 1, ProjectID: 20, Title: "Button colour", State: "opened", AuthorUsername: "bob",
+			Description: "the login button", Labels: []string{}, Assignees: bob, DueDate: day(0),
+			MilestoneTitle: "v1.1", Confidential: true, UpdatedAt: updated.AddDate(0, 0, 2)},
+		{ID: 4, IID: 5, ProjectID: 30, Title: "Rate limits", State: "opened", AuthorUsername: "carol",
+			Labels: []string{"bug-report"}, Assignees: alice, DueDate: day(9), Weight: 5,
+			UpdatedAt: updated.AddDate(0, 0, -3)},
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQueryIssues(t *testing.T) {
+	s := openTestStore(t)
+	seedQueryIssues(t, s)
+
+	tests := []struct {
+		query string
+		want  []int64 // issue IDs, in order
+	}{
+		{"", []int64{3, 2, 1, 4}},
+		{"state:opened", []int64{3, 1, 4}},
+		{"state:open", []int64{3, 1, 4}},
+		{"state:all", []int64{3, 2, 1, 4}},
+		{"-state:opened", []int64{2}},
+		{"label:bug", []int64{1}},
+		{"label:bug*", []int64{1, 4}},
+		{"label:none", []int64{3}},
+		{"label:any", []int64{2, 1, 4}},
+		{"-label:bug", []int64{3, 2, 4}},
+		{"assignee:@me", []int64{1, 4}},
+		{"assignee:@bob", []int64{3}},
+		{"assignee:none", []int64{2}},
+		{"-assignee:any", []int64{2}},
+		{"author:@me", []int64{1}},
+		{"author:b*", []int64{3, 2}},
+		{"project:10", []int64{2, 1}},
+		{"project:ACME/web", []int64{3}},
+		{"group:acme", []int64{3, 2, 1}},
+		{"milestone:v1*", []int64{3, 1}},
+		{"milestone:none", []int64{2, 4}},
+		{"iid>1", []int64{2, 4}},
+		{"weight>=3", []int64{1, 4}},
+		{"weight:<3", []int64{3, 2}},
+		{"confidential:true", []int64{3}},
+		{"due<today", []int64{1}},
+		{"due:today", []int64{3}},
+		{"due<=tomorrow", []int64{3, 1}},
+		{"due>+1w", []int64{4}},
+		{"due:none", []int64{2}},
+		{"-due:none", []int64{3, 1, 4}},
+		{"updated:2026-10-02", []int64{2}},
+		{"updated>=2026-10-01", []int64{3, 2, 1}},
+		{"closed:none", []int64{3, 1, 4}},
+		{"login", []int64{3, 1}},
+		{"-login", []int64{2, 4}},
+		{"100%", []int64{2}},
+		{"state:opened login", []int64{3, 1}},
+		{"sort:weight", []int64{3, 2, 1, 4}},
+		{"sort:-weight", []int64{4, 1, 2, 3}},
+		{"sort:due", []int64{1, 3, 4, 2}},
+		{"sort:-due_at", []int64{4, 3, 1, 2}},
+		{"sort:-weight limit:2", []int64{4, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := ParseIssueQuery(strings.Fields(tt.query))
+			if err != nil {
+				t.Fatalf("ParseIssueQuery: %v", err)
+			}
+			issues, err := s.QueryIssues(q)
+			if err != nil {
+				t.Fatalf("QueryIssues: %v", err)
+			}
+			got := make([]int64, len(issues))
+			for i, issue := range issues {
+				got[i] = issue.ID
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got issues %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryIssuesSkipsDeleted(t *testing.T) {
+	s := openTestStore(t)
+	seedQueryIssues(t, s)
+	if _, err := s.db.Exec("UPDATE issues SET deleted_at = ? WHERE id = 1", fmtTime(time.Now())); err != nil {
+		t.Fatal(err)
+	}
+	q, err := ParseIssueQuery([]string{"label:bug"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	issues, err := s.QueryIssues(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("got %d issues, want the deleted one left out", len(issues))
+	}
+}
+
+func TestParseIssueQueryErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"state:", "missing value"},
+		{"label<bug", `operator "<" not supported`},
+		{"state>=opened", `operator ">=" not supported`},
+		{"milestone>v1", `operator ">" not supported`},
+		{"due<none", `operator "<" not supported`},
+		{"colour:red", `unknown filter "colour"`},
+		{"iid:abc", "iid must be a number"},
+		{"weight>heavy", "weight must be a number"},
+		{"confidential:maybe", "confidential must be true or false"},
+		{"due:soon", `invalid date "soon"`},
+		{"due<-7x", `invalid date "-7x"`},
+		{"updated:2026-13-01", "invalid date"},
+		{"sort:colour", `unknown sort field "colour"`},
+		{"limit:0", "limit must be positive"},
+		{"limit:many", "invalid syntax"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := ParseIssueQuery([]string{tt.query})
+			if err == nil {
+				t.Fatalf("ParseIssueQuery(%q) succeeded, want error", tt.query)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %q does not contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQueryDate(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"today", day(0)},
+		{"yesterday", day(-1)},
+		{"tomorrow", day(1)},
+		{"-7d", day(-7)},
+		{"+2w", day(14)},
+		{"2026-02-28", "2026-02-28"},
+	}
+	for _, tt := range tests {
+		got, err := parseQueryDate(tt.value)
+		if err != nil {
+			t.Errorf("parseQueryDate(%q): %v", tt.value, err)
+			continue
+		}
+		if got.Format(time.DateOnly) != tt.want || got.Location() != time.Local {
+			t.Errorf("parseQueryDate(%q) = %s, want the start of local day %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestQueryIssuesLocalDays(t *testing.T) {
+	// Days are local, while timestamps are stored in UTC: in UTC+2, 21:30Z
+	// is late on October 1 and 22:30Z early on October 2.
+	defer func(loc *time.Location) { time.Local = loc }(time.Local)
+	time.Local = time.FixedZone("UTC+2", 2*60*60)
+
+	s := openTestStore(t)
+	if err := s.UpsertProjects([]StoreProject{{ID: 10, Path: "api", PathWithNamespace: "acme/api"}}); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	if err := s.UpsertIssues([]StoreIssue{
+		{ID: 1, IID: 1, ProjectID: 10, Title: "late", State: "opened", Labels: []string{}, Assignees: []StoreAssignee{},
+			CreatedAt: time.Date(2026, 10, 1, 21, 30, 0, 0, time.UTC), UpdatedAt: now},
+		{ID: 2, IID: 2, ProjectID: 10, Title: "early", State: "opened", Labels: []string{}, Assignees: []StoreAssignee{},
+			CreatedAt: time.Date(2026, 10, 1, 22, 30, 0, 0, time.UTC), UpdatedAt: now.AddDate(0, 0, -1)},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query string
+		want  []int64
+	}{
+		{"created:2026-10-01", []int64{1}},
+		{"created:2026-10-02", []int64{2}},
+		{"created<2026-10-02", []int64{1}},
+		{"created<=2026-10-01", []int64{1}},
+		{"created>2026-10-01", []int64{2}},
+		{"created>=2026-10-02", []int64{2}},
+		{"updated:today", []int64{1}},
+		{"updated:yesterday", []int64{2}},
+		{"updated>yesterday", []int64{1}},
+		{"closed<today", nil},
+	}
+	for _, tt := range tests {
+		q, err := ParseIssueQuery([]string{tt.query})
+		if err != nil {
+			t.Fatalf("ParseIssueQuery(%q): %v", tt.query, err)
+		}
+		issues, err := s.QueryIssues(q)
+		if err != nil {
+			t.Fatalf("QueryIssues(%q): %v", tt.query, err)
+		}
+		var got []int64
+		for _, issue := range issues {
+			got = append(got, issue.ID)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got issues %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseQueryDateIsLocal(t *testing.T) {
+	// For ten hours of each day, the date in UTC+10 is a day ahead of UTC.
+	defer func(loc *time.Location) { time.Local = loc }(time.Local)
+	time.Local = time.FixedZone("UTC+10", 10*60*60)
+	now := time.Now().In(time.Local)
+	got, err := parseQueryDate("today")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := now.Format(time.DateOnly); got.Format(time.DateOnly) != want {
+		t.Errorf("today = %s, want the local date %s", got, want)
+	}
+}
@@ -0,0 +1,170 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// reconcileTables are the tables Reconcile can compare against GitLab, by
+// resource type.
+var reconcileTables = map[string]string{
+	"groups":         "groups",
+	"projects":       "projects",
+	"issues":         "issues",
+	"merge_requests": "merge_requests",
+}
+
+// stampColumns hold the GitLab time each table's rows were last changed,
+// as incremental syncs see it. Groups have none.
+var stampColumns = map[string]string{
+	"groups":         "''",
+	"projects":       "last_activity_at",
+	"issues":         "updated_at",
+	"merge_requests": "updated_at",
+}
+
+// Drift is what the last reconciliation of a resource type found: rows the
+// store kept after GitLab stopped listing them, and rows GitLab lists that
+// the store lacks.
+type Drift struct {
+	Resource     string    `json:"resource"`
+	ReconciledAt time.Time `json:"reconciled_at,omitzero"`
+	Removed      int       `json:"removed"`
+	Missing      int       `json:"missing"`
+	Deleted      int       `json:"deleted"` // soft-deleted rows in the table
+}
+
+// Reconcile compares the rows of resourceType with ids, the complete list
+// of IDs GitLab has for it. Rows not in ids are soft-deleted at at, and
+// what was found is recorded as the resource's drift.
+func (s *Store) Reconcile(resourceType string, ids []int64, at time.Time) (Drift, error) {
+	table, ok := reconcileTables[resourceType]
+	if !ok {
+		return Drift{}, fmt.Errorf("cannot reconcile %s", resourceType)
+	}
+	d := Drift{Resource: resourceType, ReconciledAt: at}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return d, err
+	}
+	defer tx.Rollback()
+
+	if err := fillIDs(tx, "_reconcile_ids", ids); err != nil {
+		return d, err
+	}
+	res, err := tx.Exec(`UPDATE `+table+` SET deleted_at = ?
+		WHERE deleted_at = '' AND id NOT IN (SELECT id FROM _reconcile_ids)`, fmtTime(at))
+	if err != nil {
+		return d, err
+	}
+	removed, err := res.RowsAffected()
+	if err != nil {
+		return d, err
+	}
+	d.Removed = int(removed)
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM _reconcile_ids
+		WHERE id NOT IN (SELECT id FROM ` + table + ` WHERE deleted_at = '')`).Scan(&d.Missing); err != nil {
+		return d, err
+	}
+	if _, err := tx.Exec("DROP TABLE _reconcile_ids"); err != nil {
+		return d, err
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO sync_meta (resource_type, last_synced_at, reconciled_at, drift_removed, drift_missing)
+		VALUES (?, '', ?, ?, ?)
+		ON CONFLICT(resource_type) DO UPDATE SET reconciled_at = excluded.reconciled_at,
+			drift_removed = excluded.drift_removed, drift_missing = excluded.drift_missing`,
+		resourceType, fmtTime(at), d.Removed, d.Missing); err != nil {
+		return d, err
+	}
+	return d, tx.Commit()
+}
+
+// ReconcileGroupIssues drops the links of group to issues not in ids, the
+// issues GitLab currently lists for it.
+func (s *Store) ReconcileGroupIssues(groupID int64, ids []int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fillIDs(tx, "_reconcile_ids", ids); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM group_issues
+		WHERE group_id = ? AND issue_id NOT IN (SELECT id FROM _reconcile_ids)`, groupID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DROP TABLE _reconcile_ids"); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetDrift returns what the last reconciliation of resourceType found,
+// with a zero ReconciledAt if there was none.
+func (s *Store) GetDrift(resourceType string) (Drift, error) {
+	table, ok := reconcileTables[resourceType]
+	if !ok {
+		return Drift{}, fmt.Errorf("cannot reconcile %s", resourceType)
+	}
+	d := Drift{Resource: resourceType}
+	var at string
+	err := s.db.QueryRow(
+		"SELECT reconciled_at, drift_removed, drift_missing FROM sync_meta WHERE resource_type = ?",
+		resourceType,
+	).Scan(&at, &d.Removed, &d.Missing)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return d, err
+	}
+	d.ReconciledAt = parseTime(at)
+	err = s.db.QueryRow("SELECT COUNT(*) FROM " + table + " WHERE deleted_at != ''").Scan(&d.Deleted)
+	return d, err
+}
+
+// fillIDs creates the temporary table name holding ids.
+func fillIDs(tx *sql.Tx, name string, ids []int64) error {
+	if _, err := tx.Exec("CREATE TEMP TABLE " + name + " (id INTEGER PRIMARY KEY)"); err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare("INSERT OR IGNORE INTO " + name + " (id) VALUES (?)")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, id := range ids {
+		if _, err := stmt.Exec(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stamps returns when each live row of resourceType last changed on
+// GitLab, by ID. The times of groups are zero.
+func (s *Store) Stamps(resourceType string) (map[int64]time.Time, error) {
+	table, ok := reconcileTables[resourceType]
+	if !ok {
+		return nil, fmt.Errorf("cannot reconcile %s", resourceType)
+	}
+	rows, err := s.db.Query("SELECT id, " + stampColumns[resourceType] + " FROM " + table + " WHERE deleted_at = ''")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stamps := make(map[int64]time.Time)
+	for rows.Next() {
+		var id int64
+		var ts string
+		if err := rows.Scan(&id, &ts); err != nil {
+			return nil, err
+		}
+		stamps[id] = parseTime(ts)
+	}
+	return stamps, rows.Err()
+}
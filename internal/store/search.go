@@ -0,0 +1,103 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Snippet highlight markers. They never occur in GitLab text, so renderers
+// can replace them with styling or strip them.
+const (
+	MatchStart = "\x02"
+	MatchEnd   = "\x03"
+)
+
+// IssueHit is a full-text search result.
+type IssueHit struct {
+	StoreIssue
+	Rank    float64 `json:"rank"`    // bm25 score; lower is better
+	Snippet string  `json:"snippet"` // excerpt with matches wrapped in MatchStart/MatchEnd
+}
+
+// SearchIssues runs a full-text search over issue titles and descriptions
+// and returns up to limit hits, best first. Each term is matched as a word;
+// terms containing spaces (or wrapped in double quotes) are phrases, a
+// trailing * makes a prefix query, and the bare words OR, NOT and AND
+// combine terms. Title matches rank above description matches.
+func (s *Store) SearchIssues(terms []string, limit int) ([]IssueHit, error) {
+	match, err := ftsQuery(terms)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.db.Query(`SELECT `+issueColumns+`, f.rank, f.snippet
+		FROM issues
+		JOIN (SELECT rowid, bm25(issues_fts, 10.0, 1.0) AS rank,
+				snippet(issues_fts, -1, ?, ?, '…', 16) AS snippet
+			FROM issues_fts WHERE issues_fts MATCH ?) f ON f.rowid = issues.id
+		WHERE issues.deleted_at = ''
+		ORDER BY f.rank
+		LIMIT ?`, MatchStart, MatchEnd, match, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hits []IssueHit
+	for rows.Next() {
+		var h IssueHit
+		issue, err := scanIssue(rows, &h.Rank, &h.Snippet)
+		if err != nil {
+			return nil, err
+		}
+		h.StoreIssue = issue
+		hits = append(hits, h)
+	}
+	return hits, rows.Err()
+}
+
+// ftsQuery turns user terms into an FTS5 MATCH expression. Every term is
+// quoted so punctuation in it cannot be parsed as FTS5 syntax. The
+// operators OR, NOT and AND join two terms; one without a term on either
+// side is a usage error rather than an FTS5 syntax error.
+func ftsQuery(terms []string) (string, error) {
+	var parts []string
+	op := "" // operator waiting for the term after it
+	for _, t := range terms {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		if t == "OR" || t == "NOT" || t == "AND" {
+			switch {
+			case op != "":
+				return "", fmt.Errorf("%s cannot follow %s; put a search term between them", t, op)
+			case len(parts) == 0:
+				return "", fmt.Errorf("%s needs a search term before it", t)
+			}
+			op = t
+			parts = append(parts, t)
+			continue
+		}
+		prefix := strings.HasSuffix(t, "*")
+		t = strings.TrimSuffix(t, "*")
+		if len(t) >= 2 && strings.HasPrefix(t, `"`) && strings.HasSuffix(t, `"`) {
+			t = t[1 : len(t)-1]
+		}
+		if t == "" {
+			continue
+		}
+		part := `"` + strings.ReplaceAll(t, `"`, `""`) + `"`
+		if prefix {
+			part += "*"
+		}
+		parts = append(parts, part)
+		op = ""
+	}
+	if op != "" {
+		return "", fmt.Errorf("%s needs a search term after it", op)
+	}
+	if len(parts) == 0 {
+		return "", fmt.Errorf("no search terms")
+	}
+	return strings.Join(parts, " "), nil
+}
@@ -0,0 +1,100 @@
+package store
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFTSQuery(t *testing.T) {
+	tests := []struct {
+		terms string
+		want  string
+	}{
+		{"crash", `"crash"`},
+		{"crash login", `"crash" "login"`},
+		{"log*", `"log"*`},
+		{`"login`, `"""login"`},
+		{`say"hi`, `"say""hi"`},
+		{"crash OR hang", `"crash" OR "hang"`},
+		{"crash NOT login", `"crash" NOT "login"`},
+		{"crash AND hang OR freeze", `"crash" AND "hang" OR "freeze"`},
+		{"or not", `"or" "not"`},
+		{`"OR"`, `"OR"`},
+	}
+	for _, tt := range tests {
+		got, err := ftsQuery(strings.Fields(tt.terms))
+		if err != nil {
+			t.Errorf("ftsQuery(%q): %v", tt.terms, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ftsQuery(%q) = %s, want %s", tt.terms, got, tt.want)
+		}
+	}
+}
+
+func TestFTSQueryErrors(t *testing.T) {
+	tests := []struct {
+		terms string
+		want  string
+	}{
+		{"", "no search terms"},
+		{"*", "no search terms"},
+		{"OR", "OR needs a search term before it"},
+		{"NOT bar", "NOT needs a search term before it"},
+		{"foo OR", "OR needs a search term after it"},
+		{"foo AND", "AND needs a search term after it"},
+		{"foo OR NOT bar", "NOT cannot follow OR"},
+		{"foo AND AND bar", "AND cannot follow AND"},
+	}
+	for _, tt := range tests {
+		_, err := ftsQuery(strings.Fields(tt.terms))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ftsQuery(%q): got error %v, want %q", tt.terms, err, tt.want)
+		}
+	}
+}
+
+func TestSearchIssues(t *testing.T) {
+	s := openTestStore(t)
+	seedQueryIssues(t, s)
+
+	tests := []struct {
+		terms  []string
+		want   []int64 // issue IDs
+		ranked bool    // want is in rank order
+	}{
+		// A title match ranks above a description match.
+		{[]string{"login"}, []int64{1, 3}, true},
+		{[]string{"crash", "OR", "limits"}, []int64{1, 4}, false},
+		{[]string{"login", "NOT", "crash"}, []int64{3}, false},
+		{[]string{"rat*"}, []int64{4}, false},
+		{[]string{"login button"}, []int64{3}, false},
+		{[]string{`"button login"`}, []int64{}, false},
+	}
+	for _, tt := range tests {
+		hits, err := s.SearchIssues(tt.terms, 10)
+		if err != nil {
+			t.Errorf("SearchIssues(%q): %v", tt.terms, err)
+			continue
+		}
+		got := make([]int64, len(hits))
+		for i, h := range hits {
+			got[i] = h.ID
+		}
+		if !tt.ranked {
+			slices.Sort(got)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("SearchIssues(%q) = %v, want %v", tt.terms, got, tt.want)
+		}
+	}
+
+	// Operators without terms are caught before SQLite sees them.
+	for _, terms := range [][]string{{"OR"}, {"foo", "OR"}, {"NOT", "bar"}} {
+		if _, err := s.SearchIssues(terms, 10); err == nil || strings.Contains(err.Error(), "fts5") {
+			t.Errorf("SearchIssues(%q): got error %v, want a usage error", terms, err)
+		}
+	}
+}
@@ -31,7 +31,9 @@ func Open(path string) (*Store, error) {
 		return nil, fmt.Errorf("create db dir: %w", err)
 	}
 
-	db, err := sql.Open("sqlite", path)
+	// Per-connection pragmas go in the DSN so every pooled connection gets
+	// them; the sync reads on several connections at once.
+	db, err := sql.Open("sqlite", path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
 	if err != nil {
 		return nil, fmt.Errorf("open db: %w", err)
 	}
@@ -39,8 +41,6 @@ func Open(path string) (*Store, error) {
 	// SQLite pragmas for performance and safety.
 	for _, pragma := range []string{
 		"PRAGMA journal_mode=WAL",
-		"PRAGMA foreign_keys=ON",
-		"PRAGMA busy_timeout=5000",
 	} {
 		if _, err := db.Exec(pragma); err != nil {
 			_ = db.Close()
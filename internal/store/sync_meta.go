@@ -14,7 +14,8 @@ func (s *Store) GetLastSynced(resourceType string) (time.Time, error) {
 		"SELECT last_synced_at FROM sync_meta WHERE resource_type = ?",
 		resourceType,
 	).Scan(&ts)
-	if errors.Is(err, sql.ErrNoRows) {
+	// A row may only hold a sync cursor.
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && ts == "") {
 		return time.Time{}, nil
 	}
 	if err != nil {
@@ -69,3 +70,79 @@ func (s *Store) GetLastFullSync(resourceType string) (time.Time, error) {
 	}
 	return t, nil
 }
+
+// GetWatermark returns the newest GitLab timestamp stored for a resource
+// type, or the zero time if none was recorded.
+func (s *Store) GetWatermark(resourceType string) (time.Time, error) {
+	var ts string
+	err := s.db.QueryRow(
+		"SELECT watermark FROM sync_meta WHERE resource_type = ?",
+		resourceType,
+	).Scan(&ts)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	return parseTime(ts), err
+}
+
+// AdvanceWatermark moves the watermark of a resource type forward to t. An
+// older t leaves it as it is.
+func (s *Store) AdvanceWatermark(resourceType string, t time.Time) error {
+	if t.IsZero() {
+		return nil
+	}
+	// RFC 3339 in UTC sorts as text.
+	_, err := s.db.Exec(`
+		INSERT INTO sync_meta (resource_type, last_synced_at, watermark)
+		VALUES (?, '', ?)
+		ON CONFLICT(resource_type) DO UPDATE SET watermark = MAX(watermark, excluded.watermark)`,
+		resourceType, fmtTime(t),
+	)
+	return err
+}
+
+// SyncCursor is where an interrupted full sync of a resource stopped.
+type SyncCursor struct {
+	Page      int64     // next page to fetch; 0 once every page is stored
+	StartedAt time.Time // when the interrupted sync began
+}
+
+// GetSyncCursor returns the cursor saved for resourceType, if any.
+func (s *Store) GetSyncCursor(resourceType string) (SyncCursor, bool, error) {
+	var c SyncCursor
+	var started string
+	err := s.db.QueryRow(
+		"SELECT cursor_page, cursor_started_at FROM sync_meta WHERE resource_type = ?",
+		resourceType,
+	).Scan(&c.Page, &started)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && started == "") {
+		return SyncCursor{}, false, nil
+	}
+	if err != nil {
+		return SyncCursor{}, false, err
+	}
+	c.StartedAt = parseTime(started)
+	return c, true, nil
+}
+
+// SetSyncCursor saves how far a full sync of resourceType has got.
+func (s *Store) SetSyncCursor(resourceType string, c SyncCursor) error {
+	_, err := s.db.Exec(`
+		INSERT INTO sync_meta (resource_type, last_synced_at, cursor_page, cursor_started_at)
+		VALUES (?, '', ?, ?)
+		ON CONFLICT(resource_type) DO UPDATE SET
+			cursor_page = excluded.cursor_page, cursor_started_at = excluded.cursor_started_at`,
+		resourceType, c.Page, fmtTime(c.StartedAt),
+	)
+	return err
+}
+
+// ClearSyncCursor forgets the cursor of resourceType once its full sync has
+// completed.
+func (s *Store) ClearSyncCursor(resourceType string) error {
+	_, err := s.db.Exec(
+		"UPDATE sync_meta SET cursor_page = 0, cursor_started_at = '' WHERE resource_type = ?",
+		resourceType,
+	)
+	return err
+}
@@ -0,0 +1,115 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+func (s *Store) UpsertTodos(todos []StoreTodo) error {
+	if len(todos) == 0 {
+		return nil
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO todos (id, project_id, project_path, author_username, action_name,
+			target_type, target_iid, target_title, target_state, target_url, body, state,
+			created_at, synced_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			project_id=excluded.project_id, project_path=excluded.project_path,
+			author_username=excluded.author_username, action_name=excluded.action_name,
+			target_type=excluded.target_type, target_iid=excluded.target_iid,
+			target_title=excluded.target_title, target_state=excluded.target_state,
+			target_url=excluded.target_url, body=excluded.body, state=excluded.state,
+			created_at=excluded.created_at, synced_at=excluded.synced_at`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, t := range todos {
+		_, err := stmt.Exec(t.ID, t.ProjectID, t.ProjectPath, t.AuthorUsername, t.ActionName,
+			t.TargetType, t.TargetIID, t.TargetTitle, t.TargetState, t.TargetURL, t.Body, t.State,
+			fmtTime(t.CreatedAt), now)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// MarkStaleTodosDone marks as done the pending to-do items not stored since
+// syncedBefore, the start of a sync that stored every pending item: they
+// were dealt with elsewhere.
+func (s *Store) MarkStaleTodosDone(syncedBefore time.Time) error {
+	_, err := s.db.Exec("UPDATE todos SET state = 'done' WHERE state = 'pending' AND synced_at < ?", fmtTime(syncedBefore))
+	return err
+}
+
+// MarkTodosDone marks the given to-do items done, or every pending one when
+// no IDs are given.
+func (s *Store) MarkTodosDone(ids ...int64) error {
+	if len(ids) == 0 {
+		_, err := s.db.Exec("UPDATE todos SET state = 'done' WHERE state = 'pending'")
+		return err
+	}
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	_, err := s.db.Exec(`UPDATE todos SET state = 'done'
+		WHERE id IN (?`+strings.Repeat(", ?", len(ids)-1)+`)`, args...)
+	return err
+}
+
+const todoColumns = `id, project_id, project_path, author_username, action_name,
+	target_type, target_iid, target_title, target_state, target_url, body, state, created_at`
+
+// ListTodos returns to-do items, newest first. A non-empty state
+// ("pending" or "done") narrows the listing.
+func (s *Store) ListTodos(state string) ([]StoreTodo, error) {
+	rows, err := s.db.Query(`SELECT `+todoColumns+` FROM todos
+		WHERE (? = '' OR state = ?) ORDER BY created_at DESC, id DESC`, state, state)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var todos []StoreTodo
+	for rows.Next() {
+		t, err := scanTodo(rows)
+		if err != nil {
+			return nil, err
+		}
+		todos = append(todos, t)
+	}
+	return todos, rows.Err()
+}
+
+func (s *Store) GetTodo(id int64) (StoreTodo, error) {
+	t, err := scanTodo(s.db.QueryRow(`SELECT `+todoColumns+` FROM todos WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return t, ErrRecordNotFound
+	}
+	return t, err
+}
+
+func scanTodo(row interface{ Scan(...any) error }) (StoreTodo, error) {
+	var t StoreTodo
+	var createdAt string
+	if err := row.Scan(&t.ID, &t.ProjectID, &t.ProjectPath, &t.AuthorUsername, &t.ActionName,
+		&t.TargetType, &t.TargetIID, &t.TargetTitle, &t.TargetState, &t.TargetURL, &t.Body, &t.State,
+		&createdAt); err != nil {
+		return t, err
+	}
+	t.CreatedAt = parseTime(createdAt)
+	return t, nil
+}
@@ -0,0 +1,47 @@
+package styles
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// IssueLabel renders a label the way GitLab shows it: the name on the
+// label's colour, written in textColor or, without one, in black or white
+// for contrast. A scoped label ("key::value") has its value on the default
+// background in the label's colour. Labels without a colour are rendered
+// as "~name" in the Label style.
+func IssueLabel(name, color, textColor string) string {
+	if color == "" {
+		return Label.Render("~" + name)
+	}
+	if textColor == "" {
+		textColor = contrastColor(color)
+	}
+	chip := lipgloss.NewStyle().Background(lipgloss.Color(color)).Foreground(lipgloss.Color(textColor))
+	i := strings.LastIndex(name, "::")
+	if i < 0 {
+		return chip.Render(" " + name + " ")
+	}
+	value := lipgloss.NewStyle().Foreground(lipgloss.Color(color))
+	return chip.Render(" "+name[:i]+" ") + value.Render(" "+name[i+2:]+" ")
+}
+
+// contrastColor picks black or white text for a "#rrggbb" background by
+// its perceived brightness.
+func contrastColor(bg string) string {
+	hex := strings.TrimPrefix(bg, "#")
+	if len(hex) != 6 {
+		return "#ffffff"
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return "#ffffff"
+	}
+	r, g, b := v>>16&0xff, v>>8&0xff, v&0xff
+	if (299*r+587*g+114*b)/1000 > 128 {
+		return "#1f1e24"
+	}
+	return "#ffffff"
+}
@@ -12,4 +12,10 @@ var (
 	HelpCmd  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.AdaptiveColor{Light: "#006677", Dark: "#44ccdd"})
 	HelpDesc = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#444444", Dark: "#aaaaaa"})
 	Banner   = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.AdaptiveColor{Light: "#880077", Dark: "#dd88cc"})
+	Match    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.AdaptiveColor{Light: "#995500", Dark: "#ffcc44"})
+	Code     = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#aa3300", Dark: "#ff9966"})
+	Link     = lipgloss.NewStyle().Underline(true).Foreground(lipgloss.AdaptiveColor{Light: "#0066cc", Dark: "#6699ff"})
+	Strong   = lipgloss.NewStyle().Bold(true)
+	Emphasis = lipgloss.NewStyle().Italic(true)
+	Panel    = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.AdaptiveColor{Light: "#aaaaaa", Dark: "#555555"}).Padding(0, 1)
 )
@@ -0,0 +1,173 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chazzychouse/g2o/internal/glclient"
+	"github.com/chazzychouse/g2o/internal/store"
+)
+
+// maxResumeAge is how old an interrupted full sync may be and still be
+// resumed. Older ones start over, as too much has changed since.
+const maxResumeAge = 24 * time.Hour
+
+// checkpoint records in sync_meta how far a full sync of a resource has got,
+// page by page, so that an interrupted one resumes where it stopped. A
+// resource listed in parts, like merge requests by scope, has a cursor per
+// part; the plain resource has the single part "".
+type checkpoint struct {
+	s        *Syncer
+	resource string               // sync_meta key, e.g. "merge_requests"
+	started  time.Time            // start of the full sync, resumed or not
+	next     map[string]int64     // next page per part; 0 once it is done
+	resumed  map[string]time.Time // parts picked up from an earlier run
+	newest   time.Time            // newest change seen by this run
+}
+
+// checkpoint loads the cursors of resource, starting afresh for parts
+// without a recent one.
+func (s *Syncer) checkpoint(ctx context.Context, resource string, parts ...string) (*checkpoint, error) {
+	if len(parts) == 0 {
+		parts = []string{""}
+	}
+	c := &checkpoint{
+		s:        s,
+		resource: resource,
+		started:  time.Now().UTC(),
+		next:     make(map[string]int64, len(parts)),
+		resumed:  make(map[string]time.Time),
+	}
+	for _, part := range parts {
+		cur, ok, err := s.store.GetSyncCursor(c.key(part))
+		if err != nil {
+			return nil, err
+		}
+		if !ok || time.Since(cur.StartedAt) > maxResumeAge {
+			c.next[part] = 1
+			continue
+		}
+		c.next[part] = cur.Page
+		c.resumed[part] = cur.StartedAt
+		if cur.StartedAt.Before(c.started) {
+			c.started = cur.StartedAt
+		}
+	}
+	for _, part := range parts {
+		if _, ok := c.resumed[part]; ok && c.next[part] > 1 {
+			s.emit(Event{Kind: EventNotice, Message: fmt.Sprintf("resuming %s from page %d", c.name(ctx, part), c.next[part])})
+		}
+	}
+	return c, nil
+}
+
+func (c *checkpoint) key(part string) string {
+	if part == "" {
+		return c.resource
+	}
+	return c.resource + ":" + part
+}
+
+// name is how the resource of ctx and part are shown to the user.
+func (c *checkpoint) name(ctx context.Context, part string) string {
+	name, ok := ctx.Value(resourceKey{}).(string)
+	if !ok {
+		name = c.resource
+	}
+	if part != "" {
+		name += " (" + part + ")"
+	}
+	return name
+}
+
+// from returns the page to continue part at, or 0 if it is already done.
+func (c *checkpoint) from(part string) int64 {
+	return c.next[part]
+}
+
+// save stores page of part through fn, which stores n records, and moves
+// the cursor past it in the same step.
+func (c *checkpoint) save(ctx context.Context, part string, page int64, n int, fn func() error) error {
+	return c.s.save(ctx, n, func() error {
+		if err := fn(); err != nil {
+			return err
+		}
+		return c.s.store.SetSyncCursor(c.key(part), store.SyncCursor{Page: page + 1, StartedAt: c.started})
+	})
+}
+
+// done marks every page of part as stored.
+func (c *checkpoint) done(ctx context.Context, part string) error {
+	return c.s.save(ctx, 0, func() error {
+		return c.s.store.SetSyncCursor(c.key(part), store.SyncCursor{StartedAt: c.started})
+	})
+}
+
+// observe notes t, the newest change in a page, for the watermark set by
+// finish. Pages stored by an interrupted run are not seen again, so a
+// resumed sync sets an older watermark than it could; the next incremental
+// sync then merely looks further back.
+func (c *checkpoint) observe(t time.Time) {
+	if t.After(c.newest) {
+		c.newest = t
+	}
+}
+
+// summary describes the n records of noun stored by this run.
+func (c *checkpoint) summary(n int, noun string) string {
+	if len(c.resumed) > 0 {
+		return fmt.Sprintf("%d %s (resumed)", n, noun)
+	}
+	return fmt.Sprintf("%d %s", n, noun)
+}
+
+// finish runs prune, which drops what was not stored since the sync began,
+// advances the watermark and forgets the cursors once every part is done.
+func (c *checkpoint) finish(ctx context.Context, prune func(syncedBefore time.Time) error) error {
+	return c.s.save(ctx, 0, func() error {
+		if err := prune(c.started); err != nil {
+			return err
+		}
+		if err := c.s.store.AdvanceWatermark(c.resource, c.newest); err != nil {
+			return err
+		}
+		for part := range c.next {
+			if err := c.s.store.ClearSyncCursor(c.key(part)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// interrupted lists the resources whose full sync stopped part way and
+// will resume on the next "sync full".
+func (s *Syncer) interrupted() ([]string, error) {
+	resources := map[string][]string{
+		"groups":         {""},
+		"projects":       {""},
+		"merge_requests": glclient.MergeRequestScopes,
+	}
+	order := []string{"groups", "projects"}
+	for _, resource := range s.issueResources() {
+		resources[resource] = []string{""}
+		order = append(order, resource)
+	}
+	order = append(order, "merge_requests")
+	var out []string
+	for _, resource := range order {
+		c := &checkpoint{resource: resource}
+		for _, part := range resources[resource] {
+			cur, ok, err := s.store.GetSyncCursor(c.key(part))
+			if err != nil {
+				return nil, err
+			}
+			if ok && time.Since(cur.StartedAt) <= maxResumeAge {
+				out = append(out, resource)
+				break
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,134 @@
+package sync
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/chazzychouse/g2o/internal/store"
+)
+
+func openTestStore(t *testing.T) *store.Store {
+	t.Helper()
+	s, err := store.Open(filepath.Join(t.TempDir(), "g2o.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// tick waits until the store's clock, which keeps whole seconds, has moved
+// on.
+func tick() {
+	time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second + 10*time.Millisecond)))
+}
+
+func storedProjects(t *testing.T, st *store.Store) []int64 {
+	t.Helper()
+	projects, err := st.ListProjects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int64
+	for _, p := range projects {
+		ids = append(ids, p.ID)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// TestCheckpointResume interrupts a full sync of projects after its first
+// page and resumes it in a new run. The resumed run must prune only what
+// neither run stored: the first page was stored before the second run
+// began, but belongs to the same sync.
+func TestCheckpointResume(t *testing.T) {
+	ctx := context.Background()
+	st := openTestStore(t)
+	s := NewSyncer(nil, st)
+	s.SetReporter(NewPlainReporter(io.Discard))
+
+	// A project deleted on GitLab, stored by some earlier sync.
+	if err := st.UpsertProjects([]store.StoreProject{{ID: 1, PathWithNamespace: "acme/gone"}}); err != nil {
+		t.Fatal(err)
+	}
+	tick()
+
+	first, err := s.checkpoint(ctx, "projects")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := first.from(""); got != 1 {
+		t.Fatalf("fresh sync starts at page %d, want 1", got)
+	}
+	if err := first.save(ctx, "", 1, 1, func() error {
+		return st.UpsertProjects([]store.StoreProject{{ID: 2, PathWithNamespace: "acme/api"}})
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// Interrupted here: finish never runs.
+	tick()
+
+	second, err := s.checkpoint(ctx, "projects")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := second.from(""); got != 2 {
+		t.Fatalf("resumed sync starts at page %d, want 2", got)
+	}
+	// The store keeps whole seconds.
+	if !second.started.Equal(first.started.Truncate(time.Second)) {
+		t.Errorf("resumed sync started at %s, want the interrupted run's %s", second.started, first.started)
+	}
+	if err := second.save(ctx, "", 2, 1, func() error {
+		return st.UpsertProjects([]store.StoreProject{{ID: 3, PathWithNamespace: "acme/web"}})
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.done(ctx, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.finish(ctx, st.DeleteStaleProjects); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := storedProjects(t, st), []int64{2, 3}; !slices.Equal(got, want) {
+		t.Errorf("got projects %v, want %v: both pages kept, the stale one pruned", got, want)
+	}
+	if _, ok, err := st.GetSyncCursor("projects"); err != nil || ok {
+		t.Errorf("cursor still set (%v) after the sync finished", err)
+	}
+
+	// With the cursor gone, the next full sync starts over.
+	next, err := s.checkpoint(ctx, "projects")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := next.from(""); got != 1 || len(next.resumed) != 0 {
+		t.Errorf("next sync starts at page %d, resumed %v; want a fresh start", got, next.resumed)
+	}
+}
+
+// TestCheckpointStaleCursor checks that a cursor older than maxResumeAge is
+// not resumed, so the new run prunes by its own start.
+func TestCheckpointStaleCursor(t *testing.T) {
+	ctx := context.Background()
+	st := openTestStore(t)
+	s := NewSyncer(nil, st)
+	s.SetReporter(NewPlainReporter(io.Discard))
+
+	old := time.Now().UTC().Add(-maxResumeAge - time.Hour)
+	if err := st.SetSyncCursor("projects", store.SyncCursor{Page: 7, StartedAt: old}); err != nil {
+		t.Fatal(err)
+	}
+	c, err := s.checkpoint(ctx, "projects")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.from(""); got != 1 || !c.started.After(old) {
+		t.Errorf("got page %d started %s, want a fresh start", got, c.started)
+	}
+}
@@ -0,0 +1,150 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/chazzychouse/g2o/internal/glclient"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// SetIssueScopes sets the sets of issues a sync fetches. Each scope keeps
+// its own membership, watermark and checkpoint, so a full sync of one never
+// drops the issues of another. None at all restores the default.
+func (s *Syncer) SetIssueScopes(scopes []glclient.IssueScope) {
+	if len(scopes) == 0 {
+		scopes = glclient.DefaultIssueScopes
+	}
+	s.issueScopes = scopes
+}
+
+// issueResource is the sync_meta key of scope.
+func issueResource(scope glclient.IssueScope) string {
+	return "issues:" + scope.String()
+}
+
+// issueResources lists the sync_meta keys of the configured scopes.
+func (s *Syncer) issueResources() []string {
+	resources := make([]string, len(s.issueScopes))
+	for i, sc := range s.issueScopes {
+		resources[i] = issueResource(sc)
+	}
+	return resources
+}
+
+// knownIssueScopes returns the configured scopes followed by the others
+// that list stored issues, such as projects synced with SyncProject.
+func (s *Syncer) knownIssueScopes() ([]glclient.IssueScope, error) {
+	stored, err := s.store.ListIssueScopes()
+	if err != nil {
+		return nil, err
+	}
+	scopes := slices.Clone(s.issueScopes)
+	for _, written := range stored {
+		sc, err := glclient.ParseIssueScope(written)
+		if err != nil {
+			continue
+		}
+		if !slices.Contains(scopes, sc) {
+			scopes = append(scopes, sc)
+		}
+	}
+	return scopes, nil
+}
+
+// SyncProject fetches every issue of one project, incrementally, and keeps
+// them as the scope "project:<path>" whether or not the profile lists it.
+// project is a numeric ID or a path such as "group/sub/project".
+func (s *Syncer) SyncProject(ctx context.Context, project string) error {
+	path, err := s.client.ProjectPath(ctx, project)
+	if err != nil {
+		return err
+	}
+	scope := glclient.IssueScope{Kind: glclient.ScopeProject, Path: path}
+	if err := s.runStages(ctx, stage{"issues for " + path, s.syncScopeIssuesIncremental(scope)}); err != nil {
+		return err
+	}
+	return s.store.SetLastSynced(issueResource(scope), time.Now().UTC())
+}
+
+// issueStages returns a stage per scope, full or incremental. A lone scope
+// shows as plain "issues".
+func (s *Syncer) issueStages(full bool) []stage {
+	stages := make([]stage, len(s.issueScopes))
+	for i, sc := range s.issueScopes {
+		name := "issues"
+		if len(s.issueScopes) > 1 {
+			name = "issues (" + sc.String() + ")"
+		}
+		run := s.syncScopeIssuesIncremental(sc)
+		if full {
+			run = s.syncScopeIssuesFull(sc)
+		}
+		stages[i] = stage{name, run}
+	}
+	return stages
+}
+
+func (s *Syncer) syncScopeIssuesFull(scope glclient.IssueScope) func(context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		c, err := s.checkpoint(ctx, issueResource(scope))
+		if err != nil {
+			return "", err
+		}
+		n := 0
+		err = s.client.IssuePages(ctx, scope, nil, c.from(""), func(page int64, issues []*gitlab.Issue) error {
+			n += len(issues)
+			c.observe(latest(issues, issueUpdated))
+			return c.save(ctx, "", page, len(issues), func() error { return s.storeScopeIssues(scope, issues) })
+		})
+		if err != nil {
+			return "", err
+		}
+		err = c.finish(ctx, func(syncedBefore time.Time) error {
+			return s.store.DeleteStaleScopeIssues(scope.String(), syncedBefore)
+		})
+		if err != nil {
+			return "", err
+		}
+		return c.summary(n, "issues"), nil
+	}
+}
+
+func (s *Syncer) syncScopeIssuesIncremental(scope glclient.IssueScope) func(context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		after, err := s.lastSynced(issueResource(scope))
+		if err != nil {
+			return "", err
+		}
+		issues, err := s.client.AllIssues(ctx, scope, after)
+		if err != nil {
+			return "", err
+		}
+		if len(issues) > 0 {
+			err := s.save(ctx, len(issues), func() error {
+				if err := s.storeScopeIssues(scope, issues); err != nil {
+					return err
+				}
+				return s.store.AdvanceWatermark(issueResource(scope), latest(issues, issueUpdated))
+			})
+			if err != nil {
+				return "", err
+			}
+		}
+		return fmt.Sprintf("%d issues", len(issues)), nil
+	}
+}
+
+// storeScopeIssues upserts issues and records them as listed by scope.
+func (s *Syncer) storeScopeIssues(scope glclient.IssueScope, issues []*gitlab.Issue) error {
+	if err := s.store.UpsertIssues(glclient.ConvertIssues(issues)); err != nil {
+		return err
+	}
+	ids := make([]int64, len(issues))
+	for i, issue := range issues {
+		ids[i] = issue.ID
+	}
+	return s.store.LinkScopeIssues(scope.String(), ids)
+}
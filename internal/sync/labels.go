@@ -0,0 +1,73 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/chazzychouse/g2o/internal/glclient"
+	"github.com/chazzychouse/g2o/internal/store"
+)
+
+// SyncLabels fetches the labels every stored group and project defines.
+func (s *Syncer) SyncLabels(ctx context.Context) error {
+	if err := s.runStages(ctx, stage{"labels", s.syncLabels}); err != nil {
+		return err
+	}
+	return s.store.SetLastSynced("labels", time.Now().UTC())
+}
+
+// syncLabels runs after groups and projects are stored, whose lists it
+// walks. Each label is stored once, under the group or project defining it.
+func (s *Syncer) syncLabels(ctx context.Context) (string, error) {
+	start := time.Now().UTC()
+	groups, err := s.store.ListGroups()
+	if err != nil {
+		return "", err
+	}
+	projects, err := s.store.ListProjects()
+	if err != nil {
+		return "", err
+	}
+
+	var n atomic.Int64
+	err = forEach(ctx, s.client.Workers(), groups, func(ctx context.Context, g store.StoreGroup) error {
+		labels, err := s.client.AllGroupLabels(ctx, g.ID)
+		if errors.Is(err, glclient.ErrNotFound) {
+			return nil // deleted since groups were synced
+		}
+		if err != nil {
+			return fmt.Errorf("group %d: %w", g.ID, err)
+		}
+		n.Add(int64(len(labels)))
+		return s.save(ctx, len(labels), func() error {
+			return s.store.UpsertLabels(glclient.ConvertCatalogueLabels(labels, g.ID, 0))
+		})
+	})
+	if err != nil {
+		return "", err
+	}
+	err = forEach(ctx, s.client.Workers(), projects, func(ctx context.Context, p store.StoreProject) error {
+		labels, err := s.client.AllProjectOwnLabels(ctx, p.ID)
+		if errors.Is(err, glclient.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("project %d: %w", p.ID, err)
+		}
+		n.Add(int64(len(labels)))
+		return s.save(ctx, len(labels), func() error {
+			return s.store.UpsertLabels(glclient.ConvertCatalogueLabels(labels, 0, p.ID))
+		})
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.save(ctx, 0, func() error { return s.store.DeleteStaleLabels(start) }); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d labels", n.Load()), nil
+}
@@ -0,0 +1,131 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/chazzychouse/g2o/internal/glclient"
+	"github.com/chazzychouse/g2o/internal/store"
+)
+
+// SyncMilestones fetches the milestones of every stored group and project
+// and the iterations of every stored group.
+func (s *Syncer) SyncMilestones(ctx context.Context) error {
+	if err := s.runStages(ctx, s.milestoneStages()...); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	for _, res := range []string{"milestones", "iterations"} {
+		if err := s.store.SetLastSynced(res, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// milestoneStages run after groups and projects are stored, whose lists
+// they walk.
+func (s *Syncer) milestoneStages() []stage {
+	return []stage{
+		{"milestones", s.syncMilestones},
+		{"iterations", s.syncIterations},
+	}
+}
+
+func (s *Syncer) syncMilestones(ctx context.Context) (string, error) {
+	start := time.Now().UTC()
+	groups, err := s.store.ListGroups()
+	if err != nil {
+		return "", err
+	}
+	projects, err := s.store.ListProjects()
+	if err != nil {
+		return "", err
+	}
+
+	var n atomic.Int64
+	err = forEach(ctx, s.client.Workers(), groups, func(ctx context.Context, g store.StoreGroup) error {
+		ms, err := s.client.AllGroupMilestones(ctx, g.ID)
+		if errors.Is(err, glclient.ErrNotFound) {
+			return nil // deleted since groups were synced
+		}
+		if err != nil {
+			return fmt.Errorf("group %d: %w", g.ID, err)
+		}
+		n.Add(int64(len(ms)))
+		return s.save(ctx, len(ms), func() error { return s.store.UpsertMilestones(glclient.ConvertGroupMilestones(ms)) })
+	})
+	if err != nil {
+		return "", err
+	}
+	err = forEach(ctx, s.client.Workers(), projects, func(ctx context.Context, p store.StoreProject) error {
+		ms, err := s.client.AllProjectMilestones(ctx, p.ID)
+		if errors.Is(err, glclient.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("project %d: %w", p.ID, err)
+		}
+		n.Add(int64(len(ms)))
+		return s.save(ctx, len(ms), func() error { return s.store.UpsertMilestones(glclient.ConvertMilestones(ms)) })
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// Every milestone that still exists was stored above.
+	err = s.save(ctx, 0, func() error {
+		if err := s.store.DeleteStaleMilestones(start); err != nil {
+			return err
+		}
+		return s.store.LinkMilestoneTitles()
+	})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d milestones", n.Load()), nil
+}
+
+func (s *Syncer) syncIterations(ctx context.Context) (string, error) {
+	start := time.Now().UTC()
+	groups, err := s.store.ListGroups()
+	if err != nil {
+		return "", err
+	}
+
+	var n, unavailable atomic.Int64
+	err = forEach(ctx, s.client.Workers(), groups, func(ctx context.Context, g store.StoreGroup) error {
+		its, err := s.client.AllGroupIterations(ctx, g.ID)
+		if notLicensed(err) {
+			unavailable.Add(1)
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("group %d: %w", g.ID, err)
+		}
+		n.Add(int64(len(its)))
+		return s.save(ctx, len(its), func() error { return s.store.UpsertIterations(glclient.ConvertIterations(its)) })
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := s.save(ctx, 0, func() error { return s.store.DeleteStaleIterations(start) }); err != nil {
+		return "", err
+	}
+	if u := unavailable.Load(); u > 0 && u == int64(len(groups)) {
+		return "not available on this instance", nil
+	}
+	return fmt.Sprintf("%d iterations", n.Load()), nil
+}
+
+// notLicensed reports whether GitLab refused a request for a feature the
+// instance or group does not have, such as iterations without Premium.
+func notLicensed(err error) bool {
+	var apiErr *glclient.APIError
+	return errors.As(err, &apiErr) &&
+		(apiErr.StatusCode == http.StatusForbidden || apiErr.StatusCode == http.StatusNotFound)
+}
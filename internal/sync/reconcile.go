@@ -0,0 +1,118 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chazzychouse/g2o/internal/glclient"
+	"github.com/chazzychouse/g2o/internal/store"
+)
+
+// Reconcile finds records GitLab no longer lists. Incremental syncs only
+// see what changed, so a deleted issue or a project the user left would
+// stay in the store until the next full sync. Reconciling fetches just the
+// IDs of each resource and soft-deletes the stored rows missing from them;
+// a later sync that sees a row again brings it back.
+func (s *Syncer) Reconcile(ctx context.Context) error {
+	s.emit(Event{Kind: EventSyncStarted, Message: "Reconciling with GitLab..."})
+	if err := s.runStages(ctx,
+		stage{"groups", s.reconcileWith("groups", s.client.GroupStamps)},
+		stage{"projects", s.reconcileWith("projects", s.client.ProjectStamps)},
+		stage{"issues", s.reconcileWith("issues", s.reconcileIssueStamps)},
+		stage{"merge requests", s.reconcileWith("merge_requests", s.client.MergeRequestStamps)},
+	); err != nil {
+		return err
+	}
+	s.emit(Event{Kind: EventSyncFinished, Message: "Reconcile complete."})
+	return nil
+}
+
+// reconcileWith returns a stage that reconciles resource against the
+// records list returns.
+func (s *Syncer) reconcileWith(resource string, list func(context.Context) ([]glclient.Stamp, error)) func(context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		stamps, err := list(ctx)
+		if err != nil {
+			return "", err
+		}
+		var d store.Drift
+		err = s.save(ctx, 0, func() error {
+			d, err = s.store.Reconcile(resource, glclient.StampIDs(stamps), time.Now().UTC())
+			return err
+		})
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%d removed, %d missing", d.Removed, d.Missing), nil
+	}
+}
+
+// reconcileIssueStamps lists the issues GitLab has for the store and drops
+// the scope and group memberships GitLab no longer lists.
+func (s *Syncer) reconcileIssueStamps(ctx context.Context) ([]glclient.Stamp, error) {
+	return s.issueStamps(ctx, true)
+}
+
+// issueStamps lists the issues of every known scope and, once group issues have
+// been synced, those of every stored group. With prune, memberships of
+// issues a scope or group no longer lists are dropped on the way.
+func (s *Syncer) issueStamps(ctx context.Context, prune bool) ([]glclient.Stamp, error) {
+	var stamps []glclient.Stamp
+	seen := make(map[int64]bool)
+	// add is called through save, which serializes it.
+	add := func(listed []glclient.Stamp) {
+		for _, st := range listed {
+			if !seen[st.ID] {
+				seen[st.ID] = true
+				stamps = append(stamps, st)
+			}
+		}
+	}
+
+	scopes, err := s.knownIssueScopes()
+	if err != nil {
+		return nil, err
+	}
+	err = forEach(ctx, s.client.Workers(), scopes, func(ctx context.Context, sc glclient.IssueScope) error {
+		listed, err := s.client.IssueStamps(ctx, sc)
+		if err != nil {
+			return fmt.Errorf("scope %s: %w", sc, err)
+		}
+		return s.save(ctx, 0, func() error {
+			add(listed)
+			if !prune {
+				return nil
+			}
+			return s.store.ReconcileScopeIssues(sc.String(), glclient.StampIDs(listed))
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if last, err := s.store.GetLastSynced("group_issues"); err != nil || last.IsZero() {
+		return stamps, err
+	}
+	groups, err := s.store.ListGroups()
+	if err != nil {
+		return nil, err
+	}
+	err = forEach(ctx, s.client.Workers(), groups, func(ctx context.Context, g store.StoreGroup) error {
+		listed, err := s.client.GroupIssueStamps(ctx, g.ID)
+		if err != nil {
+			return fmt.Errorf("group %d: %w", g.ID, err)
+		}
+		return s.save(ctx, 0, func() error {
+			add(listed)
+			if !prune {
+				return nil
+			}
+			return s.store.ReconcileGroupIssues(g.ID, glclient.StampIDs(listed))
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stamps, nil
+}
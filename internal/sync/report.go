@@ -0,0 +1,225 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/chazzychouse/g2o/internal/styles"
+)
+
+// EventKind says what happened in a sync.
+type EventKind string
+
+const (
+	EventSyncStarted  EventKind = "sync_started"  // a sync command began; Message describes it
+	EventStarted      EventKind = "started"       // a resource began syncing
+	EventPage         EventKind = "page"          // a page of a resource arrived
+	EventUpserted     EventKind = "upserted"      // Count records of a resource were stored
+	EventFinished     EventKind = "finished"      // a resource is done; Message summarizes it
+	EventError        EventKind = "error"         // a resource failed with Err
+	EventNotice       EventKind = "notice"        // something worth telling the user
+	EventSyncFinished EventKind = "sync_finished" // a sync command completed
+)
+
+// Event is one step of a sync, as reported to a Reporter.
+type Event struct {
+	Kind       EventKind
+	Time       time.Time
+	Resource   string // e.g. "issues"; empty for whole-sync events
+	Page       int64  // for EventPage
+	TotalPages int64  // for EventPage; 0 when GitLab did not say
+	Count      int    // items in the page, or records stored
+	Message    string
+	Err        error
+}
+
+// Reporter receives the events of a sync. A Syncer never calls it from two
+// goroutines at once.
+type Reporter interface {
+	Report(Event)
+}
+
+// Progress modes accepted by NewReporter.
+const (
+	ProgressLive  = "live"  // redraws a line per running resource
+	ProgressPlain = "plain" // prints a line per finished resource
+	ProgressJSON  = "json"  // writes every event as a JSON object per line
+	ProgressNone  = "none"  // prints nothing
+)
+
+// ProgressModes lists every mode in the order shown to users.
+var ProgressModes = []string{ProgressLive, ProgressPlain, ProgressJSON, ProgressNone}
+
+// NewReporter returns the reporter for a progress mode, writing to w.
+func NewReporter(mode string, w io.Writer) (Reporter, error) {
+	switch strings.ToLower(mode) {
+	case ProgressLive:
+		return NewLiveReporter(w), nil
+	case ProgressPlain, "":
+		return NewPlainReporter(w), nil
+	case ProgressJSON:
+		return NewJSONReporter(w), nil
+	case ProgressNone:
+		return Silent, nil
+	}
+	return nil, fmt.Errorf("unknown progress mode %q (want one of: %s)", mode, strings.Join(ProgressModes, ", "))
+}
+
+// Silent discards every event.
+var Silent Reporter = silent{}
+
+type silent struct{}
+
+func (silent) Report(Event) {}
+
+// PlainReporter prints a line for each resource as it finishes, which
+// suits logs and output that is not a terminal.
+type PlainReporter struct {
+	w io.Writer
+}
+
+func NewPlainReporter(w io.Writer) *PlainReporter {
+	return &PlainReporter{w: w}
+}
+
+func (r *PlainReporter) Report(e Event) {
+	if line, ok := eventLine(e); ok {
+		fmt.Fprintln(r.w, line)
+	}
+}
+
+// eventLine renders the events that leave a permanent line of output.
+func eventLine(e Event) (string, bool) {
+	switch e.Kind {
+	case EventSyncStarted:
+		return styles.Title.Render(e.Message), true
+	case EventFinished:
+		return resourceLine(e.Resource, styles.Success.Render(e.Message)), true
+	case EventError:
+		return resourceLine(e.Resource, styles.Error.Render("failed")), true
+	case EventNotice:
+		return styles.Label.Render("  " + e.Message), true
+	case EventSyncFinished:
+		return styles.Success.Render(e.Message), true
+	}
+	return "", false
+}
+
+func resourceLine(resource, result string) string {
+	return fmt.Sprintf("  syncing %s... %s", resource, result)
+}
+
+// LiveReporter keeps a line per running resource at the bottom of a
+// terminal and updates it as pages arrive. Finished resources are printed
+// above it once, like PlainReporter does. Rows are matched by resource
+// name, which the syncer keeps unique among the resources running at once.
+type LiveReporter struct {
+	w       io.Writer
+	running []*liveRow
+	drawn   int // lines of the running block currently on screen
+}
+
+type liveRow struct {
+	resource    string
+	page, total int64
+	stored      int
+}
+
+func NewLiveReporter(w io.Writer) *LiveReporter {
+	return &LiveReporter{w: w}
+}
+
+func (r *LiveReporter) Report(e Event) {
+	r.clear()
+	switch e.Kind {
+	case EventStarted:
+		r.running = append(r.running, &liveRow{resource: e.Resource})
+	case EventPage:
+		if row := r.row(e.Resource); row != nil {
+			row.page, row.total = e.Page, e.TotalPages
+		}
+	case EventUpserted:
+		if row := r.row(e.Resource); row != nil {
+			row.stored += e.Count
+		}
+	case EventFinished, EventError:
+		r.remove(e.Resource)
+	}
+	if line, ok := eventLine(e); ok {
+		fmt.Fprintln(r.w, line)
+	}
+	r.draw()
+}
+
+func (r *LiveReporter) row(resource string) *liveRow {
+	for _, row := range r.running {
+		if row.resource == resource {
+			return row
+		}
+	}
+	return nil
+}
+
+func (r *LiveReporter) remove(resource string) {
+	for i, row := range r.running {
+		if row.resource == resource {
+			r.running = append(r.running[:i], r.running[i+1:]...)
+			return
+		}
+	}
+}
+
+// clear erases the running block so output can be printed in its place.
+func (r *LiveReporter) clear() {
+	if r.drawn > 0 {
+		fmt.Fprintf(r.w, "\033[%dA\033[J", r.drawn)
+		r.drawn = 0
+	}
+}
+
+func (r *LiveReporter) draw() {
+	for _, row := range r.running {
+		var parts []string
+		switch {
+		case row.total > 0:
+			parts = append(parts, fmt.Sprintf("page %d/%d", row.page, row.total))
+		case row.page > 0:
+			parts = append(parts, fmt.Sprintf("page %d", row.page))
+		}
+		if row.stored > 0 {
+			parts = append(parts, fmt.Sprintf("%d stored", row.stored))
+		}
+		fmt.Fprintln(r.w, resourceLine(row.resource, styles.Label.Render(strings.Join(parts, ", "))))
+	}
+	r.drawn = len(r.running)
+}
+
+// JSONReporter writes every event as a JSON object on its own line, for
+// other tools to follow a sync.
+type JSONReporter struct {
+	enc *json.Encoder
+}
+
+func NewJSONReporter(w io.Writer) *JSONReporter {
+	return &JSONReporter{enc: json.NewEncoder(w)}
+}
+
+func (r *JSONReporter) Report(e Event) {
+	v := struct {
+		Time       time.Time `json:"time"`
+		Kind       EventKind `json:"event"`
+		Resource   string    `json:"resource,omitempty"`
+		Page       int64     `json:"page,omitempty"`
+		TotalPages int64     `json:"total_pages,omitempty"`
+		Count      int       `json:"count,omitempty"`
+		Message    string    `json:"message,omitempty"`
+		Error      string    `json:"error,omitempty"`
+	}{e.Time, e.Kind, e.Resource, e.Page, e.TotalPages, e.Count, e.Message, ""}
+	if e.Err != nil {
+		v.Error = e.Err.Error()
+	}
+	_ = r.enc.Encode(v)
+}
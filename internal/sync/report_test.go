@@ -0,0 +1,38 @@
+package sync
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLiveReporterRows(t *testing.T) {
+	var b strings.Builder
+	r := NewLiveReporter(&b)
+	for _, e := range []Event{
+		{Kind: EventStarted, Resource: "issues for acme/api"},
+		{Kind: EventStarted, Resource: "issues for other/api"},
+		{Kind: EventPage, Resource: "issues for acme/api", Page: 1, TotalPages: 3},
+		{Kind: EventUpserted, Resource: "issues for other/api", Count: 20},
+		{Kind: EventPage, Resource: "issues for acme/api", Page: 2, TotalPages: 3},
+	} {
+		r.Report(e)
+	}
+	if len(r.running) != 2 {
+		t.Fatalf("got %d rows, want one per group", len(r.running))
+	}
+	acme, other := r.running[0], r.running[1]
+	if acme.page != 2 || acme.total != 3 || acme.stored != 0 {
+		t.Errorf("acme/api row is %+v, want page 2/3 with nothing stored", *acme)
+	}
+	if other.page != 0 || other.stored != 20 {
+		t.Errorf("other/api row is %+v, want 20 stored and no page", *other)
+	}
+
+	r.Report(Event{Kind: EventFinished, Resource: "issues for acme/api", Message: "40 issues"})
+	if len(r.running) != 1 || r.running[0].resource != "issues for other/api" {
+		t.Errorf("after acme/api finished, running %v; want only other/api", r.running)
+	}
+	if !strings.Contains(b.String(), "syncing issues for acme/api...") {
+		t.Errorf("finished line missing from output:\n%s", b.String())
+	}
+}
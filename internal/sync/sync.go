@@ -3,84 +3,203 @@ package sync
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/chazzychouse/g2o/internal/glclient"
+	"github.com/chazzychouse/g2o/internal/render"
 	"github.com/chazzychouse/g2o/internal/store"
-	"github.com/chazzychouse/g2o/internal/styles"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
 )
 
 type Syncer struct {
 	client *glclient.GitLab
 	store  *store.Store
+
+	writeMu  sync.Mutex // serializes store writes from concurrent fetches
+	reportMu sync.Mutex // serializes calls to reporter
+	reporter Reporter
+
+	overlap     time.Duration         // see SetOverlap
+	issueScopes []glclient.IssueScope // see SetIssueScopes
 }
 
+// DefaultOverlap is how far before its watermark an incremental sync
+// starts unless SetOverlap says otherwise.
+const DefaultOverlap = 5 * time.Minute
+
+// NewSyncer returns a syncer that prints its progress to stdout with a
+// PlainReporter until SetReporter is called.
 func NewSyncer(client *glclient.GitLab, store *store.Store) *Syncer {
-	return &Syncer{client: client, store: store}
+	return &Syncer{client: client, store: store, reporter: NewPlainReporter(os.Stdout),
+		overlap: DefaultOverlap, issueScopes: glclient.DefaultIssueScopes}
+}
+
+// SetOverlap sets how far before the newest change already stored an
+// incremental sync starts looking. Records GitLab stamps with a time
+// slightly in the past, such as those written while the previous sync was
+// fetching, are caught by the overlap; 0 restores the default.
+func (s *Syncer) SetOverlap(d time.Duration) {
+	if d <= 0 {
+		d = DefaultOverlap
+	}
+	s.overlap = d
+}
+
+// SetReporter sends the events of subsequent syncs to r.
+func (s *Syncer) SetReporter(r Reporter) {
+	s.reportMu.Lock()
+	defer s.reportMu.Unlock()
+	s.reporter = r
+}
+
+// emit stamps e and hands it to the reporter.
+func (s *Syncer) emit(e Event) {
+	e.Time = time.Now().UTC()
+	s.reportMu.Lock()
+	defer s.reportMu.Unlock()
+	s.reporter.Report(e)
+}
+
+// stage is one resource fetched and stored by a sync. run returns the
+// summary shown on its progress line.
+type stage struct {
+	name string
+	run  func(ctx context.Context) (string, error)
+}
+
+// runStages runs stages concurrently and reports each as it starts, makes
+// progress and finishes. The first failure cancels the others.
+func (s *Syncer) runStages(ctx context.Context, stages ...stage) error {
+	return forEach(ctx, len(stages), stages, func(ctx context.Context, st stage) error {
+		_, err := s.track(ctx, st.name, st.run)
+		if err != nil {
+			return fmt.Errorf("sync %s: %w", st.name, err)
+		}
+		return nil
+	})
+}
+
+type resourceKey struct{}
+
+// track reports resource as started, runs fn with a context that reports
+// the pages it fetches and the records it stores, then reports how it
+// ended.
+func (s *Syncer) track(ctx context.Context, resource string, fn func(context.Context) (string, error)) (string, error) {
+	s.emit(Event{Kind: EventStarted, Resource: resource})
+	rctx := context.WithValue(ctx, resourceKey{}, resource)
+	rctx = glclient.WithPageFunc(rctx, func(page, total int64, items int) {
+		s.emit(Event{Kind: EventPage, Resource: resource, Page: page, TotalPages: total, Count: items})
+	})
+	summary, err := fn(rctx)
+	if err != nil {
+		// Resources stopped because another one failed stay quiet.
+		if ctx.Err() == nil {
+			s.emit(Event{Kind: EventError, Resource: resource, Err: err})
+		}
+		return "", err
+	}
+	s.emit(Event{Kind: EventFinished, Resource: resource, Message: summary})
+	return summary, nil
+}
+
+// save runs fn, which stores n records, with exclusive use of the store
+// and reports them for the resource ctx is tracking. Fetches run
+// concurrently but SQLite takes one writer at a time, so every stage stores
+// its results through here.
+func (s *Syncer) save(ctx context.Context, n int, fn func() error) error {
+	s.writeMu.Lock()
+	err := fn()
+	s.writeMu.Unlock()
+	if err != nil {
+		return err
+	}
+	if resource, ok := ctx.Value(resourceKey{}).(string); ok && n > 0 {
+		s.emit(Event{Kind: EventUpserted, Resource: resource, Count: n})
+	}
+	return nil
 }
 
 // SyncAll performs a full sync of all resources, downloading everything
-// and removing stale records.
+// and removing stale records. Independent resources are fetched at the
+// same time.
 func (s *Syncer) SyncAll(ctx context.Context) error {
-	fmt.Println(styles.Title.Render("Full sync starting..."))
+	s.emit(Event{Kind: EventSyncStarted, Message: "Full sync starting..."})
 	now := time.Now().UTC()
 
-	if err := s.syncUser(ctx); err != nil {
-		return fmt.Errorf("sync user: %w", err)
+	if err := s.replayPendingOps(ctx); err != nil {
+		return fmt.Errorf("replay queued writes: %w", err)
 	}
-	if err := s.syncGroupsFull(ctx); err != nil {
-		return fmt.Errorf("sync groups: %w", err)
-	}
-	if err := s.syncProjectsFull(ctx); err != nil {
-		return fmt.Errorf("sync projects: %w", err)
+
+	stages := []stage{
+		{"user", s.syncUser},
+		{"groups", s.syncGroupsFull},
+		{"projects", s.syncProjectsFull},
+	}
+	stages = append(stages, s.issueStages(true)...)
+	stages = append(stages, stage{"merge requests", s.syncMergeRequestsFull}, stage{"to-do items", s.syncTodos})
+	if err := s.runStages(ctx, stages...); err != nil {
+		return err
 	}
-	if err := s.syncIssuesFull(ctx); err != nil {
-		return fmt.Errorf("sync issues: %w", err)
+	// Notes are fetched for the issues stored above, and milestones and
+	// labels for the groups and projects.
+	late := []stage{{"issue notes", s.syncIssueNotes}, {"labels", s.syncLabels}}
+	if err := s.runStages(ctx, append(late, s.milestoneStages()...)...); err != nil {
+		return err
 	}
 
 	// Mark full sync timestamps.
-	for _, res := range []string{"groups", "projects", "issues", "user"} {
+	for _, res := range append([]string{"groups", "projects", "issues", "issue_notes", "merge_requests", "milestones", "iterations", "labels", "todos", "user"}, s.issueResources()...) {
 		if err := s.store.SetFullSync(res, now); err != nil {
 			return fmt.Errorf("set full sync %s: %w", res, err)
 		}
 	}
 
-	fmt.Println(styles.Success.Render("Full sync complete."))
+	s.emit(Event{Kind: EventSyncFinished, Message: "Full sync complete."})
 	return nil
 }
 
 // SyncIncremental uses timestamps from last sync for incremental updates.
 func (s *Syncer) SyncIncremental(ctx context.Context) error {
-	fmt.Println(styles.Title.Render("Incremental sync starting..."))
+	s.emit(Event{Kind: EventSyncStarted, Message: "Incremental sync starting..."})
 	now := time.Now().UTC()
 
-	if err := s.syncUser(ctx); err != nil {
-		return fmt.Errorf("sync user: %w", err)
-	}
-	// Groups always full (no UpdatedAfter on API).
-	if err := s.syncGroupsFull(ctx); err != nil {
-		return fmt.Errorf("sync groups: %w", err)
+	if err := s.replayPendingOps(ctx); err != nil {
+		return fmt.Errorf("replay queued writes: %w", err)
 	}
-	if err := s.syncProjectsIncremental(ctx); err != nil {
-		return fmt.Errorf("sync projects: %w", err)
+
+	stages := []stage{
+		{"user", s.syncUser},
+		// Groups always full (no UpdatedAfter on API).
+		{"groups", s.syncGroupsFull},
+		{"projects", s.syncProjectsIncremental},
+	}
+	stages = append(stages, s.issueStages(false)...)
+	stages = append(stages, stage{"merge requests", s.syncMergeRequestsIncremental}, stage{"to-do items", s.syncTodos})
+	if err := s.runStages(ctx, stages...); err != nil {
+		return err
 	}
-	if err := s.syncIssuesIncremental(ctx); err != nil {
-		return fmt.Errorf("sync issues: %w", err)
+	if err := s.runStages(ctx, stage{"issue notes", s.syncIssueNotes}); err != nil {
+		return err
 	}
 
-	for _, res := range []string{"groups", "projects", "issues", "user"} {
+	for _, res := range append([]string{"groups", "projects", "issues", "issue_notes", "merge_requests", "todos", "user"}, s.issueResources()...) {
 		if err := s.store.SetLastSynced(res, now); err != nil {
 			return fmt.Errorf("set last synced %s: %w", res, err)
 		}
 	}
 
-	fmt.Println(styles.Success.Render("Sync complete."))
+	s.emit(Event{Kind: EventSyncFinished, Message: "Sync complete."})
 	return nil
 }
 
 // SyncGroups syncs groups only.
 func (s *Syncer) SyncGroups(ctx context.Context) error {
-	if err := s.syncGroupsFull(ctx); err != nil {
+	if err := s.runStages(ctx, stage{"groups", s.syncGroupsFull}); err != nil {
 		return err
 	}
 	return s.store.SetLastSynced("groups", time.Now().UTC())
@@ -88,221 +207,397 @@ func (s *Syncer) SyncGroups(ctx context.Context) error {
 
 // SyncProjects syncs projects only.
 func (s *Syncer) SyncProjects(ctx context.Context) error {
-	if err := s.syncProjectsIncremental(ctx); err != nil {
+	if err := s.runStages(ctx, stage{"projects", s.syncProjectsIncremental}); err != nil {
 		return err
 	}
 	return s.store.SetLastSynced("projects", time.Now().UTC())
 }
 
-// SyncIssues syncs issues only.
+// SyncIssues syncs the issues of every scope only.
 func (s *Syncer) SyncIssues(ctx context.Context) error {
-	if err := s.syncIssuesIncremental(ctx); err != nil {
+	if err := s.runStages(ctx, s.issueStages(false)...); err != nil {
 		return err
 	}
-	return s.store.SetLastSynced("issues", time.Now().UTC())
+	now := time.Now().UTC()
+	for _, res := range append([]string{"issues"}, s.issueResources()...) {
+		if err := s.store.SetLastSynced(res, now); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (s *Syncer) syncUser(ctx context.Context) error {
-	fmt.Print("  syncing user... ")
-	u, err := s.client.CurrentUser()
-	if err != nil {
+// SyncMergeRequests syncs merge requests only.
+func (s *Syncer) SyncMergeRequests(ctx context.Context) error {
+	if err := s.runStages(ctx, stage{"merge requests", s.syncMergeRequestsIncremental}); err != nil {
 		return err
 	}
-	if err := s.store.UpsertUser(convertUser(u)); err != nil {
-		return err
-	}
-	fmt.Println(styles.Success.Render("done"))
-	return nil
+	return s.store.SetLastSynced("merge_requests", time.Now().UTC())
 }
 
-func (s *Syncer) syncGroupsFull(ctx context.Context) error {
-	fmt.Print("  syncing groups... ")
-	groups, err := s.client.AllGroups(ctx)
-	if err != nil {
+// replayPendingOps sends writes queued while offline before anything is
+// fetched, so the sync brings back their result.
+func (s *Syncer) replayPendingOps(ctx context.Context) error {
+	n, err := s.store.CountPendingOps(store.OpPending)
+	if err != nil || n == 0 {
 		return err
 	}
-	sg := convertGroups(groups)
-	if err := s.store.UpsertGroups(sg); err != nil {
+	var res glclient.ReplayResult
+	_, err = s.track(ctx, "queued writes", func(ctx context.Context) (string, error) {
+		res, err = s.client.ReplayPendingOps(ctx)
+		if err != nil {
+			return "", err
+		}
+		return replaySummary(res), nil
+	})
+	if err != nil {
 		return err
 	}
-	// Delete stale.
-	ids := make([]int64, len(groups))
-	for i, g := range groups {
-		ids[i] = g.ID
+	if res.Conflicts > 0 || res.Failed > 0 {
+		s.emit(Event{Kind: EventNotice, Message: "see 'queue' to retry or drop them"})
 	}
-	if err := s.store.DeleteStaleGroups(ids); err != nil {
-		return err
-	}
-	fmt.Println(styles.Success.Render(fmt.Sprintf("%d groups", len(groups))))
 	return nil
 }
 
-func (s *Syncer) syncProjectsFull(ctx context.Context) error {
-	fmt.Print("  syncing projects... ")
-	projects, err := s.client.AllProjects(ctx, nil)
+func replaySummary(res glclient.ReplayResult) string {
+	parts := []string{fmt.Sprintf("%d applied", res.Applied)}
+	if res.Conflicts > 0 {
+		parts = append(parts, fmt.Sprintf("%d conflicts", res.Conflicts))
+	}
+	if res.Failed > 0 {
+		parts = append(parts, fmt.Sprintf("%d failed", res.Failed))
+	}
+	if res.Remaining > 0 {
+		parts = append(parts, fmt.Sprintf("%d still queued", res.Remaining))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (s *Syncer) syncUser(ctx context.Context) (string, error) {
+	u, err := s.client.CurrentUser()
 	if err != nil {
-		return err
+		return "", err
 	}
-	sp := convertProjects(projects)
-	if err := s.store.UpsertProjects(sp); err != nil {
-		return err
+	if err := s.save(ctx, 1, func() error { return s.store.UpsertUser(glclient.ConvertUser(u)) }); err != nil {
+		return "", err
 	}
-	ids := make([]int64, len(projects))
-	for i, p := range projects {
-		ids[i] = p.ID
+	return "done", nil
+}
+
+func (s *Syncer) syncGroupsFull(ctx context.Context) (string, error) {
+	c, err := s.checkpoint(ctx, "groups")
+	if err != nil {
+		return "", err
 	}
-	if err := s.store.DeleteStaleProjects(ids); err != nil {
-		return err
+	n := 0
+	err = s.client.GroupPages(ctx, c.from(""), func(page int64, groups []*gitlab.Group) error {
+		n += len(groups)
+		return c.save(ctx, "", page, len(groups), func() error { return s.store.UpsertGroups(glclient.ConvertGroups(groups)) })
+	})
+	if err != nil {
+		return "", err
 	}
-	fmt.Println(styles.Success.Render(fmt.Sprintf("%d projects", len(projects))))
-	return nil
+	if err := c.finish(ctx, s.store.DeleteStaleGroups); err != nil {
+		return "", err
+	}
+	return c.summary(n, "groups"), nil
 }
 
-func (s *Syncer) syncProjectsIncremental(ctx context.Context) error {
-	fmt.Print("  syncing projects... ")
-	lastSynced, err := s.store.GetLastSynced("projects")
+func (s *Syncer) syncProjectsFull(ctx context.Context) (string, error) {
+	c, err := s.checkpoint(ctx, "projects")
 	if err != nil {
-		return err
+		return "", err
+	}
+	n := 0
+	err = s.client.ProjectPages(ctx, nil, c.from(""), func(page int64, projects []*gitlab.Project) error {
+		n += len(projects)
+		c.observe(latest(projects, projectActivity))
+		return c.save(ctx, "", page, len(projects), func() error { return s.store.UpsertProjects(glclient.ConvertProjects(projects)) })
+	})
+	if err != nil {
+		return "", err
 	}
-	var after *time.Time
-	if !lastSynced.IsZero() {
-		after = &lastSynced
+	if err := c.finish(ctx, s.store.DeleteStaleProjects); err != nil {
+		return "", err
+	}
+	return c.summary(n, "projects"), nil
+}
+
+func (s *Syncer) syncProjectsIncremental(ctx context.Context) (string, error) {
+	after, err := s.lastSynced("projects")
+	if err != nil {
+		return "", err
 	}
 	projects, err := s.client.AllProjects(ctx, after)
 	if err != nil {
-		return err
+		return "", err
 	}
 	if len(projects) > 0 {
-		sp := convertProjects(projects)
-		if err := s.store.UpsertProjects(sp); err != nil {
-			return err
+		err := s.save(ctx, len(projects), func() error {
+			if err := s.store.UpsertProjects(glclient.ConvertProjects(projects)); err != nil {
+				return err
+			}
+			return s.store.AdvanceWatermark("projects", latest(projects, projectActivity))
+		})
+		if err != nil {
+			return "", err
 		}
 	}
-	fmt.Println(styles.Success.Render(fmt.Sprintf("%d projects", len(projects))))
-	return nil
+	return fmt.Sprintf("%d projects", len(projects)), nil
 }
 
-func (s *Syncer) syncIssuesFull(ctx context.Context) error {
-	fmt.Print("  syncing issues... ")
-	issues, err := s.client.AllIssues(ctx, nil)
+func (s *Syncer) syncMergeRequestsFull(ctx context.Context) (string, error) {
+	c, err := s.checkpoint(ctx, "merge_requests", glclient.MergeRequestScopes...)
 	if err != nil {
-		return err
+		return "", err
 	}
-	si := convertIssues(issues)
-	if err := s.store.UpsertIssues(si); err != nil {
-		return err
+	// A merge request can turn up in several scopes.
+	seen := make(map[int64]bool)
+	for _, scope := range glclient.MergeRequestScopes {
+		if c.from(scope) == 0 {
+			continue
+		}
+		err := s.client.MergeRequestPages(ctx, scope, nil, c.from(scope), func(page int64, mrs []*gitlab.BasicMergeRequest) error {
+			for _, mr := range mrs {
+				seen[mr.ID] = true
+			}
+			c.observe(latest(mrs, mergeRequestUpdated))
+			detailed := s.withMergeRequestDetails(ctx, mrs)
+			return c.save(ctx, scope, page, len(detailed), func() error { return s.store.UpsertMergeRequests(detailed) })
+		})
+		if err != nil {
+			return "", err
+		}
+		if err := c.done(ctx, scope); err != nil {
+			return "", err
+		}
 	}
-	ids := make([]int64, len(issues))
-	for i, issue := range issues {
-		ids[i] = issue.ID
+	if err := c.finish(ctx, s.store.DeleteStaleMergeRequests); err != nil {
+		return "", err
 	}
-	if err := s.store.DeleteStaleIssues(ids); err != nil {
-		return err
+	return c.summary(len(seen), "merge requests"), nil
+}
+
+func (s *Syncer) syncMergeRequestsIncremental(ctx context.Context) (string, error) {
+	after, err := s.lastSynced("merge_requests")
+	if err != nil {
+		return "", err
 	}
-	fmt.Println(styles.Success.Render(fmt.Sprintf("%d issues", len(issues))))
-	return nil
+	mrs, err := s.client.AllMergeRequests(ctx, after)
+	if err != nil {
+		return "", err
+	}
+	if len(mrs) > 0 {
+		detailed := s.withMergeRequestDetails(ctx, mrs)
+		err := s.save(ctx, len(detailed), func() error {
+			if err := s.store.UpsertMergeRequests(detailed); err != nil {
+				return err
+			}
+			return s.store.AdvanceWatermark("merge_requests", latest(mrs, mergeRequestUpdated))
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	return fmt.Sprintf("%d merge requests", len(mrs)), nil
 }
 
-func (s *Syncer) syncIssuesIncremental(ctx context.Context) error {
-	fmt.Print("  syncing issues... ")
-	lastSynced, err := s.store.GetLastSynced("issues")
+// lastSynced returns the UpdatedAfter filter for an incremental sync of
+// resource, or nil if it was never synced. It goes by the newest GitLab
+// timestamp stored rather than by the local clock, which may be skewed and
+// says nothing of changes made while the last sync was running.
+func (s *Syncer) lastSynced(resource string) (*time.Time, error) {
+	last, err := s.store.GetWatermark(resource)
 	if err != nil {
+		return nil, err
+	}
+	if last.IsZero() {
+		// Stores synced before watermarks existed only have the local time.
+		if last, err = s.store.GetLastSynced(resource); err != nil || last.IsZero() {
+			return nil, err
+		}
+	}
+	after := last.Add(-s.overlap)
+	return &after, nil
+}
+
+// latest returns the newest of the times at returns for items.
+func latest[T any](items []T, at func(T) *time.Time) time.Time {
+	var newest time.Time
+	for _, item := range items {
+		if t := at(item); t != nil && t.After(newest) {
+			newest = *t
+		}
+	}
+	return newest
+}
+
+func issueUpdated(i *gitlab.Issue) *time.Time                     { return i.UpdatedAt }
+func projectActivity(p *gitlab.Project) *time.Time                { return p.LastActivityAt }
+func mergeRequestUpdated(mr *gitlab.BasicMergeRequest) *time.Time { return mr.UpdatedAt }
+
+// SyncIssueNotes fetches notes and linked issues for every stored issue
+// that changed since its notes were last fetched.
+func (s *Syncer) SyncIssueNotes(ctx context.Context) error {
+	if err := s.runStages(ctx, stage{"issue notes", s.syncIssueNotes}); err != nil {
 		return err
 	}
-	var after *time.Time
-	if !lastSynced.IsZero() {
-		after = &lastSynced
+	return s.store.SetLastSynced("issue_notes", time.Now().UTC())
+}
+
+func (s *Syncer) syncIssueNotes(ctx context.Context) (string, error) {
+	issues, err := s.store.ListIssuesNeedingNotes()
+	if err != nil {
+		return "", err
 	}
-	issues, err := s.client.AllIssues(ctx, after)
+	// One page per issue says little; stored notes show the progress.
+	ctx = glclient.WithPageFunc(ctx, nil)
+	var total atomic.Int64
+	err = forEach(ctx, s.client.Workers(), issues, func(ctx context.Context, issue store.StoreIssue) error {
+		notes, links, err := s.client.IssueDiscussion(ctx, issue.ProjectID, issue.ID, issue.IID)
+		if err != nil {
+			return fmt.Errorf("issue %d: %w", issue.ID, err)
+		}
+		total.Add(int64(len(notes)))
+		return s.save(ctx, len(notes), func() error { return s.store.ReplaceIssueDiscussion(issue.ID, notes, links) })
+	})
 	if err != nil {
-		return err
+		return "", err
 	}
-	if len(issues) > 0 {
-		si := convertIssues(issues)
-		if err := s.store.UpsertIssues(si); err != nil {
-			return err
+	return fmt.Sprintf("%d notes on %d issues", total.Load(), len(issues)), nil
+}
+
+// withMergeRequestDetails converts mrs and, for open ones, fetches the head
+// pipeline and approval state that the list endpoint does not return. A
+// failed detail fetch keeps the list data.
+func (s *Syncer) withMergeRequestDetails(ctx context.Context, mrs []*gitlab.BasicMergeRequest) []store.StoreMergeRequest {
+	out := glclient.ConvertMergeRequests(mrs)
+	var open []int
+	for i, mr := range mrs {
+		if mr.State == "opened" {
+			open = append(open, i)
 		}
 	}
-	fmt.Println(styles.Success.Render(fmt.Sprintf("%d issues", len(issues))))
-	return nil
+	_ = forEach(ctx, s.client.Workers(), open, func(ctx context.Context, i int) error {
+		if d, err := s.client.GetMergeRequest(mrs[i].ProjectID, mrs[i].IID); err == nil {
+			out[i] = glclient.ConvertMergeRequestDetail(d)
+		}
+		return nil
+	})
+	return out
 }
 
-// SyncGroupIssues fetches issues for every stored group and links them.
+// SyncGroupIssues fetches issues for every stored group, several groups at
+// a time, and links them.
 func (s *Syncer) SyncGroupIssues(ctx context.Context) error {
 	groups, err := s.store.ListGroups()
 	if err != nil {
 		return fmt.Errorf("list groups: %w", err)
 	}
 	if len(groups) == 0 {
-		fmt.Println(styles.Label.Render("  no groups in store — run 'sync groups' first"))
+		s.emit(Event{Kind: EventNotice, Message: "no groups in store — run 'sync groups' first"})
 		return nil
 	}
 
-	lastSynced, err := s.store.GetLastSynced("group_issues")
+	after, err := s.lastSynced("group_issues")
 	if err != nil {
 		return err
 	}
-	var after *time.Time
-	if !lastSynced.IsZero() {
-		after = &lastSynced
-	}
 
-	var totalIssues int
-	for _, g := range groups {
-		fmt.Printf("  syncing issues for %s... ", styles.Value.Render(g.Name))
-		issues, err := s.client.AllGroupIssues(ctx, g.ID, after)
-		if err != nil {
-			fmt.Println(styles.Error.Render("failed"))
-			return fmt.Errorf("group %d: %w", g.ID, err)
-		}
-		if len(issues) > 0 {
-			si := convertIssues(issues)
-			if err := s.store.UpsertIssues(si); err != nil {
-				return err
+	var totalIssues atomic.Int64
+	var newest time.Time // guarded by save
+	err = forEach(ctx, s.client.Workers(), groups, func(ctx context.Context, g store.StoreGroup) error {
+		// Group names repeat under different parents; progress is tracked
+		// by resource name, so use the full path.
+		_, err := s.track(ctx, "issues for "+g.FullPath, func(ctx context.Context) (string, error) {
+			issues, err := s.client.AllGroupIssues(ctx, g.ID, after)
+			if err != nil {
+				return "", err
 			}
-			ids := make([]int64, len(issues))
-			for i, issue := range issues {
-				ids[i] = issue.ID
-			}
-			if err := s.store.LinkGroupIssues(g.ID, ids); err != nil {
-				return err
+			if len(issues) > 0 {
+				ids := make([]int64, len(issues))
+				for i, issue := range issues {
+					ids[i] = issue.ID
+				}
+				err := s.save(ctx, len(issues), func() error {
+					if err := s.store.UpsertIssues(glclient.ConvertIssues(issues)); err != nil {
+						return err
+					}
+					if t := latest(issues, issueUpdated); t.After(newest) {
+						newest = t
+					}
+					return s.store.LinkGroupIssues(g.ID, ids)
+				})
+				if err != nil {
+					return "", err
+				}
 			}
+			totalIssues.Add(int64(len(issues)))
+			return fmt.Sprintf("%d issues", len(issues)), nil
+		})
+		if err != nil {
+			return fmt.Errorf("group %d: %w", g.ID, err)
 		}
-		fmt.Println(styles.Success.Render(fmt.Sprintf("%d issues", len(issues))))
-		totalIssues += len(issues)
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
 	if err := s.store.SetLastSynced("group_issues", time.Now().UTC()); err != nil {
 		return err
 	}
-	fmt.Println(styles.Success.Render(fmt.Sprintf("  total: %d issues across %d groups", totalIssues, len(groups))))
+	if err := s.store.AdvanceWatermark("group_issues", newest); err != nil {
+		return err
+	}
+	s.emit(Event{Kind: EventSyncFinished, Message: fmt.Sprintf("  total: %d issues across %d groups", totalIssues.Load(), len(groups))})
 	return nil
 }
 
-// ShowStatus prints the last sync time for each resource type.
-func (s *Syncer) ShowStatus() error {
-	resources := []string{"user", "groups", "projects", "issues", "group_issues"}
-	fmt.Println(styles.Title.Render("Sync Status"))
+// ShowStatus writes the last sync time for each resource type, and how far
+// each table had drifted from GitLab when it was last reconciled, to w in
+// format f.
+func (s *Syncer) ShowStatus(w io.Writer, f render.Format) error {
+	scopes, err := s.knownIssueScopes()
+	if err != nil {
+		return err
+	}
+	resources := []string{"user", "groups", "projects", "issues"}
+	for _, sc := range scopes {
+		resources = append(resources, issueResource(sc))
+	}
+	resources = append(resources, "issue_notes", "group_issues", "merge_requests", "milestones", "iterations", "labels", "todos")
+	scoped, err := s.store.CountScopeIssues()
+	if err != nil {
+		return err
+	}
+	var st render.SyncState
 	for _, res := range resources {
 		last, err := s.store.GetLastSynced(res)
 		if err != nil {
 			return err
 		}
-		ts := "never"
-		if !last.IsZero() {
-			ts = last.Local().Format("2006-01-02 15:04:05")
+		r := render.SyncedResource{Resource: res, LastSynced: last}
+		if scope, ok := strings.CutPrefix(res, "issues:"); ok {
+			r.Issues = scoped[scope]
 		}
-		fmt.Printf("  %s %s\n",
-			styles.Label.Render(fmt.Sprintf("%-10s", res)),
-			styles.Value.Render(ts))
+		st.Resources = append(st.Resources, r)
 	}
-
-	// Check if full sync is stale (>7 days).
-	last, _ := s.store.GetLastFullSync("groups")
-	if !last.IsZero() && time.Since(last) > 7*24*time.Hour {
-		fmt.Println(styles.Error.Render("  hint: last full sync > 7 days ago — consider running 'sync full'"))
+	for _, res := range []string{"groups", "projects", "issues", "merge_requests"} {
+		d, err := s.store.GetDrift(res)
+		if err != nil {
+			return err
+		}
+		st.Drift = append(st.Drift, d)
 	}
-	return nil
+	if n, err := s.store.CountPendingOps(store.OpPending); err == nil {
+		st.QueuedWrites = n
+	}
+	if resources, err := s.interrupted(); err == nil {
+		st.Interrupted = resources
+	}
+	st.LastFullSync, _ = s.store.GetLastFullSync("groups")
+	return render.SyncStatus(w, f, st)
 }
 
 // NeedsFullSync returns true if the database appears to need a full sync
@@ -0,0 +1,46 @@
+package sync
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chazzychouse/g2o/internal/render"
+)
+
+func TestShowStatus(t *testing.T) {
+	st := openTestStore(t)
+	s := NewSyncer(nil, st)
+	if err := st.SetLastSynced("groups", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := s.ShowStatus(&out, render.JSON); err != nil {
+		t.Fatal(err)
+	}
+	var got render.SyncState
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("JSON status is not a single document: %v\n%s", err, out.String())
+	}
+	if len(got.Drift) != 4 {
+		t.Errorf("got drift for %d resources, want 4", len(got.Drift))
+	}
+	for _, r := range got.Resources {
+		if synced := !r.LastSynced.IsZero(); synced != (r.Resource == "groups") {
+			t.Errorf("%s: last synced %v", r.Resource, r.LastSynced)
+		}
+	}
+
+	out.Reset()
+	if err := s.ShowStatus(&out, render.Plain); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Sync Status", "Drift", "never reconciled"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("plain status lacks %q:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chazzychouse/g2o/internal/glclient"
+)
+
+// SyncTodos fetches the current user's pending to-do items.
+func (s *Syncer) SyncTodos(ctx context.Context) error {
+	if err := s.runStages(ctx, stage{"to-do items", s.syncTodos}); err != nil {
+		return err
+	}
+	return s.store.SetLastSynced("todos", time.Now().UTC())
+}
+
+// syncTodos stores the pending to-do items. The API cannot list what changed
+// since a time, but the pending list is short: it is fetched whole, and
+// stored items no longer on it were dealt with elsewhere and are marked
+// done, keeping the rows of finished items.
+func (s *Syncer) syncTodos(ctx context.Context) (string, error) {
+	start := time.Now().UTC()
+	todos, err := s.client.AllPendingTodos(ctx)
+	if err != nil {
+		return "", err
+	}
+	err = s.save(ctx, len(todos), func() error {
+		if err := s.store.UpsertTodos(glclient.ConvertTodos(todos)); err != nil {
+			return err
+		}
+		return s.store.MarkStaleTodosDone(start)
+	})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d pending", len(todos)), nil
+}
@@ -0,0 +1,126 @@
+package sync
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chazzychouse/g2o/internal/glclient"
+)
+
+// Verify compares each stored resource with what GitLab lists for it: the
+// records and when each last changed, summed up as a count and a checksum
+// on either side. It proves an incremental sync dropped nothing, and
+// returns an error naming the resources that differ.
+func (s *Syncer) Verify(ctx context.Context) error {
+	s.emit(Event{Kind: EventSyncStarted, Message: "Verifying against GitLab..."})
+	var (
+		mu     sync.Mutex
+		differ []string
+	)
+	verify := func(resource string, list func(context.Context) ([]glclient.Stamp, error)) func(context.Context) (string, error) {
+		return func(ctx context.Context) (string, error) {
+			remote, err := list(ctx)
+			if err != nil {
+				return "", err
+			}
+			local, err := s.store.Stamps(resource)
+			if err != nil {
+				return "", err
+			}
+			v := compareStamps(remote, local)
+			if !v.ok() {
+				mu.Lock()
+				differ = append(differ, resource)
+				mu.Unlock()
+			}
+			return v.String(), nil
+		}
+	}
+	if err := s.runStages(ctx,
+		stage{"groups", verify("groups", s.client.GroupStamps)},
+		stage{"projects", verify("projects", s.client.ProjectStamps)},
+		stage{"issues", verify("issues", func(ctx context.Context) ([]glclient.Stamp, error) { return s.issueStamps(ctx, false) })},
+		stage{"merge requests", verify("merge_requests", s.client.MergeRequestStamps)},
+	); err != nil {
+		return err
+	}
+	if len(differ) > 0 {
+		slices.Sort(differ)
+		s.emit(Event{Kind: EventNotice, Message: "'sync full' fetches what is missing or out of date"})
+		return fmt.Errorf("verify: %s differ from GitLab", strings.Join(differ, ", "))
+	}
+	s.emit(Event{Kind: EventSyncFinished, Message: "Store matches GitLab."})
+	return nil
+}
+
+// verification is how the store compares with GitLab for one resource.
+type verification struct {
+	remote, local  int    // records on either side
+	missing, stale int    // listed by GitLab but not stored, or stored with another time
+	extra          int    // stored but not listed by GitLab, e.g. awaiting reconcile
+	remoteSum      string // checksum of GitLab's records
+	localSum       string // checksum of the stored copies of the same records
+}
+
+func (v verification) ok() bool {
+	return v.missing == 0 && v.stale == 0
+}
+
+func (v verification) String() string {
+	var b strings.Builder
+	if v.ok() {
+		fmt.Fprintf(&b, "%d records, checksum %s", v.remote, v.remoteSum)
+	} else {
+		fmt.Fprintf(&b, "%d on GitLab, %d stored: %d missing, %d out of date (checksum %s, stored %s)",
+			v.remote, v.local, v.missing, v.stale, v.remoteSum, v.localSum)
+	}
+	if v.extra > 0 {
+		fmt.Fprintf(&b, ", %d only stored", v.extra)
+	}
+	return b.String()
+}
+
+// compareStamps compares the records GitLab lists with those stored.
+// Stored times have whole seconds, so GitLab's are truncated to match.
+func compareStamps(remote []glclient.Stamp, local map[int64]time.Time) verification {
+	v := verification{remote: len(remote), local: len(local)}
+	slices.SortFunc(remote, func(a, b glclient.Stamp) int { return cmp.Compare(a.ID, b.ID) })
+	rsum, lsum := sha256.New(), sha256.New()
+	listed := make(map[int64]bool, len(remote))
+	for _, st := range remote {
+		listed[st.ID] = true
+		at := st.UpdatedAt.UTC().Truncate(time.Second)
+		fmt.Fprintf(rsum, "%d %s\n", st.ID, stampText(at))
+		stored, ok := local[st.ID]
+		if !ok {
+			v.missing++
+			continue
+		}
+		fmt.Fprintf(lsum, "%d %s\n", st.ID, stampText(stored))
+		if !stored.Equal(at) {
+			v.stale++
+		}
+	}
+	for id := range local {
+		if !listed[id] {
+			v.extra++
+		}
+	}
+	v.remoteSum = hex.EncodeToString(rsum.Sum(nil))[:12]
+	v.localSum = hex.EncodeToString(lsum.Sum(nil))[:12]
+	return v
+}
+
+func stampText(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}